@@ -670,3 +670,208 @@ func NewUnknownMarshalFormatError(format, at string) UnknownMarshalFormatError {
 func QueryUnknownMarshalFormatError(target error) bool {
 	return QueryGeneric[UnknownMarshalFormatErrorBehaviourQuery]("UnknownMarshalFormat", target)
 }
+
+// ❌ Path Escapes Jail
+
+// PathEscapesJailTemplData path resolves to a location outside of the jail
+type PathEscapesJailTemplData struct {
+	ExtendioTemplData
+	Path string
+	Root string
+}
+
+func (td PathEscapesJailTemplData) Message() *Message {
+	return &Message{
+		ID:          "path-escapes-jail.error",
+		Description: "File system path resolves to a location outside of the jail",
+		Other:       "file system path '{{.Path}}', escapes jail '{{.Root}}'",
+	}
+}
+
+// PathEscapesJailErrorBehaviourQuery used to query if an error is:
+// "File system path resolves to a location outside of the jail"
+type PathEscapesJailErrorBehaviourQuery interface {
+	PathEscapesJail() bool
+}
+
+type PathEscapesJailError struct {
+	LocalisableError
+}
+
+// PathEscapesJail enables the client to check if error is PathEscapesJailError
+// via QueryPathEscapesJailError
+func (e PathEscapesJailError) PathEscapesJail() bool {
+	return true
+}
+
+// NewPathEscapesJailError creates a PathEscapesJailError
+func NewPathEscapesJailError(path, root string) PathEscapesJailError {
+	return PathEscapesJailError{
+		LocalisableError: LocalisableError{
+			Data: PathEscapesJailTemplData{
+				Path: path,
+				Root: root,
+			},
+		},
+	}
+}
+
+// QueryPathEscapesJailError helper function to enable identification of
+// an error via its behaviour, rather than by its type.
+func QueryPathEscapesJailError(target error) bool {
+	return QueryGeneric[PathEscapesJailErrorBehaviourQuery]("PathEscapesJail", target)
+}
+
+// ❌ Read Only File System
+
+// ReadOnlyFileSystemTemplData mutative operation invoked on read only file system
+type ReadOnlyFileSystemTemplData struct {
+	ExtendioTemplData
+	Op   string
+	Path string
+}
+
+func (td ReadOnlyFileSystemTemplData) Message() *Message {
+	return &Message{
+		ID:          "read-only-file-system.error",
+		Description: "Mutative operation invoked on read only file system",
+		Other:       "can't {{.Op}} file system path '{{.Path}}', file system is read only",
+	}
+}
+
+// ReadOnlyFileSystemErrorBehaviourQuery used to query if an error is:
+// "Mutative operation invoked on read only file system"
+type ReadOnlyFileSystemErrorBehaviourQuery interface {
+	ReadOnlyFileSystem() bool
+}
+
+type ReadOnlyFileSystemError struct {
+	LocalisableError
+}
+
+// ReadOnlyFileSystem enables the client to check if error is ReadOnlyFileSystemError
+// via QueryReadOnlyFileSystemError
+func (e ReadOnlyFileSystemError) ReadOnlyFileSystem() bool {
+	return true
+}
+
+// NewReadOnlyFileSystemError creates a ReadOnlyFileSystemError
+func NewReadOnlyFileSystemError(op, path string) ReadOnlyFileSystemError {
+	return ReadOnlyFileSystemError{
+		LocalisableError: LocalisableError{
+			Data: ReadOnlyFileSystemTemplData{
+				Op:   op,
+				Path: path,
+			},
+		},
+	}
+}
+
+// QueryReadOnlyFileSystemError helper function to enable identification of
+// an error via its behaviour, rather than by its type.
+func QueryReadOnlyFileSystemError(target error) bool {
+	return QueryGeneric[ReadOnlyFileSystemErrorBehaviourQuery]("ReadOnlyFileSystem", target)
+}
+
+// ❌ Hash Not Available
+
+// HashNotAvailableTemplData hash requested for an algorithm that was not selected,
+// or for an item that is not a file
+type HashNotAvailableTemplData struct {
+	ExtendioTemplData
+	Algorithm string
+	Path      string
+}
+
+func (td HashNotAvailableTemplData) Message() *Message {
+	return &Message{
+		ID:          "hash-not-available.error",
+		Description: "Hash requested for an algorithm not selected, or for an item that is not a file",
+		Other:       "hash '{{.Algorithm}}' not available for file system path '{{.Path}}'",
+	}
+}
+
+// HashNotAvailableErrorBehaviourQuery used to query if an error is:
+// "Hash requested for an algorithm not selected, or for an item that is not a file"
+type HashNotAvailableErrorBehaviourQuery interface {
+	HashNotAvailable() bool
+}
+
+type HashNotAvailableError struct {
+	LocalisableError
+}
+
+// HashNotAvailable enables the client to check if error is HashNotAvailableError
+// via QueryHashNotAvailableError
+func (e HashNotAvailableError) HashNotAvailable() bool {
+	return true
+}
+
+// NewHashNotAvailableError creates a HashNotAvailableError
+func NewHashNotAvailableError(algorithm, path string) HashNotAvailableError {
+	return HashNotAvailableError{
+		LocalisableError: LocalisableError{
+			Data: HashNotAvailableTemplData{
+				Algorithm: algorithm,
+				Path:      path,
+			},
+		},
+	}
+}
+
+// QueryHashNotAvailableError helper function to enable identification of
+// an error via its behaviour, rather than by its type.
+func QueryHashNotAvailableError(target error) bool {
+	return QueryGeneric[HashNotAvailableErrorBehaviourQuery]("HashNotAvailable", target)
+}
+
+// ❌ Reorder Buffer Too Small
+
+// ReorderBufferTooSmallTemplData reorder buffer size requested is less than minimum
+type ReorderBufferTooSmallTemplData struct {
+	ExtendioTemplData
+	Size    int
+	Minimum int
+}
+
+func (td ReorderBufferTooSmallTemplData) Message() *Message {
+	return &Message{
+		ID:          "reorder-buffer-too-small.error",
+		Description: "Reorder buffer size requested is less than minimum",
+		Other:       "reorder buffer size requested ({{.Size}}) is less than minimum ('{{.Minimum}}')",
+	}
+}
+
+// ReorderBufferTooSmallErrorBehaviourQuery used to query if an error is:
+// "Reorder buffer size requested is less than minimum"
+type ReorderBufferTooSmallErrorBehaviourQuery interface {
+	ReorderBufferTooSmall() bool
+}
+
+type ReorderBufferTooSmallError struct {
+	LocalisableError
+}
+
+// ReorderBufferTooSmall enables the client to check if error is ReorderBufferTooSmallError
+// via QueryReorderBufferTooSmallError
+func (e ReorderBufferTooSmallError) ReorderBufferTooSmall() bool {
+	return true
+}
+
+// NewReorderBufferTooSmallError creates a ReorderBufferTooSmallError
+func NewReorderBufferTooSmallError(size, minimum int) ReorderBufferTooSmallError {
+	return ReorderBufferTooSmallError{
+		LocalisableError: LocalisableError{
+			Data: ReorderBufferTooSmallTemplData{
+				Size:    size,
+				Minimum: minimum,
+			},
+		},
+	}
+}
+
+// QueryReorderBufferTooSmallError helper function to enable identification of
+// an error via its behaviour, rather than by its type.
+func QueryReorderBufferTooSmallError(target error) bool {
+	return QueryGeneric[ReorderBufferTooSmallErrorBehaviourQuery]("ReorderBufferTooSmall", target)
+}
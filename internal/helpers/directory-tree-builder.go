@@ -1,173 +1,21 @@
 package helpers
 
 import (
-	"bytes"
-	"encoding/xml"
-	"fmt"
-	"os"
-	"path/filepath"
-
-	"github.com/snivilised/extendio/collections"
-	"github.com/snivilised/extendio/internal/lo"
-
-	"github.com/snivilised/extendio/xfs/utils"
+	"github.com/snivilised/extendio/xfs/fixture"
+	"github.com/snivilised/extendio/xfs/storage"
 )
 
-const offset = 2
-const tabSize = 2
-
-type directoryTreeBuilder struct {
-	root    string
-	full    string
-	stack   *collections.Stack[string]
-	index   string
-	write   bool
-	depth   int
-	padding string
-}
-
-func (r *directoryTreeBuilder) read() (*Directory, error) {
-	data, err := os.ReadFile(r.index)
-
-	if err != nil {
-		return nil, err
-	}
-
-	var tree Tree
-	err = xml.Unmarshal(data, &tree)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &tree.Root, nil
-}
-
-func (r *directoryTreeBuilder) status(path string) string {
-	return lo.Ternary(utils.Exists(path), "✔️", "❌")
-}
-
-func (r *directoryTreeBuilder) pad() string {
-	return string(bytes.Repeat([]byte{' '}, (r.depth+offset)*tabSize))
-}
-
-func (r *directoryTreeBuilder) refill() string {
-	segments := r.stack.Content()
-	return filepath.Join(segments...)
-}
-
-func (r *directoryTreeBuilder) inc(name string) {
-	r.stack.Push(name)
-	r.full = r.refill()
-
-	r.depth++
-	r.padding = r.pad()
-}
-
-func (r *directoryTreeBuilder) dec() {
-	_, _ = r.stack.Pop()
-	r.full = r.refill()
-
-	r.depth--
-	r.padding = r.pad()
-}
-
-func (r *directoryTreeBuilder) show(path, indicator, name string) {
-	status := r.status(path)
-	fmt.Printf("%v(depth: '%v') (%v) %v: -> '%v' (🔥 %v)\n",
-		r.padding, r.depth, status, indicator, name, r.full,
-	)
-}
-
-func (r *directoryTreeBuilder) walk() error {
-	fmt.Printf("\n🤖 re-generating tree at '%v'\n", r.root)
-
-	top, err := r.read()
-
-	if err != nil {
-		return err
-	}
-
-	r.full = r.root
-
-	return r.dir(*top)
-}
-
-func (r *directoryTreeBuilder) dir(dir Directory) error { //nolint:gocritic // performance is not an issue
-	r.inc(dir.Name)
-
-	_, dn := utils.SplitParent(dir.Name)
-
-	if r.write {
-		err := os.MkdirAll(r.full, os.ModePerm)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	r.show(r.full, "📂", dn)
-
-	for _, directory := range dir.Directories {
-		err := r.dir(directory)
-		if err != nil {
-			return err
-		}
-	}
-
-	for _, file := range dir.Files {
-		fp := Path(r.full, file.Name)
-
-		if r.write {
-			err := os.WriteFile(fp, []byte(file.Text), os.ModePerm)
-			if err != nil {
-				return err
-			}
-		}
-
-		r.show(fp, "  📜", file.Name)
-	}
-
-	r.dec()
-
-	return nil
-}
-
-type Tree struct {
-	XMLName xml.Name  `xml:"tree"`
-	Root    Directory `xml:"directory"`
-}
-
-type Directory struct {
-	XMLName     xml.Name    `xml:"directory"`
-	Name        string      `xml:"name,attr"`
-	Files       []File      `xml:"file"`
-	Directories []Directory `xml:"directory"`
-}
-
-type File struct {
-	XMLName xml.Name `xml:"file"`
-	Name    string   `xml:"name,attr"`
-	Text    string   `xml:",chardata"`
-}
-
-const doWrite = true
-
+// Ensure builds the test tree described by the musico index at the root
+// specified on the native file system, if it does not already exist.
 func Ensure(root string) error {
-	repo := Repo("../..")
-	index := Path(repo, "Test/data/musico-index.xml")
-
-	if utils.FolderExists(root) {
-		return nil
-	}
+	return EnsureIn(root, storage.UseNativeFS())
+}
 
-	parent, _ := utils.SplitParent(root)
-	builder := directoryTreeBuilder{
-		root:  root,
-		stack: collections.NewStackWith([]string{parent}),
-		index: index,
-		write: doWrite,
-	}
+// EnsureIn builds the test tree described by the musico index at the root
+// specified, on the virtual file system provided, if it does not already
+// exist.
+func EnsureIn(root string, vfs storage.VirtualFS) error {
+	index := Path(Repo("../.."), "Test/data/musico-index.xml")
 
-	return builder.walk()
+	return fixture.EnsureFrom(vfs, root, index)
 }
@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UpdateGoldenEnv is the name of the environment variable that when set,
+// causes golden files to be re-generated from the actual output, instead
+// of being compared against.
+const UpdateGoldenEnv = "EXTENDIO_UPDATE_GOLDEN"
+
+// GoldenPath returns the path of the golden output file identified by name.
+func GoldenPath(name string) string {
+	return Path(Repo("../.."), "Test/data/golden/"+name+".golden.txt")
+}
+
+// Golden returns the expected lines of output recorded in the golden file
+// identified by name. If the UpdateGoldenEnv environment variable is set, then
+// the golden file is re-written with the actual lines provided, which are then
+// returned as the expected output.
+func Golden(name string, actual []string) ([]string, error) {
+	path := GoldenPath(name)
+
+	if _, update := os.LookupEnv(UpdateGoldenEnv); update {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return nil, err
+		}
+
+		content := strings.Join(actual, "\n") + "\n"
+
+		return actual, os.WriteFile(path, []byte(content), 0o600)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
@@ -0,0 +1,73 @@
+package bench
+
+import (
+	"github.com/snivilised/extendio/xfs/fixture"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// Tree is a synthetic tree built on a file system, which is traversed by
+// the benchmarks.
+type Tree struct {
+	// FS is the file system the tree is built on
+	//
+	FS storage.VirtualFS
+
+	// Root is the path of the root directory of the tree
+	//
+	Root string
+
+	// Shape is the shape of the tree
+	//
+	Shape fixture.Shape
+}
+
+// Scenario defines a traversal that is measured.
+type Scenario struct {
+	// Name identifies the scenario in the name of its benchmark. It should
+	// take the form key=value, so that the results can be compared by
+	// benchstat, eg "subscription=files".
+	//
+	Name string
+
+	// OptionsFn customises the traversal. The Callback and the hooks that
+	// read the file system are always overridden.
+	//
+	OptionsFn nav.TraverseOptionFn
+
+	// NoW, when positive, is the number of workers of the pool with which the
+	// traversal is accelerated (see nav.NavigationRunner.RunAsync); otherwise
+	// the traversal runs sequentially.
+	//
+	NoW int
+}
+
+// Measurement contains the results of measuring a scenario, which are also
+// reported as custom metrics of the benchmark.
+type Measurement struct {
+	// Items is the number of items for which the callback was invoked, over
+	// all iterations
+	//
+	Items uint64
+
+	// ItemsPerSecond is the throughput of the traversal, reported as "items/s"
+	//
+	ItemsPerSecond float64
+
+	// AllocsPerItem is the number of heap allocations made per item, reported
+	// as "allocs/item"
+	//
+	AllocsPerItem float64
+}
+
+// DefaultShapes are the shapes of the trees measured by default: a deep and
+// narrow tree, a shallow and wide tree and a balanced tree.
+var DefaultShapes = []fixture.Shape{
+	{Depth: 6, FanOut: 2, Files: 4},
+	{Depth: 1, FanOut: 16, Files: 64},
+	{Depth: 3, FanOut: 4, Files: 16},
+}
+
+// Extensions are the extensions of the files of the synthetic trees, which
+// are selected by the Filters scenarios.
+var Extensions = []string{"txt", "jpg", "mp3", "flac"}
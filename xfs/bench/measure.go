@@ -0,0 +1,157 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/snivilised/extendio/xfs/fixture"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// Prepare builds a synthetic tree of the shape specified inside the root
+// path on the file system, unless it already exists. The files are given
+// the Extensions, unless the shape defines its own.
+func Prepare(vfs storage.VirtualFS, root string, shape *fixture.Shape) (*Tree, error) {
+	tree := &Tree{
+		FS:    vfs,
+		Root:  root,
+		Shape: *shape,
+	}
+
+	if len(tree.Shape.Extensions) == 0 {
+		tree.Shape.Extensions = Extensions
+	}
+
+	if err := fixture.Ensure(vfs, root, fixture.Synthesise(&tree.Shape)); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// Trees prepares a tree of each of the shapes, both in memory and on disk
+// (inside a temporary directory of the benchmark).
+func Trees(b *testing.B, shapes []fixture.Shape) []*Tree {
+	b.Helper()
+
+	var (
+		trees = make([]*Tree, 0, len(shapes)*2)
+		temp  = b.TempDir()
+	)
+
+	for _, vfs := range []storage.VirtualFS{storage.UseMemFS(), storage.UseNativeFS()} {
+		for i := range shapes {
+			root := filepath.Join(temp, string(vfs.Backend()), shapes[i].String())
+
+			tree, err := Prepare(vfs, root, &shapes[i])
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			trees = append(trees, tree)
+		}
+	}
+
+	return trees
+}
+
+// Run measures each scenario over each tree, as a sub-benchmark of b, named
+// after the file system, the shape of the tree and the scenario, eg
+// "fs=mem/shape=d3f4n16/subscription=files", so that benchstat can compare
+// the results along each of these dimensions.
+func Run(b *testing.B, trees []*Tree, scenarios []Scenario) {
+	b.Helper()
+
+	for _, tree := range trees {
+		for i := range scenarios {
+			scenario := &scenarios[i]
+			name := fmt.Sprintf("fs=%v/shape=%v/%v", tree.FS.Backend(), tree.Shape.String(), scenario.Name)
+
+			b.Run(name, func(b *testing.B) {
+				Measure(b, tree, scenario)
+			})
+		}
+	}
+}
+
+// Measure traverses the tree with the scenario b.N times. Alongside the
+// standard metrics, the throughput ("items/s") and the allocations per item
+// ("allocs/item") are reported as custom metrics, where an item is one for
+// which the callback was invoked.
+func Measure(b *testing.B, tree *Tree, scenario *Scenario) *Measurement {
+	b.Helper()
+
+	var (
+		items  uint64
+		before runtime.MemStats
+		after  runtime.MemStats
+	)
+
+	optionsFn := func(o *nav.TraverseOptions) {
+		if scenario.OptionsFn != nil {
+			scenario.OptionsFn(o)
+		}
+
+		o.Hooks.QueryStatus = nav.VirtualQueryStatusHookFn(tree.FS)
+		o.Hooks.ReadDirectory = nav.VirtualReadEntriesHookFn(tree.FS)
+		o.Hooks.OpenDirectory = nav.VirtualOpenEntriesHookFn(tree.FS)
+		o.Callback = &nav.LabelledTraverseCallback{
+			Label: "bench callback",
+			Fn: func(_ *nav.TraverseItem) error {
+				atomic.AddUint64(&items, 1)
+
+				return nil
+			},
+		}
+	}
+
+	b.ReportAllocs()
+	runtime.GC()
+	b.ResetTimer()
+	runtime.ReadMemStats(&before)
+
+	for i := 0; i < b.N; i++ {
+		if err := traverse(tree, scenario, optionsFn); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	runtime.ReadMemStats(&after)
+	b.StopTimer()
+
+	measurement := &Measurement{
+		Items: items,
+	}
+
+	if items > 0 {
+		measurement.ItemsPerSecond = float64(items) / b.Elapsed().Seconds()
+		measurement.AllocsPerItem = float64(after.Mallocs-before.Mallocs) / float64(items)
+	}
+
+	b.ReportMetric(measurement.ItemsPerSecond, "items/s")
+	b.ReportMetric(measurement.AllocsPerItem, "allocs/item")
+
+	return measurement
+}
+
+func traverse(tree *Tree, scenario *Scenario, optionsFn nav.TraverseOptionFn) error {
+	runner := nav.New().Primary(&nav.Prime{
+		Path:      tree.Root,
+		OptionsFn: optionsFn,
+	})
+
+	if scenario.NoW > 0 {
+		_, err := runner.RunAsync(context.Background(), scenario.NoW).Wait()
+
+		return err
+	}
+
+	_, err := runner.Run()
+
+	return err
+}
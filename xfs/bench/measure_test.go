@@ -0,0 +1,132 @@
+package bench_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/bench"
+	"github.com/snivilised/extendio/xfs/fixture"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+type measureTE struct {
+	scenario string
+	backend  string
+	expected func(directories, files uint) uint
+}
+
+var _ = Describe("Measure", Ordered, func() {
+	var (
+		shape    = fixture.Shape{Depth: 2, FanOut: 3, Files: 8}
+		backends map[string]storage.VirtualFS
+		trees    map[string]*bench.Tree
+	)
+
+	BeforeAll(func() {
+		backends = map[string]storage.VirtualFS{
+			"native": storage.UseNativeFS(),
+			"mem":    storage.UseMemFS(),
+		}
+		trees = map[string]*bench.Tree{}
+
+		for name, vfs := range backends {
+			tree, err := bench.Prepare(vfs, filepath.Join(GinkgoT().TempDir(), "synthetic"), &shape)
+			Expect(err).To(Succeed())
+
+			trees[name] = tree
+		}
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	scenario := func(name string) *bench.Scenario {
+		for _, scenarios := range [][]bench.Scenario{
+			bench.Subscriptions, bench.Filters, bench.Samplers, bench.Pooling, bench.Acceleration,
+		} {
+			for i := range scenarios {
+				if scenarios[i].Name == name {
+					return &scenarios[i]
+				}
+			}
+		}
+
+		Fail(fmt.Sprintf("no such scenario: '%v'", name))
+
+		return nil
+	}
+
+	DescribeTable("scenarios",
+		func(entry *measureTE) {
+			var (
+				measurement *bench.Measurement
+				iterations  int
+			)
+
+			testing.Benchmark(func(b *testing.B) {
+				measurement = bench.Measure(b, trees[entry.backend], scenario(entry.scenario))
+				iterations = b.N
+			})
+
+			directories, files := shape.Count()
+			Expect(measurement.Items).To(
+				Equal(uint64(iterations) * uint64(entry.expected(directories, files))),
+			)
+			Expect(measurement.ItemsPerSecond).To(BeNumerically(">", 0))
+			Expect(measurement.AllocsPerItem).To(BeNumerically(">", 0))
+		},
+		func(entry *measureTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'",
+				entry.scenario+" on "+entry.backend, "count items invoked",
+			)
+		},
+
+		Entry(nil, &measureTE{
+			scenario: "subscription=any",
+			backend:  "mem",
+			expected: func(directories, files uint) uint { return directories + files },
+		}),
+
+		Entry(nil, &measureTE{
+			scenario: "subscription=files",
+			backend:  "native",
+			expected: func(_, files uint) uint { return files },
+		}),
+
+		Entry(nil, &measureTE{
+			scenario: "subscription=folders",
+			backend:  "mem",
+			expected: func(directories, _ uint) uint { return directories },
+		}),
+
+		Entry(nil, &measureTE{
+			scenario: "filter=glob",
+			backend:  "mem",
+			expected: func(directories, files uint) uint {
+				return directories + files/uint(len(bench.Extensions))
+			},
+		}),
+
+		Entry(nil, &measureTE{
+			scenario: "pooled=true/streamed=true",
+			backend:  "native",
+			expected: func(directories, files uint) uint { return directories + files },
+		}),
+
+		Entry(nil, &measureTE{
+			scenario: "workers=4",
+			backend:  "mem",
+			expected: func(directories, files uint) uint { return directories + files },
+		}),
+	)
+})
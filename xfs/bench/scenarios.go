@@ -0,0 +1,121 @@
+package bench
+
+import (
+	"github.com/snivilised/extendio/xfs/nav"
+)
+
+// Subscriptions are the scenarios that measure each navigator, by way of
+// the subscriptions that select them.
+var Subscriptions = []Scenario{
+	{Name: "subscription=any", OptionsFn: subscribe(nav.SubscribeAny)},
+	{Name: "subscription=files", OptionsFn: subscribe(nav.SubscribeFiles)},
+	{Name: "subscription=folders", OptionsFn: subscribe(nav.SubscribeFolders)},
+	{Name: "subscription=folders-with-files", OptionsFn: subscribe(nav.SubscribeFoldersWithFiles)},
+}
+
+// Filters are the scenarios that measure each type of filter, all of which
+// select the same files.
+var Filters = []Scenario{
+	{Name: "filter=none", OptionsFn: subscribe(nav.SubscribeAny)},
+	{Name: "filter=glob", OptionsFn: filter(&nav.FilterDef{
+		Type:    nav.FilterTypeGlobEn,
+		Pattern: "*.jpg",
+		Scope:   nav.ScopeFileEn,
+	})},
+	{Name: "filter=regex", OptionsFn: filter(&nav.FilterDef{
+		Type:    nav.FilterTypeRegexEn,
+		Pattern: `\.jpg$`,
+		Scope:   nav.ScopeFileEn,
+	})},
+	{Name: "filter=extended-glob", OptionsFn: filter(&nav.FilterDef{
+		Type:    nav.FilterTypeExtendedGlobEn,
+		Pattern: "*|jpg",
+		Scope:   nav.ScopeFileEn,
+	})},
+	{Name: "filter=poly", OptionsFn: filter(&nav.FilterDef{
+		Type: nav.FilterTypePolyEn,
+		Poly: &nav.PolyFilterDef{
+			File: nav.FilterDef{
+				Type:    nav.FilterTypeExtendedGlobEn,
+				Pattern: "*|jpg",
+				Scope:   nav.ScopeFileEn,
+			},
+			Folder: nav.FilterDef{
+				Type:    nav.FilterTypeGlobEn,
+				Pattern: "dir-*",
+				Scope:   nav.ScopeFolderEn,
+			},
+		},
+	})},
+}
+
+// Samplers are the scenarios that measure sampling.
+var Samplers = []Scenario{
+	{Name: "sampling=none", OptionsFn: subscribe(nav.SubscribeAny)},
+	{Name: "sampling=slice", OptionsFn: sample(nav.SampleTypeSliceEn, false)},
+	{Name: "sampling=slice-reverse", OptionsFn: sample(nav.SampleTypeSliceEn, true)},
+	{Name: "sampling=filter", OptionsFn: func(o *nav.TraverseOptions) {
+		filter(&nav.FilterDef{
+			Type:    nav.FilterTypeGlobEn,
+			Pattern: "*.jpg",
+			Scope:   nav.ScopeFileEn,
+		})(o)
+		sample(nav.SampleTypeFilterEn, false)(o)
+	}},
+}
+
+// Pooling are the scenarios that measure recycling (see nav.PoolingOptions),
+// with and without streaming.
+var Pooling = []Scenario{
+	{Name: "pooled=false/streamed=false", OptionsFn: pool(false, 0)},
+	{Name: "pooled=true/streamed=false", OptionsFn: pool(true, 0)},
+	{Name: "pooled=false/streamed=true", OptionsFn: pool(false, 16)},
+	{Name: "pooled=true/streamed=true", OptionsFn: pool(true, 16)},
+}
+
+// Acceleration are the scenarios that measure traversals accelerated by a
+// pool of workers, against a sequential traversal.
+var Acceleration = []Scenario{
+	{Name: "workers=none", OptionsFn: subscribe(nav.SubscribeAny)},
+	{Name: "workers=1", OptionsFn: subscribe(nav.SubscribeAny), NoW: 1},
+	{Name: "workers=2", OptionsFn: subscribe(nav.SubscribeAny), NoW: 2},
+	{Name: "workers=4", OptionsFn: subscribe(nav.SubscribeAny), NoW: 4},
+	{Name: "workers=8", OptionsFn: subscribe(nav.SubscribeAny), NoW: 8},
+}
+
+func subscribe(subscription nav.TraverseSubscription) nav.TraverseOptionFn {
+	return func(o *nav.TraverseOptions) {
+		o.Store.Subscription = subscription
+	}
+}
+
+func filter(def *nav.FilterDef) nav.TraverseOptionFn {
+	return func(o *nav.TraverseOptions) {
+		o.Store.Subscription = nav.SubscribeAny
+		o.Store.FilterDefs = &nav.FilterDefinitions{
+			Node: *def,
+		}
+	}
+}
+
+func sample(sampleType nav.SampleTypeEnum, reverse bool) nav.TraverseOptionFn {
+	return func(o *nav.TraverseOptions) {
+		o.Store.Subscription = nav.SubscribeAny
+		o.Store.Sampling = nav.SamplingOptions{
+			SampleType:      sampleType,
+			SampleInReverse: reverse,
+			NoOf: nav.EntryQuantities{
+				Files:   2,
+				Folders: 2,
+			},
+		}
+	}
+}
+
+func pool(recycle bool, batchSize uint) nav.TraverseOptionFn {
+	return func(o *nav.TraverseOptions) {
+		o.Store.Subscription = nav.SubscribeAny
+		o.Store.Pooling.Recycle = recycle
+		o.Store.Streaming.BatchSize = batchSize
+	}
+}
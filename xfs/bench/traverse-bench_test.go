@@ -0,0 +1,35 @@
+package bench_test
+
+import (
+	"testing"
+
+	"github.com/snivilised/extendio/xfs/bench"
+)
+
+// The benchmarks are run with:
+//
+//	go test ./xfs/bench -run '^$' -bench . -count 10 > new.txt
+//
+// and compared with a previous run by:
+//
+//	benchstat old.txt new.txt
+
+func BenchmarkSubscriptions(b *testing.B) {
+	bench.Run(b, bench.Trees(b, bench.DefaultShapes), bench.Subscriptions)
+}
+
+func BenchmarkFilters(b *testing.B) {
+	bench.Run(b, bench.Trees(b, bench.DefaultShapes), bench.Filters)
+}
+
+func BenchmarkSampling(b *testing.B) {
+	bench.Run(b, bench.Trees(b, bench.DefaultShapes), bench.Samplers)
+}
+
+func BenchmarkPooling(b *testing.B) {
+	bench.Run(b, bench.Trees(b, bench.DefaultShapes), bench.Pooling)
+}
+
+func BenchmarkAcceleration(b *testing.B) {
+	bench.Run(b, bench.Trees(b, bench.DefaultShapes), bench.Acceleration)
+}
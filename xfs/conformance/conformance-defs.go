@@ -0,0 +1,38 @@
+package conformance
+
+import (
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// Constructor creates a fresh instance of the VirtualFS under test. It is
+// invoked before every spec.
+type Constructor func() storage.VirtualFS
+
+// Options customises the conformance suite for a particular backend.
+type Options struct {
+	// Unsupported contains the names of the methods that the backend
+	// deliberately does not support (eg "Chown"). Instead of the usual specs,
+	// a single spec verifies that the method fails with errors.ErrUnsupported.
+	//
+	Unsupported []string
+
+	// NoSymlinks indicates that the backend (or the platform) does not
+	// support symbolic links; the specs that depend on them are skipped.
+	//
+	NoSymlinks bool
+
+	// NoLinks indicates that the backend (or the platform) does not support
+	// hard links; the specs that depend on them are skipped.
+	//
+	NoLinks bool
+}
+
+func (o *Options) unsupported(method string) bool {
+	for _, name := range o.Unsupported {
+		if name == method {
+			return true
+		}
+	}
+
+	return false
+}
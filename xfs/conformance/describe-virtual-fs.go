@@ -0,0 +1,910 @@
+package conformance
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/xfs/matchers"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	dirPerm  = fs.FileMode(0o755)
+	filePerm = fs.FileMode(0o644)
+)
+
+// DescribeVirtualFS registers a Ginkgo container of specs that verify that
+// the VirtualFS created by the constructor behaves as the native file system
+// does. It must be invoked at the top level of a test file, eg:
+//
+//	var _ = conformance.DescribeVirtualFS("custom", UseCustomFS, nil)
+//
+// Every spec runs in its own temporary directory, obtained from
+// GinkgoT().TempDir(), which is created on the backend via MkdirAll, so
+// backends that are not backed by the native file system are also supported.
+func DescribeVirtualFS(name string, constructor Constructor, options *Options) bool {
+	if options == nil {
+		options = &Options{}
+	}
+
+	return Describe(fmt.Sprintf("VirtualFS conformance: '%v'", name), func() {
+		var (
+			vfs  storage.VirtualFS
+			root string
+		)
+
+		path := func(segments ...string) string {
+			return filepath.Join(append([]string{root}, segments...)...)
+		}
+
+		write := func(name, content string) string {
+			target := path(name)
+
+			Expect(vfs.MkdirAll(filepath.Dir(target), dirPerm)).To(Succeed())
+			Expect(vfs.WriteFile(target, []byte(content), filePerm)).To(Succeed())
+
+			return target
+		}
+
+		read := func(name string) string {
+			data, err := vfs.ReadFile(path(name))
+			Expect(err).To(Succeed())
+
+			return string(data)
+		}
+
+		directory := func(name string) string {
+			target := path(name)
+			Expect(vfs.MkdirAll(target, dirPerm)).To(Succeed())
+
+			return target
+		}
+
+		requireSymlinks := func() {
+			if options.NoSymlinks {
+				Skip("symbolic links not supported")
+			}
+		}
+
+		requireLinks := func() {
+			if options.NoLinks {
+				Skip("hard links not supported")
+			}
+		}
+
+		// method registers the specs for the method, unless it is
+		// unsupported, in which case only the probe is verified.
+		method := func(name string, body func(), probe func() error) {
+			if options.unsupported(name) {
+				Describe(name, func() {
+					It("🧪 should: report unsupported", func() {
+						Expect(errors.Is(probe(), errors.ErrUnsupported)).To(BeTrue(),
+							fmt.Sprintf("🔥 expected %v to fail with errors.ErrUnsupported", name),
+						)
+					})
+				})
+
+				return
+			}
+
+			Describe(name, body)
+		}
+
+		BeforeEach(func() {
+			root = GinkgoT().TempDir()
+			vfs = constructor()
+
+			Expect(vfs.MkdirAll(root, dirPerm)).To(Succeed())
+		})
+
+		// interface ExistsInFS
+
+		Describe("FileExists", func() {
+			It("🧪 should: report existing file", func() {
+				Expect(matchers.AsFile(write("a.txt", "alpha"))).To(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: not report directory", func() {
+				Expect(matchers.AsFile(directory("dir"))).NotTo(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: not report missing path", func() {
+				Expect(matchers.AsFile(path("missing.txt"))).NotTo(matchers.ExistInFS(vfs))
+			})
+		})
+
+		Describe("DirectoryExists", func() {
+			It("🧪 should: report existing directory", func() {
+				Expect(matchers.AsDirectory(directory("dir"))).To(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: not report file", func() {
+				Expect(matchers.AsDirectory(write("a.txt", "alpha"))).NotTo(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: not report missing path", func() {
+				Expect(matchers.AsDirectory(path("missing"))).NotTo(matchers.ExistInFS(vfs))
+			})
+		})
+
+		// end: interface ExistsInFS
+
+		// interface ReadFromFS
+
+		Describe("Lstat", func() {
+			It("🧪 should: describe file", func() {
+				write("a.txt", "alpha")
+
+				info, err := vfs.Lstat(path("a.txt"))
+				Expect(err).To(Succeed())
+				Expect(info.Name()).To(Equal("a.txt"))
+				Expect(info.Size()).To(Equal(int64(len("alpha"))))
+				Expect(info.Mode().IsRegular()).To(BeTrue())
+				Expect(info.Mode().Perm()).To(Equal(filePerm))
+			})
+
+			It("🧪 should: describe directory", func() {
+				directory("dir")
+
+				info, err := vfs.Lstat(path("dir"))
+				Expect(err).To(Succeed())
+				Expect(info.IsDir()).To(BeTrue())
+			})
+
+			It("🧪 should: not follow symlink", func() {
+				requireSymlinks()
+				Expect(vfs.Symlink(write("a.txt", "alpha"), path("link"))).To(Succeed())
+
+				info, err := vfs.Lstat(path("link"))
+				Expect(err).To(Succeed())
+				Expect(info.Mode() & fs.ModeSymlink).NotTo(BeZero())
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				_, err := vfs.Lstat(path("missing.txt"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("Stat", func() {
+			It("🧪 should: follow symlink", func() {
+				requireSymlinks()
+				Expect(vfs.Symlink(write("a.txt", "alpha"), path("link"))).To(Succeed())
+
+				info, err := vfs.Stat(path("link"))
+				Expect(err).To(Succeed())
+				Expect(info.Mode().IsRegular()).To(BeTrue())
+				Expect(info.Size()).To(Equal(int64(len("alpha"))))
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				_, err := vfs.Stat(path("missing.txt"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+
+			It("🧪 should: fail for dangling symlink", func() {
+				requireSymlinks()
+				Expect(vfs.Symlink(path("missing.txt"), path("link"))).To(Succeed())
+
+				_, err := vfs.Stat(path("link"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("Glob", func() {
+			It("🧪 should: return sorted matches", func() {
+				write("b.txt", "bravo")
+				write("a.txt", "alpha")
+				write("c.flac", "charlie")
+
+				matches, err := vfs.Glob(path("*.txt"))
+				Expect(err).To(Succeed())
+				Expect(matches).To(Equal([]string{path("a.txt"), path("b.txt")}))
+			})
+
+			It("🧪 should: match in multiple directories", func() {
+				write(filepath.Join("x", "a.txt"), "alpha")
+				write(filepath.Join("y", "a.txt"), "alpha")
+
+				matches, err := vfs.Glob(path("*", "a.txt"))
+				Expect(err).To(Succeed())
+				Expect(matches).To(Equal([]string{path("x", "a.txt"), path("y", "a.txt")}))
+			})
+
+			It("🧪 should: return no matches without error", func() {
+				matches, err := vfs.Glob(path("*.missing"))
+				Expect(err).To(Succeed())
+				Expect(matches).To(BeEmpty())
+			})
+
+			It("🧪 should: fail for malformed pattern", func() {
+				_, err := vfs.Glob(path("["))
+				Expect(err).To(MatchError(filepath.ErrBadPattern))
+			})
+		})
+
+		Describe("Open", func() {
+			It("🧪 should: read file content", func() {
+				file, err := vfs.Open(write("a.txt", "alpha"))
+				Expect(err).To(Succeed())
+
+				defer file.Close()
+
+				data, err := io.ReadAll(file)
+				Expect(err).To(Succeed())
+				Expect(string(data)).To(Equal("alpha"))
+			})
+
+			It("🧪 should: read at offset", func() {
+				file, err := vfs.Open(write("a.txt", "alpha"))
+				Expect(err).To(Succeed())
+
+				defer file.Close()
+
+				buffer := make([]byte, 3)
+				_, err = file.ReadAt(buffer, 2)
+				Expect(err).To(Succeed())
+				Expect(string(buffer)).To(Equal("pha"))
+			})
+
+			It("🧪 should: read directory entries", func() {
+				write(filepath.Join("dir", "a.txt"), "alpha")
+				write(filepath.Join("dir", "b.txt"), "bravo")
+
+				file, err := vfs.Open(path("dir"))
+				Expect(err).To(Succeed())
+
+				defer file.Close()
+
+				entries, err := file.ReadDir(-1)
+				Expect(err).To(Succeed())
+
+				names := make([]string, 0, len(entries))
+				for _, entry := range entries {
+					names = append(names, entry.Name())
+				}
+
+				slices.Sort(names)
+				Expect(names).To(Equal([]string{"a.txt", "b.txt"}))
+			})
+
+			It("🧪 should: not permit write", func() {
+				file, err := vfs.Open(write("a.txt", "alpha"))
+				Expect(err).To(Succeed())
+
+				defer file.Close()
+
+				_, err = file.WriteString("bravo")
+				Expect(err).To(HaveOccurred())
+				Expect(read("a.txt")).To(Equal("alpha"))
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				_, err := vfs.Open(path("missing.txt"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("ReadFile", func() {
+			It("🧪 should: read content", func() {
+				write("a.txt", "alpha")
+				Expect(read("a.txt")).To(Equal("alpha"))
+			})
+
+			It("🧪 should: read empty file", func() {
+				write("empty.txt", "")
+				Expect(read("empty.txt")).To(BeEmpty())
+			})
+
+			It("🧪 should: fail for directory", func() {
+				_, err := vfs.ReadFile(directory("dir"))
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				_, err := vfs.ReadFile(path("missing.txt"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("ReadDir", func() {
+			It("🧪 should: return entries sorted by name", func() {
+				write(filepath.Join("dir", "b.txt"), "bravo")
+				write(filepath.Join("dir", "a.txt"), "alpha")
+				directory(filepath.Join("dir", "c"))
+
+				entries, err := vfs.ReadDir(path("dir"))
+				Expect(err).To(Succeed())
+				Expect(entries).To(HaveLen(3))
+				Expect(entries[0].Name()).To(Equal("a.txt"))
+				Expect(entries[1].Name()).To(Equal("b.txt"))
+				Expect(entries[2].Name()).To(Equal("c"))
+				Expect(entries[0].IsDir()).To(BeFalse())
+				Expect(entries[2].IsDir()).To(BeTrue())
+			})
+
+			It("🧪 should: return no entries for empty directory", func() {
+				entries, err := vfs.ReadDir(directory("empty"))
+				Expect(err).To(Succeed())
+				Expect(entries).To(BeEmpty())
+			})
+
+			It("🧪 should: fail for file", func() {
+				_, err := vfs.ReadDir(write("a.txt", "alpha"))
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				_, err := vfs.ReadDir(path("missing"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("ReadLink", func() {
+			It("🧪 should: return target", func() {
+				requireSymlinks()
+				target := write("a.txt", "alpha")
+				Expect(vfs.Symlink(target, path("link"))).To(Succeed())
+
+				actual, err := vfs.ReadLink(path("link"))
+				Expect(err).To(Succeed())
+				Expect(actual).To(Equal(target))
+			})
+
+			It("🧪 should: return relative target verbatim", func() {
+				requireSymlinks()
+				write("a.txt", "alpha")
+				Expect(vfs.Symlink("a.txt", path("link"))).To(Succeed())
+
+				actual, err := vfs.ReadLink(path("link"))
+				Expect(err).To(Succeed())
+				Expect(actual).To(Equal("a.txt"))
+			})
+
+			It("🧪 should: fail for regular file", func() {
+				_, err := vfs.ReadLink(write("a.txt", "alpha"))
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				_, err := vfs.ReadLink(path("missing"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("WalkDir", func() {
+			BeforeEach(func() {
+				write(filepath.Join("tree", "a.txt"), "alpha")
+				write(filepath.Join("tree", "sub", "b.txt"), "bravo")
+				write(filepath.Join("tree", "z.txt"), "zulu")
+			})
+
+			walk := func(skip string) ([]string, error) {
+				visited := []string{}
+				err := vfs.WalkDir(path("tree"), func(current string, entry fs.DirEntry, err error) error {
+					if err != nil {
+						return err
+					}
+
+					visited = append(visited, strings.TrimPrefix(current, path("tree")))
+
+					if skip != "" && entry.Name() == skip {
+						return filepath.SkipDir
+					}
+
+					return nil
+				})
+
+				return visited, err
+			}
+
+			It("🧪 should: visit all in lexical order", func() {
+				visited, err := walk("")
+				Expect(err).To(Succeed())
+				Expect(visited).To(Equal([]string{
+					"",
+					sep("a.txt"),
+					sep("sub"),
+					sep("sub", "b.txt"),
+					sep("z.txt"),
+				}))
+			})
+
+			It("🧪 should: skip directory", func() {
+				visited, err := walk("sub")
+				Expect(err).To(Succeed())
+				Expect(visited).To(Equal([]string{"", sep("a.txt"), sep("sub"), sep("z.txt")}))
+			})
+
+			It("🧪 should: skip remaining files in directory", func() {
+				visited, err := walk("a.txt")
+				Expect(err).To(Succeed())
+				Expect(visited).To(Equal([]string{"", sep("a.txt")}))
+			})
+
+			It("🧪 should: report missing root to callback", func() {
+				var reported error
+
+				err := vfs.WalkDir(path("missing"), func(_ string, _ fs.DirEntry, err error) error {
+					reported = err
+
+					return err
+				})
+				Expect(err).To(MatchError(fs.ErrNotExist))
+				Expect(reported).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		// end: interface ReadFromFS
+
+		// interface WriteToFS
+
+		Describe("Chmod", func() {
+			It("🧪 should: change permissions", func() {
+				target := write("a.txt", "alpha")
+				Expect(vfs.Chmod(target, 0o600)).To(Succeed())
+
+				info, err := vfs.Lstat(target)
+				Expect(err).To(Succeed())
+				Expect(info.Mode().Perm()).To(Equal(fs.FileMode(0o600)))
+			})
+
+			It("🧪 should: change directory permissions", func() {
+				target := directory("dir")
+				Expect(vfs.Chmod(target, 0o700)).To(Succeed())
+
+				info, err := vfs.Lstat(target)
+				Expect(err).To(Succeed())
+				Expect(info.IsDir()).To(BeTrue())
+				Expect(info.Mode().Perm()).To(Equal(fs.FileMode(0o700)))
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				Expect(vfs.Chmod(path("missing.txt"), 0o600)).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("Chtimes", func() {
+			It("🧪 should: change modification time", func() {
+				target := write("a.txt", "alpha")
+				mtime := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+				Expect(vfs.Chtimes(target, mtime, mtime)).To(Succeed())
+
+				info, err := vfs.Lstat(target)
+				Expect(err).To(Succeed())
+				Expect(info.ModTime().Equal(mtime)).To(BeTrue(),
+					fmt.Sprintf("🔥 expected mod time '%v' to be '%v'", info.ModTime(), mtime),
+				)
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				now := time.Now()
+				Expect(vfs.Chtimes(path("missing.txt"), now, now)).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		method("Chown", func() {
+			It("🧪 should: fail for missing path", func() {
+				Expect(vfs.Chown(path("missing.txt"), os.Getuid(), os.Getgid())).To(MatchError(fs.ErrNotExist))
+			})
+		}, func() error {
+			return vfs.Chown(write("a.txt", "alpha"), os.Getuid(), os.Getgid())
+		})
+
+		method("Create", func() {
+			It("🧪 should: create empty file", func() {
+				file, err := vfs.Create(path("a.txt"))
+				Expect(err).To(Succeed())
+				Expect(file.Close()).To(Succeed())
+
+				Expect(matchers.AsFile(path("a.txt"))).To(matchers.ExistInFS(vfs))
+				Expect(read("a.txt")).To(BeEmpty())
+			})
+
+			It("🧪 should: truncate existing file", func() {
+				file, err := vfs.Create(write("a.txt", "alpha"))
+				Expect(err).To(Succeed())
+				_, err = file.WriteString("bravo!")
+				Expect(err).To(Succeed())
+				Expect(file.Close()).To(Succeed())
+
+				Expect(read("a.txt")).To(Equal("bravo!"))
+			})
+
+			It("🧪 should: fail for missing parent", func() {
+				_, err := vfs.Create(path("missing", "a.txt"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		}, func() error {
+			file, err := vfs.Create(path("a.txt"))
+			if err == nil {
+				_ = file.Close()
+			}
+
+			return err
+		})
+
+		method("CreateTemp", func() {
+			It("🧪 should: create unique files in directory", func() {
+				first, err := vfs.CreateTemp(root, "temp-*.txt")
+				Expect(err).To(Succeed())
+				Expect(first.Close()).To(Succeed())
+
+				second, err := vfs.CreateTemp(root, "temp-*.txt")
+				Expect(err).To(Succeed())
+				Expect(second.Close()).To(Succeed())
+
+				Expect(first.Name()).NotTo(Equal(second.Name()))
+				Expect(filepath.Dir(first.Name())).To(Equal(root))
+				Expect(filepath.Base(first.Name())).To(HavePrefix("temp-"))
+				Expect(filepath.Base(first.Name())).To(HaveSuffix(".txt"))
+				Expect(matchers.AsFile(first.Name())).To(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: fail for missing directory", func() {
+				_, err := vfs.CreateTemp(path("missing"), "temp")
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		}, func() error {
+			file, err := vfs.CreateTemp(root, "temp")
+			if err == nil {
+				_ = file.Close()
+			}
+
+			return err
+		})
+
+		method("Link", func() {
+			It("🧪 should: create hard link", func() {
+				requireLinks()
+				Expect(vfs.Link(write("a.txt", "alpha"), path("b.txt"))).To(Succeed())
+				Expect(read("b.txt")).To(Equal("alpha"))
+			})
+
+			It("🧪 should: fail for existing target", func() {
+				requireLinks()
+				Expect(vfs.Link(write("a.txt", "alpha"), write("b.txt", "bravo"))).To(MatchError(fs.ErrExist))
+				Expect(read("b.txt")).To(Equal("bravo"))
+			})
+
+			It("🧪 should: fail for missing source", func() {
+				requireLinks()
+				Expect(vfs.Link(path("missing.txt"), path("b.txt"))).To(MatchError(fs.ErrNotExist))
+			})
+		}, func() error {
+			return vfs.Link(write("a.txt", "alpha"), path("b.txt"))
+		})
+
+		Describe("Mkdir", func() {
+			It("🧪 should: create directory with permissions", func() {
+				Expect(vfs.Mkdir(path("dir"), dirPerm)).To(Succeed())
+
+				info, err := vfs.Lstat(path("dir"))
+				Expect(err).To(Succeed())
+				Expect(info.IsDir()).To(BeTrue())
+				Expect(info.Mode().Perm()).To(Equal(dirPerm))
+			})
+
+			It("🧪 should: fail for existing directory", func() {
+				Expect(vfs.Mkdir(directory("dir"), dirPerm)).To(MatchError(fs.ErrExist))
+			})
+
+			It("🧪 should: fail for existing file", func() {
+				Expect(vfs.Mkdir(write("a.txt", "alpha"), dirPerm)).To(MatchError(fs.ErrExist))
+			})
+
+			It("🧪 should: fail for missing parent", func() {
+				Expect(vfs.Mkdir(path("missing", "dir"), dirPerm)).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("MkdirAll", func() {
+			It("🧪 should: create all segments", func() {
+				Expect(vfs.MkdirAll(path("a", "b", "c"), dirPerm)).To(Succeed())
+				Expect(matchers.AsDirectory(path("a", "b", "c"))).To(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: succeed for existing directory", func() {
+				Expect(vfs.MkdirAll(directory("dir"), dirPerm)).To(Succeed())
+			})
+
+			It("🧪 should: fail for existing file", func() {
+				Expect(vfs.MkdirAll(write("a.txt", "alpha"), dirPerm)).To(HaveOccurred())
+			})
+
+			It("🧪 should: fail for file in path", func() {
+				write("a.txt", "alpha")
+				Expect(vfs.MkdirAll(path("a.txt", "dir"), dirPerm)).To(HaveOccurred())
+			})
+		})
+
+		method("MkdirTemp", func() {
+			It("🧪 should: create unique directories", func() {
+				first, err := vfs.MkdirTemp(root, "temp-*-dir")
+				Expect(err).To(Succeed())
+
+				second, err := vfs.MkdirTemp(root, "temp-*-dir")
+				Expect(err).To(Succeed())
+
+				Expect(first).NotTo(Equal(second))
+				Expect(filepath.Dir(first)).To(Equal(root))
+				Expect(filepath.Base(first)).To(HavePrefix("temp-"))
+				Expect(filepath.Base(first)).To(HaveSuffix("-dir"))
+				Expect(matchers.AsDirectory(first)).To(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: fail for missing directory", func() {
+				_, err := vfs.MkdirTemp(path("missing"), "temp")
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		}, func() error {
+			_, err := vfs.MkdirTemp(root, "temp")
+
+			return err
+		})
+
+		method("OpenFile", func() {
+			It("🧪 should: open for read only", func() {
+				file, err := vfs.OpenFile(write("a.txt", "alpha"), os.O_RDONLY, 0)
+				Expect(err).To(Succeed())
+
+				defer file.Close()
+
+				data, err := io.ReadAll(file)
+				Expect(err).To(Succeed())
+				Expect(string(data)).To(Equal("alpha"))
+			})
+
+			It("🧪 should: append", func() {
+				file, err := vfs.OpenFile(write("a.txt", "alpha"), os.O_WRONLY|os.O_APPEND, 0)
+				Expect(err).To(Succeed())
+				_, err = file.WriteString("-bravo")
+				Expect(err).To(Succeed())
+				Expect(file.Close()).To(Succeed())
+
+				Expect(read("a.txt")).To(Equal("alpha-bravo"))
+			})
+
+			It("🧪 should: write at offset", func() {
+				file, err := vfs.OpenFile(write("a.txt", "alpha"), os.O_RDWR, 0)
+				Expect(err).To(Succeed())
+				_, err = file.WriteAt([]byte("PH"), 2)
+				Expect(err).To(Succeed())
+				Expect(file.Close()).To(Succeed())
+
+				Expect(read("a.txt")).To(Equal("alPHa"))
+			})
+
+			It("🧪 should: truncate", func() {
+				file, err := vfs.OpenFile(write("a.txt", "alpha"), os.O_WRONLY|os.O_TRUNC, 0)
+				Expect(err).To(Succeed())
+				Expect(file.Close()).To(Succeed())
+
+				Expect(read("a.txt")).To(BeEmpty())
+			})
+
+			It("🧪 should: create with permissions", func() {
+				file, err := vfs.OpenFile(path("a.txt"), os.O_WRONLY|os.O_CREATE, filePerm)
+				Expect(err).To(Succeed())
+				Expect(file.Close()).To(Succeed())
+
+				info, err := vfs.Lstat(path("a.txt"))
+				Expect(err).To(Succeed())
+				Expect(info.Mode().Perm()).To(Equal(filePerm))
+			})
+
+			It("🧪 should: fail exclusive create for existing file", func() {
+				_, err := vfs.OpenFile(write("a.txt", "alpha"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, filePerm)
+				Expect(err).To(MatchError(fs.ErrExist))
+				Expect(read("a.txt")).To(Equal("alpha"))
+			})
+
+			It("🧪 should: fail without create for missing file", func() {
+				_, err := vfs.OpenFile(path("missing.txt"), os.O_WRONLY, filePerm)
+				Expect(err).To(MatchError(fs.ErrNotExist))
+			})
+		}, func() error {
+			file, err := vfs.OpenFile(path("a.txt"), os.O_WRONLY|os.O_CREATE, filePerm)
+			if err == nil {
+				_ = file.Close()
+			}
+
+			return err
+		})
+
+		Describe("Remove", func() {
+			It("🧪 should: remove file", func() {
+				target := write("a.txt", "alpha")
+				Expect(vfs.Remove(target)).To(Succeed())
+				Expect(matchers.AsFile(target)).NotTo(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: remove empty directory", func() {
+				target := directory("dir")
+				Expect(vfs.Remove(target)).To(Succeed())
+				Expect(matchers.AsDirectory(target)).NotTo(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: fail for non empty directory", func() {
+				write(filepath.Join("dir", "a.txt"), "alpha")
+				Expect(vfs.Remove(path("dir"))).To(HaveOccurred())
+				Expect(read(filepath.Join("dir", "a.txt"))).To(Equal("alpha"))
+			})
+
+			It("🧪 should: remove symlink, not its target", func() {
+				requireSymlinks()
+				target := write("a.txt", "alpha")
+				Expect(vfs.Symlink(target, path("link"))).To(Succeed())
+				Expect(vfs.Remove(path("link"))).To(Succeed())
+
+				_, err := vfs.Lstat(path("link"))
+				Expect(err).To(MatchError(fs.ErrNotExist))
+				Expect(read("a.txt")).To(Equal("alpha"))
+			})
+
+			It("🧪 should: fail for missing path", func() {
+				Expect(vfs.Remove(path("missing.txt"))).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		Describe("RemoveAll", func() {
+			It("🧪 should: remove tree", func() {
+				write(filepath.Join("dir", "sub", "a.txt"), "alpha")
+				Expect(vfs.RemoveAll(path("dir"))).To(Succeed())
+				Expect(matchers.AsDirectory(path("dir"))).NotTo(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: remove file", func() {
+				target := write("a.txt", "alpha")
+				Expect(vfs.RemoveAll(target)).To(Succeed())
+				Expect(matchers.AsFile(target)).NotTo(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: succeed for missing path", func() {
+				Expect(vfs.RemoveAll(path("missing"))).To(Succeed())
+			})
+		})
+
+		Describe("Rename", func() {
+			It("🧪 should: move file", func() {
+				source := write("a.txt", "alpha")
+				directory("dir")
+				Expect(vfs.Rename(source, path("dir", "b.txt"))).To(Succeed())
+
+				Expect(matchers.AsFile(source)).NotTo(matchers.ExistInFS(vfs))
+				Expect(read(filepath.Join("dir", "b.txt"))).To(Equal("alpha"))
+			})
+
+			It("🧪 should: replace existing file", func() {
+				source := write("a.txt", "alpha")
+				Expect(vfs.Rename(source, write("b.txt", "bravo"))).To(Succeed())
+
+				Expect(matchers.AsFile(source)).NotTo(matchers.ExistInFS(vfs))
+				Expect(read("b.txt")).To(Equal("alpha"))
+			})
+
+			It("🧪 should: move directory with content", func() {
+				write(filepath.Join("dir", "sub", "a.txt"), "alpha")
+				Expect(vfs.Rename(path("dir"), path("moved"))).To(Succeed())
+
+				Expect(matchers.AsDirectory(path("dir"))).NotTo(matchers.ExistInFS(vfs))
+				Expect(read(filepath.Join("moved", "sub", "a.txt"))).To(Equal("alpha"))
+			})
+
+			It("🧪 should: fail for missing source", func() {
+				Expect(vfs.Rename(path("missing.txt"), path("b.txt"))).To(MatchError(fs.ErrNotExist))
+			})
+		})
+
+		method("Symlink", func() {
+			It("🧪 should: fail for existing target", func() {
+				requireSymlinks()
+				Expect(vfs.Symlink(write("a.txt", "alpha"), write("b.txt", "bravo"))).To(MatchError(fs.ErrExist))
+				Expect(read("b.txt")).To(Equal("bravo"))
+			})
+
+			It("🧪 should: create dangling symlink", func() {
+				requireSymlinks()
+				Expect(vfs.Symlink(path("missing.txt"), path("link"))).To(Succeed())
+
+				_, err := vfs.Lstat(path("link"))
+				Expect(err).To(Succeed())
+			})
+		}, func() error {
+			return vfs.Symlink(write("a.txt", "alpha"), path("link"))
+		})
+
+		Describe("WriteFile", func() {
+			It("🧪 should: create file with permissions", func() {
+				Expect(vfs.WriteFile(path("a.txt"), []byte("alpha"), filePerm)).To(Succeed())
+
+				info, err := vfs.Lstat(path("a.txt"))
+				Expect(err).To(Succeed())
+				Expect(info.Mode().Perm()).To(Equal(filePerm))
+				Expect(read("a.txt")).To(Equal("alpha"))
+			})
+
+			It("🧪 should: overwrite existing file", func() {
+				write("a.txt", "a much longer alpha")
+				Expect(vfs.WriteFile(path("a.txt"), []byte("bravo"), filePerm)).To(Succeed())
+				Expect(read("a.txt")).To(Equal("bravo"))
+			})
+
+			It("🧪 should: fail for directory", func() {
+				Expect(vfs.WriteFile(directory("dir"), []byte("alpha"), filePerm)).To(HaveOccurred())
+				Expect(matchers.AsDirectory(path("dir"))).To(matchers.ExistInFS(vfs))
+			})
+
+			It("🧪 should: fail for missing parent", func() {
+				Expect(vfs.WriteFile(path("missing", "a.txt"), []byte("alpha"), filePerm)).To(
+					MatchError(fs.ErrNotExist),
+				)
+			})
+		})
+
+		// end: interface WriteToFS
+
+		Describe("path edge cases", func() {
+			It("🧪 should: resolve dot segments", func() {
+				write(filepath.Join("dir", "a.txt"), "alpha")
+				dotted := root + sep("dir", ".", "..", "dir", "a.txt")
+
+				data, err := vfs.ReadFile(dotted)
+				Expect(err).To(Succeed())
+				Expect(string(data)).To(Equal("alpha"))
+			})
+
+			It("🧪 should: accept trailing separator for directory", func() {
+				directory("dir")
+				Expect(matchers.AsDirectory(path("dir") + string(filepath.Separator))).To(matchers.ExistInFS(vfs))
+
+				info, err := vfs.Stat(path("dir") + string(filepath.Separator))
+				Expect(err).To(Succeed())
+				Expect(info.IsDir()).To(BeTrue())
+			})
+
+			It("🧪 should: round trip names with spaces and unicode", func() {
+				for _, name := range []string{"with space.txt", "ünïcödé.txt", "日本語.txt", "🎶.txt"} {
+					write(name, name)
+					Expect(read(name)).To(Equal(name))
+				}
+
+				entries, err := vfs.ReadDir(root)
+				Expect(err).To(Succeed())
+				Expect(entries).To(HaveLen(4))
+			})
+
+			It("🧪 should: distinguish names by case", func() {
+				write("case.txt", "lower")
+				write("CASE.txt", "UPPER CASE")
+
+				if read("case.txt") != "lower" {
+					Skip("case insensitive file system")
+				}
+
+				Expect(read("CASE.txt")).To(Equal("UPPER CASE"))
+			})
+
+			It("🧪 should: fail for empty path", func() {
+				_, err := vfs.Stat("")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+}
+
+// sep returns the segments joined as a path relative to the root, with a
+// leading separator.
+func sep(segments ...string) string {
+	return string(filepath.Separator) + strings.Join(segments, string(filepath.Separator))
+}
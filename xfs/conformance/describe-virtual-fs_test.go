@@ -0,0 +1,56 @@
+package conformance_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/xfs/conformance"
+	"github.com/snivilised/extendio/xfs/jail"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+var _ = conformance.DescribeVirtualFS("native", func() storage.VirtualFS {
+	return storage.UseNativeFS()
+}, nil)
+
+var _ = conformance.DescribeVirtualFS("mem", func() storage.VirtualFS {
+	return storage.UseMemFS()
+}, nil)
+
+var _ = conformance.DescribeVirtualFS("overlay", func() storage.VirtualFS {
+	return storage.UseOverlayFS(storage.UseNativeFS())
+}, nil)
+
+var _ = conformance.DescribeVirtualFS("recording", func() storage.VirtualFS {
+	return storage.UseRecordingFS(storage.UseNativeFS())
+}, &conformance.Options{
+	Unsupported: []string{"Create", "CreateTemp", "OpenFile"},
+})
+
+var _ = conformance.DescribeVirtualFS("transaction", func() storage.VirtualFS {
+	tx, err := storage.Begin(storage.UseNativeFS(), &storage.TransactionOptions{
+		Journal: filepath.Join(GinkgoT().TempDir(), "journal.json"),
+	})
+	Expect(err).To(Succeed())
+
+	return tx
+}, &conformance.Options{
+	Unsupported: []string{"Chown"},
+})
+
+var _ = conformance.DescribeVirtualFS("faulty", func() storage.VirtualFS {
+	vfs, err := storage.UseFaultyFS(storage.UseNativeFS())
+	Expect(err).To(Succeed())
+
+	return vfs
+}, nil)
+
+var _ = conformance.DescribeVirtualFS("jail", func() storage.VirtualFS {
+	vfs, err := jail.New(storage.UseNativeFS(), os.TempDir(), nil)
+	Expect(err).To(Succeed())
+
+	return vfs
+}, nil)
@@ -0,0 +1,124 @@
+package dedupe
+
+import (
+	"time"
+
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	// DefaultPartialSize is the number of bytes read from the start of each
+	// candidate file, when computing the partial hash.
+	DefaultPartialSize = 4 * 1024
+
+	// DefaultJobsChSize is the size of the buffer of the channel that feeds
+	// hashing jobs to the worker pool.
+	DefaultJobsChSize = 16
+
+	// DefaultOutputChTimeout is the time a hashing worker waits to send its
+	// output before giving up.
+	DefaultOutputChTimeout = time.Second * 10
+)
+
+// FindParams defines the parameters required to find duplicate files.
+type FindParams struct {
+	// Path is the root of the tree to search for duplicates
+	//
+	Path string
+
+	// OptionsFn allows the client to customise the traversal, eg to define
+	// filters (Options.Store.FilterDefs) or sampling (Options.Store.Sampling), which
+	// restrict which files are considered. The Subscription and Callback are
+	// always overridden, since only files are of interest to the finder.
+	//
+	OptionsFn nav.TraverseOptionFn
+
+	// NoW is the number of workers in the pool used to hash candidate files,
+	// the same as the NoW acceleration operator on the navigation runner. When
+	// not set, defaults to the number of CPUs.
+	//
+	NoW int
+
+	// PartialSize is the number of bytes hashed from the start of each
+	// candidate to form its partial hash (defaults to DefaultPartialSize).
+	//
+	PartialSize int64
+
+	// IncludeEmpty, when set, causes empty files to be reported as duplicates
+	// of each other. By default they are ignored, as they do not occupy any
+	// reclaimable space.
+	//
+	IncludeEmpty bool
+
+	// FS is the file system the tree is read from. When not set, the native
+	// file system is used.
+	//
+	FS storage.ReadOnlyVirtualFS
+}
+
+// Group represents a set of files with identical content.
+type Group struct {
+	// Size is the size in bytes of each of the files in the group
+	//
+	Size int64
+
+	// Hash is the hex encoded content hash of the files in the group
+	//
+	Hash string
+
+	// Paths are the paths of the duplicates, in sorted order
+	//
+	Paths []string
+}
+
+// Reclaimable returns the no of bytes that would be freed if all but one
+// of the files in the group were removed.
+func (g *Group) Reclaimable() int64 {
+	return g.Size * int64(len(g.Paths)-1)
+}
+
+// Result is the outcome of finding duplicates.
+type Result struct {
+	// Groups contains the groups of duplicates found, ordered by the amount of
+	// space that can be reclaimed (descending).
+	//
+	Groups []*Group
+
+	// Reclaimable is the total number of bytes that would be freed if all
+	// duplicates were removed, leaving one file in each group.
+	//
+	Reclaimable int64
+
+	// Errors contains the errors encountered for individual paths. Paths
+	// appearing here are excluded from the results.
+	//
+	Errors map[string]error
+
+	// Traversal is the result of the underlying traversal
+	//
+	Traversal *nav.TraverseResult
+}
+
+type candidate struct {
+	path string
+	size int64
+}
+
+type hashStageEnum uint
+
+const (
+	hashStagePartialEn hashStageEnum = iota
+	hashStageFullEn
+)
+
+type hashInput struct {
+	candidate candidate
+	stage     hashStageEnum
+}
+
+type hashOutput struct {
+	candidate candidate
+	hash      string
+	err       error
+}
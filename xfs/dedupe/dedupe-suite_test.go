@@ -0,0 +1,13 @@
+package dedupe_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+)
+
+func TestDedupe(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dedupe Suite")
+}
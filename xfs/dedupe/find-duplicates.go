@@ -0,0 +1,196 @@
+package dedupe
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/nav"
+)
+
+// Find searches the tree rooted at the path specified for files with identical
+// content. To avoid reading more data than necessary, candidates are first
+// grouped by size. Only files that share a size are hashed, initially just the
+// first PartialSize bytes; then only files whose partial hashes collide are
+// hashed in full. Hashing is performed on a pool of NoW workers.
+func Find(ctx context.Context, params *FindParams) (*Result, error) {
+	if params.NoW != 0 && (params.NoW < nav.MinNoWorkers || params.NoW > nav.MaxNoWorkers) {
+		return nil, fmt.Errorf("no of workers requested (%v) is out of range ('%v' - '%v')",
+			params.NoW, nav.MinNoWorkers, nav.MaxNoWorkers,
+		)
+	}
+
+	f := &finder{
+		params: params,
+		partialSize: lo.Ternary(params.PartialSize > 0,
+			params.PartialSize, DefaultPartialSize,
+		),
+		result: &Result{
+			Errors: make(map[string]error),
+		},
+	}
+
+	return f.find(ctx)
+}
+
+type finder struct {
+	params      *FindParams
+	partialSize int64
+	result      *Result
+}
+
+func (f *finder) find(ctx context.Context) (*Result, error) {
+	bySize, err := f.survey()
+	if err != nil {
+		return f.result, err
+	}
+
+	// only files that share their size with another can be duplicates
+	//
+	partials := f.hash(ctx, flatten(bySize), hashStagePartialEn)
+	groups := []*Group{}
+	remaining := []candidate{}
+
+	for _, g := range partials {
+		// when the whole file fits inside the partial size, the partial hash
+		// is already the full hash, so there is no need to read it again.
+		//
+		if g.Size <= f.partialSize {
+			groups = append(groups, g)
+
+			continue
+		}
+
+		remaining = append(remaining, lo.Map(g.Paths, func(path string, _ int) candidate {
+			return candidate{path: path, size: g.Size}
+		})...)
+	}
+
+	groups = append(groups, f.hash(ctx, remaining, hashStageFullEn)...)
+
+	if err := ctx.Err(); err != nil {
+		return f.result, err
+	}
+
+	f.conclude(groups)
+
+	return f.result, nil
+}
+
+func (f *finder) survey() (map[int64][]candidate, error) {
+	bySize := make(map[int64][]candidate)
+
+	optionsFn := func(o *nav.TraverseOptions) {
+		if f.params.OptionsFn != nil {
+			f.params.OptionsFn(o)
+		}
+
+		o.Store.Subscription = nav.SubscribeFiles
+
+		if f.params.FS != nil {
+			o.Hooks.QueryStatus = nav.VirtualQueryStatusHookFn(f.params.FS)
+			o.Hooks.ReadDirectory = nav.VirtualReadEntriesHookFn(f.params.FS)
+		}
+
+		o.Callback = &nav.LabelledTraverseCallback{
+			Label: "dedupe survey callback",
+			Fn: func(item *nav.TraverseItem) error {
+				if item.Error != nil {
+					f.result.Errors[item.Path] = item.Error
+
+					return nil
+				}
+
+				if item.Info == nil || !item.Info.Mode().IsRegular() {
+					return nil
+				}
+
+				size := item.Info.Size()
+
+				if size == 0 && !f.params.IncludeEmpty {
+					return nil
+				}
+
+				bySize[size] = append(bySize[size], candidate{
+					path: item.Path,
+					size: size,
+				})
+
+				return nil
+			},
+		}
+	}
+
+	traversal, err := nav.New().Primary(&nav.Prime{
+		Path:      f.params.Path,
+		OptionsFn: optionsFn,
+	}).Run()
+
+	f.result.Traversal = traversal
+
+	return bySize, err
+}
+
+func flatten(bySize map[int64][]candidate) []candidate {
+	result := []candidate{}
+
+	for _, candidates := range bySize {
+		if len(candidates) > 1 {
+			result = append(result, candidates...)
+		}
+	}
+
+	return result
+}
+
+func (f *finder) hash(ctx context.Context, candidates []candidate, stage hashStageEnum) []*Group {
+	if len(candidates) == 0 {
+		return []*Group{}
+	}
+
+	outputs := f.dispatch(ctx, candidates, stage)
+	collated := make(map[string]*Group)
+
+	for _, output := range outputs {
+		if output.err != nil {
+			f.result.Errors[output.candidate.path] = output.err
+
+			continue
+		}
+
+		key := fmt.Sprintf("%v:%v", output.candidate.size, output.hash)
+
+		if g, found := collated[key]; found {
+			g.Paths = append(g.Paths, output.candidate.path)
+		} else {
+			collated[key] = &Group{
+				Size:  output.candidate.size,
+				Hash:  output.hash,
+				Paths: []string{output.candidate.path},
+			}
+		}
+	}
+
+	return lo.Filter(lo.Values(collated), func(g *Group, _ int) bool {
+		return len(g.Paths) > 1
+	})
+}
+
+func (f *finder) conclude(groups []*Group) {
+	for _, g := range groups {
+		slices.Sort(g.Paths)
+		f.result.Reclaimable += g.Reclaimable()
+	}
+
+	slices.SortFunc(groups, func(a, b *Group) int {
+		if a.Reclaimable() != b.Reclaimable() {
+			return lo.Ternary(a.Reclaimable() > b.Reclaimable(), -1, 1)
+		}
+
+		return strings.Compare(a.Paths[0], b.Paths[0])
+	})
+
+	f.result.Groups = groups
+}
@@ -0,0 +1,207 @@
+package dedupe_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/dedupe"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	bigSize = 10000
+	perm    = 0o666
+)
+
+type dedupeTE struct {
+	given               string
+	should              string
+	optionsFn           nav.TraverseOptionFn
+	includeEmpty        bool
+	useVFS              bool
+	expectedGroups      [][]string
+	expectedReclaimable int64
+}
+
+func writeTree(root string) {
+	big := bytes.Repeat([]byte("0123456789"), bigSize/10)
+	nearlyBig := bytes.Clone(big)
+	nearlyBig[bigSize-1] = 'X'
+
+	files := map[string][]byte{
+		"a/one.txt":   []byte("alpha"),
+		"a/two.txt":   []byte("alpha"),
+		"b/three.txt": []byte("alpha"),
+		"b/four.txt":  []byte("bravo"),
+		"b/note.md":   []byte("alpha"),
+		"c/big1.bin":  big,
+		"c/big2.bin":  big,
+		"c/big3.bin":  nearlyBig,
+		"d/empty-1":   {},
+		"d/empty-2":   {},
+	}
+
+	for name, data := range files {
+		path := filepath.Join(root, name)
+		Expect(os.MkdirAll(filepath.Dir(path), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(path, data, perm)).To(Succeed())
+	}
+}
+
+var _ = Describe("Find", Ordered, func() {
+	var root string
+
+	BeforeAll(func() {
+		root = GinkgoT().TempDir()
+		writeTree(root)
+
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	DescribeTable("duplicates",
+		func(ctx SpecContext, entry *dedupeTE) {
+			params := &dedupe.FindParams{
+				Path:         root,
+				OptionsFn:    entry.optionsFn,
+				NoW:          3,
+				IncludeEmpty: entry.includeEmpty,
+			}
+
+			if entry.useVFS {
+				params.FS = storage.UseNativeFS()
+			}
+
+			result, err := dedupe.Find(ctx, params)
+
+			Expect(err).To(BeNil())
+			Expect(result.Errors).To(BeEmpty())
+			Expect(result.Groups).To(HaveLen(len(entry.expectedGroups)))
+
+			for i, expected := range entry.expectedGroups {
+				paths := make([]string, 0, len(expected))
+				for _, name := range expected {
+					paths = append(paths, filepath.Join(root, name))
+				}
+
+				Expect(result.Groups[i].Paths).To(Equal(paths))
+			}
+
+			Expect(result.Reclaimable).To(Equal(entry.expectedReclaimable))
+			Expect(result.Traversal).NotTo(BeNil())
+		},
+		func(entry *dedupeTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.given, entry.should)
+		},
+
+		Entry(nil, &dedupeTE{
+			given:  "tree with duplicates",
+			should: "group by content, excluding same size files that differ",
+			expectedGroups: [][]string{
+				{"c/big1.bin", "c/big2.bin"},
+				{"a/one.txt", "a/two.txt", "b/note.md", "b/three.txt"},
+			},
+			expectedReclaimable: bigSize + 3*5,
+		}),
+
+		Entry(nil, &dedupeTE{
+			given:  "tree with duplicates read via virtual file system",
+			should: "group by content, excluding same size files that differ",
+			useVFS: true,
+			expectedGroups: [][]string{
+				{"c/big1.bin", "c/big2.bin"},
+				{"a/one.txt", "a/two.txt", "b/note.md", "b/three.txt"},
+			},
+			expectedReclaimable: bigSize + 3*5,
+		}),
+
+		Entry(nil, &dedupeTE{
+			given:        "tree with empty files",
+			should:       "also group empty files",
+			includeEmpty: true,
+			expectedGroups: [][]string{
+				{"c/big1.bin", "c/big2.bin"},
+				{"a/one.txt", "a/two.txt", "b/note.md", "b/three.txt"},
+				{"d/empty-1", "d/empty-2"},
+			},
+			expectedReclaimable: bigSize + 3*5,
+		}),
+
+		Entry(nil, &dedupeTE{
+			given:  "filter",
+			should: "only consider files that pass the filter",
+			optionsFn: func(o *nav.TraverseOptions) {
+				o.Store.FilterDefs = &nav.FilterDefinitions{
+					Node: nav.FilterDef{
+						Type:            nav.FilterTypeGlobEn,
+						Description:     "text files",
+						Pattern:         "*.txt",
+						Scope:           nav.ScopeFileEn,
+						IfNotApplicable: nav.TriStateBoolTrueEn,
+					},
+				}
+			},
+			expectedGroups: [][]string{
+				{"a/one.txt", "a/two.txt", "b/three.txt"},
+			},
+			expectedReclaimable: 2 * 5,
+		}),
+	)
+
+	When("virtual file system can only be read via open", func() {
+		It("🧪 should: hash files without reading them whole", func(ctx SpecContext) {
+			vfs, err := storage.UseFaultyFS(storage.UseNativeFS(), storage.Fault{
+				Op:  "ReadFile",
+				Err: fs.ErrPermission,
+			})
+			Expect(err).To(Succeed())
+
+			result, err := dedupe.Find(ctx, &dedupe.FindParams{
+				Path: root,
+				NoW:  3,
+				FS:   vfs,
+			})
+
+			Expect(err).To(BeNil())
+			Expect(result.Errors).To(BeEmpty())
+			Expect(result.Groups).To(HaveLen(2))
+			Expect(result.Reclaimable).To(Equal(int64(bigSize + 3*5)))
+		})
+	})
+
+	When("context is cancelled", func() {
+		It("🧪 should: return context error", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := dedupe.Find(ctx, &dedupe.FindParams{
+				Path: root,
+			})
+
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+
+	When("no of workers is out of range", func() {
+		It("🧪 should: return error", func() {
+			_, err := dedupe.Find(context.Background(), &dedupe.FindParams{
+				Path: root,
+				NoW:  nav.MaxNoWorkers + 1,
+			})
+
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
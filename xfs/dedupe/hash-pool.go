@@ -0,0 +1,161 @@
+package dedupe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/snivilised/lorax/boost"
+)
+
+const (
+	dispatcherRoutineName = boost.GoRoutineName("🧮 dedupe dispatcher")
+)
+
+// dispatch hashes all the candidates on the worker pool and returns the
+// outputs once all the workers have finished.
+func (f *finder) dispatch(ctx context.Context, candidates []candidate, stage hashStageEnum) []hashOutput {
+	// The pool is deliberately not bound to the client's context. When a pool
+	// is cancelled before it has spawned any workers, it never completes its
+	// drain. Instead, cancellation is honoured by no longer feeding the pool,
+	// which then winds down once the jobs already in flight are complete
+	// (see produce).
+	//
+	poolCtx, poolCancel := context.WithCancel(context.Background())
+	defer poolCancel()
+
+	var (
+		wgan = boost.NewAnnotatedWaitGroup("🧮 dedupe")
+		// the jobs channel is owned by the producer below and closed when all
+		// jobs have been sent; the outputs channel is owned by the pool, which
+		// closes it once all its workers have drained.
+		//
+		jobsCh   = make(boost.JobStream[hashInput], DefaultJobsChSize)
+		outputCh = make(boost.JobOutputStream[hashOutput], len(candidates))
+		outputs  = make([]hashOutput, 0, len(candidates))
+	)
+
+	pool := boost.NewWorkerPool[hashInput, hashOutput](
+		&boost.NewWorkerPoolParams[hashInput, hashOutput]{
+			NoWorkers:       f.params.NoW,
+			OutputChTimeout: DefaultOutputChTimeout,
+			Exec: func(job boost.Job[hashInput]) (boost.JobOutput[hashOutput], error) {
+				return f.executive(ctx, job)
+			},
+			JobsCh: jobsCh,
+			WaitAQ: wgan,
+		})
+
+	wgan.Add(1, pool.RoutineName)
+
+	go pool.Start(poolCtx, poolCancel, outputCh)
+	go produce(ctx, jobsCh, candidates, stage)
+
+	for output := range outputCh {
+		outputs = append(outputs, output.Payload)
+	}
+
+	wgan.Wait(dispatcherRoutineName)
+
+	return outputs
+}
+
+func produce(ctx context.Context,
+	jobsChOut chan<- boost.Job[hashInput],
+	candidates []candidate,
+	stage hashStageEnum,
+) {
+	defer close(jobsChOut)
+
+	for i, c := range candidates {
+		job := boost.Job[hashInput]{
+			ID: fmt.Sprintf("JOB-ID:%v", uuid.NewString()),
+			Input: hashInput{
+				candidate: c,
+				stage:     stage,
+			},
+			SequenceNo: i,
+		}
+
+		// The first job is always sent (the channel is buffered, so this can't
+		// block), because a pool that never receives a job, never spawns a worker
+		// and therefore never completes. The executive will not hash anything
+		// once the context has been cancelled.
+		//
+		if i == 0 {
+			jobsChOut <- job
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case jobsChOut <- job:
+		}
+	}
+}
+
+func (f *finder) executive(ctx context.Context,
+	job boost.Job[hashInput],
+) (boost.JobOutput[hashOutput], error) {
+	var (
+		digest string
+		err    = ctx.Err()
+	)
+
+	if err == nil {
+		digest, err = f.digest(job.Input.candidate, job.Input.stage)
+	}
+
+	return boost.JobOutput[hashOutput]{
+		Payload: hashOutput{
+			candidate: job.Input.candidate,
+			hash:      digest,
+			err:       err,
+		},
+	}, err
+}
+
+func (f *finder) digest(c candidate, stage hashStageEnum) (string, error) {
+	h := sha256.New()
+
+	if err := f.read(h, c.path, stage); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (f *finder) read(h hash.Hash, path string, stage hashStageEnum) error {
+	file, err := f.open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+
+	if stage == hashStagePartialEn {
+		reader = io.LimitReader(file, f.partialSize)
+	}
+
+	_, err = io.Copy(h, reader)
+
+	return err
+}
+
+// open opens the file for reading, so that the partial hash only needs to
+// read the prefix, whichever file system the tree is read from.
+func (f *finder) open(path string) (io.ReadCloser, error) {
+	if f.params.FS != nil {
+		return f.params.FS.Open(path)
+	}
+
+	return os.Open(path)
+}
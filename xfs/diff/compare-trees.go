@@ -0,0 +1,497 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/nav"
+)
+
+// ErrUnsupportedFilter is returned by Compare when the options define a
+// children (compound) filter, which can't be applied to a pair of trees.
+var ErrUnsupportedFilter = errors.New("diff: children filter is not supported, only node filters are")
+
+// Compare traverses the trees rooted at Left and Right in lockstep and reports
+// the entries that have been added, removed, changed type or been modified.
+// The entries of each pair of directories are merged and sorted with the same
+// Sort hook used by the navigator, so that the differences are reported in a
+// deterministic order. When a directory is present in only one of the trees,
+// it and each of its descendants are reported individually. Directories
+// present in both trees are not themselves reported, only their contents.
+//
+// Errors encountered reading individual paths do not end the comparison;
+// they are only reported via the Errors of the result and the entries
+// concerned are excluded. The error returned is reserved for failures of the
+// comparison as a whole, eg an invalid root, an unsupported filter or
+// cancellation of the context.
+func Compare(ctx context.Context, params *CompareParams) (*Result, error) {
+	if params.Records != nil {
+		defer close(params.Records)
+	}
+
+	o := nav.GetDefaultOptions()
+
+	if params.OptionsFn != nil {
+		params.OptionsFn(o)
+	}
+
+	if o.Store.FilterDefs != nil && isCompoundFilteringActive(&o.Store.FilterDefs.Children) {
+		return &Result{
+			Errors: make(map[string]error),
+		}, ErrUnsupportedFilter
+	}
+
+	if params.FS != nil {
+		o.Hooks.QueryStatus = nav.VirtualQueryStatusHookFn(params.FS)
+		o.Hooks.ReadDirectory = nav.VirtualReadEntriesHookFn(params.FS)
+	}
+
+	if o.Hooks.Sort == nil {
+		o.Hooks.Sort = lo.Ternary(o.Store.Behaviours.Sort.IsCaseSensitive,
+			nav.CaseSensitiveSortHookFn, nav.CaseInSensitiveSortHookFn,
+		)
+	}
+
+	c := &comparer{
+		params: params,
+		o:      o,
+		result: &Result{
+			Errors: make(map[string]error),
+		},
+	}
+
+	if isFilteringActive(o) {
+		c.filter = nav.NewNodeFilter(&o.Store.FilterDefs.Node)
+	}
+
+	left, err := c.root(params.Left)
+	if err != nil {
+		return c.result, err
+	}
+
+	if _, err := c.root(params.Right); err != nil {
+		return c.result, err
+	}
+
+	return c.result, c.compare(ctx, "", left)
+}
+
+func isFilteringActive(o *nav.TraverseOptions) bool {
+	if o.Store.FilterDefs != nil {
+		node := &o.Store.FilterDefs.Node
+
+		return node.Pattern != "" || node.Custom != nil || node.Poly != nil
+	}
+
+	return false
+}
+
+func isCompoundFilteringActive(def *nav.CompoundFilterDef) bool {
+	return def.Pattern != "" || def.Custom != nil
+}
+
+type comparer struct {
+	params *CompareParams
+	o      *nav.TraverseOptions
+	filter nav.TraverseFilter
+	result *Result
+}
+
+func (c *comparer) root(path string) (*nav.TraverseItem, error) {
+	info, err := c.o.Hooks.QueryStatus(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("diff root '%v' is not a directory", path)
+	}
+
+	item := nav.NewTraverseItem(path, nil, info, nil)
+	item.Extension = nav.ExtendedItem{
+		Name:      filepath.Base(path),
+		NodeScope: nav.ScopeRootEn | nav.ScopeFolderEn,
+	}
+
+	return item, nil
+}
+
+// compare compares the contents of the directory at the sub-path, which is
+// present in both trees.
+func (c *comparer) compare(ctx context.Context, subPath string, parent *nav.TraverseItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	left, err := c.read(filepath.Join(c.params.Left, subPath))
+	if err != nil {
+		return nil
+	}
+
+	right, err := c.read(filepath.Join(c.params.Right, subPath))
+	if err != nil {
+		return nil
+	}
+
+	merged := lo.Values(right)
+
+	for name, entry := range left {
+		if _, found := right[name]; !found {
+			merged = append(merged, entry)
+		}
+	}
+
+	if err := c.o.Hooks.Sort(merged); err != nil {
+		return err
+	}
+
+	for _, entry := range merged {
+		name := entry.Name()
+		_, inLeft := left[name]
+		_, inRight := right[name]
+		entrySubPath := filepath.Join(subPath, name)
+
+		switch {
+		case !inRight:
+			err = c.only(ctx, KindRemovedEn, c.params.Left, entrySubPath, parent)
+		case !inLeft:
+			err = c.only(ctx, KindAddedEn, c.params.Right, entrySubPath, parent)
+		default:
+			err = c.both(ctx, entrySubPath, parent)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// both compares the entry at the sub-path, which is present in both trees.
+func (c *comparer) both(ctx context.Context, subPath string, parent *nav.TraverseItem) error {
+	leftPath := filepath.Join(c.params.Left, subPath)
+	rightPath := filepath.Join(c.params.Right, subPath)
+
+	leftInfo, err := c.status(leftPath)
+	if err != nil {
+		return nil
+	}
+
+	rightInfo, err := c.status(rightPath)
+	if err != nil {
+		return nil
+	}
+
+	if leftInfo.Mode().Type() != rightInfo.Mode().Type() {
+		return c.changed(ctx, subPath, leftInfo, rightInfo, parent)
+	}
+
+	if leftInfo.IsDir() {
+		item := c.item(leftPath, subPath, leftInfo, parent, c.isLeaf(leftPath, rightPath))
+
+		return c.compare(ctx, subPath, item)
+	}
+
+	if !c.admit(c.item(leftPath, subPath, leftInfo, parent, true)) {
+		return nil
+	}
+
+	modified, err := c.modified(leftPath, rightPath, leftInfo, rightInfo)
+	if err != nil {
+		return nil
+	}
+
+	if !modified {
+		c.result.Summary.Unchanged++
+
+		return nil
+	}
+
+	return c.emit(ctx, &Record{
+		Kind:    KindModifiedEn,
+		SubPath: subPath,
+		Left:    leftInfo,
+		Right:   rightInfo,
+	})
+}
+
+// changed reports an entry whose type is different in each tree. When either
+// of them is a directory, its contents are reported as removed or added.
+func (c *comparer) changed(ctx context.Context,
+	subPath string,
+	leftInfo, rightInfo fs.FileInfo,
+	parent *nav.TraverseItem,
+) error {
+	leftPath := filepath.Join(c.params.Left, subPath)
+	rightPath := filepath.Join(c.params.Right, subPath)
+	leftItem := c.item(leftPath, subPath, leftInfo, parent, c.isLeaf(leftPath))
+	rightItem := c.item(rightPath, subPath, rightInfo, parent, c.isLeaf(rightPath))
+
+	if c.admit(leftItem) || c.admit(rightItem) {
+		if err := c.emit(ctx, &Record{
+			Kind:    KindTypeChangedEn,
+			SubPath: subPath,
+			Left:    leftInfo,
+			Right:   rightInfo,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if leftInfo.IsDir() {
+		if err := c.descend(ctx, KindRemovedEn, c.params.Left, subPath, leftItem); err != nil {
+			return err
+		}
+	}
+
+	if rightInfo.IsDir() {
+		return c.descend(ctx, KindAddedEn, c.params.Right, subPath, rightItem)
+	}
+
+	return nil
+}
+
+// only reports the entry at the sub-path, which is present in just the tree
+// at the root specified, along with all of its descendants.
+func (c *comparer) only(ctx context.Context,
+	kind KindEnum,
+	root, subPath string,
+	parent *nav.TraverseItem,
+) error {
+	path := filepath.Join(root, subPath)
+
+	info, err := c.status(path)
+	if err != nil {
+		return nil
+	}
+
+	item := c.item(path, subPath, info, parent, !info.IsDir() || c.isLeaf(path))
+
+	if c.admit(item) {
+		record := &Record{
+			Kind:    kind,
+			SubPath: subPath,
+		}
+
+		if kind == KindRemovedEn {
+			record.Left = info
+		} else {
+			record.Right = info
+		}
+
+		if err := c.emit(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	if info.IsDir() {
+		return c.descend(ctx, kind, root, subPath, item)
+	}
+
+	return nil
+}
+
+func (c *comparer) descend(ctx context.Context,
+	kind KindEnum,
+	root, subPath string,
+	parent *nav.TraverseItem,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	contents, err := c.read(filepath.Join(root, subPath))
+	if err != nil {
+		return nil
+	}
+
+	entries := lo.Values(contents)
+
+	if err := c.o.Hooks.Sort(entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := c.only(ctx, kind, root, filepath.Join(subPath, entry.Name()), parent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *comparer) emit(ctx context.Context, record *Record) error {
+	switch record.Kind {
+	case KindAddedEn:
+		c.result.Summary.Added++
+	case KindRemovedEn:
+		c.result.Summary.Removed++
+	case KindTypeChangedEn:
+		c.result.Summary.TypeChanged++
+	case KindModifiedEn:
+		c.result.Summary.Modified++
+	case KindUndefinedEn:
+	}
+
+	if c.params.Records == nil {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c.params.Records <- record:
+	}
+
+	return nil
+}
+
+// item creates the traverse item that the filter is applied to. The scope is
+// derived in the same way as the navigator's default extend hook.
+func (c *comparer) item(path, subPath string,
+	info fs.FileInfo,
+	parent *nav.TraverseItem,
+	isLeaf bool,
+) *nav.TraverseItem {
+	var (
+		scope nav.FilterScopeBiEnum
+		depth = parent.Extension.Depth + 1
+	)
+
+	if info.IsDir() {
+		scope = lo.Ternary(depth == 1, nav.ScopeTopEn, nav.ScopeIntermediateEn)
+
+		if isLeaf {
+			scope = lo.Ternary(depth == 1, nav.ScopeTopEn|nav.ScopeLeafEn, nav.ScopeLeafEn)
+		}
+
+		scope |= nav.ScopeFolderEn
+	} else {
+		scope = nav.ScopeLeafEn | nav.ScopeFileEn
+	}
+
+	item := nav.NewTraverseItem(path, nil, info, parent)
+	item.Extension = nav.ExtendedItem{
+		Depth:     depth,
+		IsLeaf:    isLeaf,
+		Name:      filepath.Base(path),
+		Parent:    filepath.Dir(path),
+		SubPath:   subPath,
+		NodeScope: scope,
+	}
+
+	return item
+}
+
+func (c *comparer) admit(item *nav.TraverseItem) bool {
+	return c.filter == nil || c.filter.IsMatch(item)
+}
+
+// isLeaf determines whether the directories at the paths specified contain
+// no sub directories.
+func (c *comparer) isLeaf(paths ...string) bool {
+	for _, path := range paths {
+		contents, err := c.o.Hooks.ReadDirectory(path)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range contents {
+			if entry.IsDir() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (c *comparer) read(path string) (map[string]fs.DirEntry, error) {
+	contents, err := c.o.Hooks.ReadDirectory(path)
+	if err != nil {
+		c.result.Errors[path] = err
+
+		return nil, err
+	}
+
+	result := make(map[string]fs.DirEntry, len(contents))
+
+	for _, entry := range contents {
+		result[entry.Name()] = entry
+	}
+
+	return result, nil
+}
+
+func (c *comparer) status(path string) (fs.FileInfo, error) {
+	info, err := c.o.Hooks.QueryStatus(path)
+	if err != nil {
+		c.result.Errors[path] = err
+	}
+
+	return info, err
+}
+
+func (c *comparer) modified(leftPath, rightPath string, leftInfo, rightInfo fs.FileInfo) (bool, error) {
+	if leftInfo.Size() != rightInfo.Size() {
+		return true, nil
+	}
+
+	if c.params.Method != MethodHashEn || !leftInfo.Mode().IsRegular() {
+		return !leftInfo.ModTime().Equal(rightInfo.ModTime()), nil
+	}
+
+	leftDigest, err := c.digest(leftPath)
+	if err != nil {
+		return false, err
+	}
+
+	rightDigest, err := c.digest(rightPath)
+	if err != nil {
+		return false, err
+	}
+
+	return !bytes.Equal(leftDigest, rightDigest), nil
+}
+
+func (c *comparer) digest(path string) ([]byte, error) {
+	h := sha256.New()
+
+	if c.params.FS != nil {
+		data, err := c.params.FS.ReadFile(path)
+		if err != nil {
+			c.result.Errors[path] = err
+
+			return nil, err
+		}
+
+		_, _ = h.Write(data)
+
+		return h.Sum(nil), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		c.result.Errors[path] = err
+
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(h, file); err != nil {
+		c.result.Errors[path] = err
+
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,311 @@
+package diff_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/diff"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	perm = 0o666
+)
+
+type diffTE struct {
+	given           string
+	should          string
+	optionsFn       nav.TraverseOptionFn
+	method          diff.MethodEnum
+	useVFS          bool
+	expectedRecords []string
+	expectedSummary diff.Summary
+}
+
+var epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func writeFiles(root string, files map[string]string, dirs ...string) {
+	for _, dir := range dirs {
+		Expect(os.MkdirAll(filepath.Join(root, dir), os.ModePerm)).To(Succeed())
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		Expect(os.MkdirAll(filepath.Dir(path), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), perm)).To(Succeed())
+		Expect(os.Chtimes(path, epoch, epoch)).To(Succeed())
+	}
+}
+
+func format(record *diff.Record) string {
+	kind := map[diff.KindEnum]string{
+		diff.KindAddedEn:       "+",
+		diff.KindRemovedEn:     "-",
+		diff.KindTypeChangedEn: "~",
+		diff.KindModifiedEn:    "*",
+	}[record.Kind]
+
+	return fmt.Sprintf("%v %v", kind, filepath.ToSlash(record.SubPath))
+}
+
+var _ = Describe("Compare", Ordered, func() {
+	var left, right string
+
+	BeforeAll(func() {
+		left = filepath.Join(GinkgoT().TempDir(), "left")
+		right = filepath.Join(GinkgoT().TempDir(), "right")
+
+		writeFiles(left, map[string]string{
+			"a/content.txt": "abc",
+			"a/same.txt":    "x",
+			"a/size.txt":    "x",
+			"a/touched.txt": "same",
+			"b/gone.txt":    "gone",
+			"c/inner.txt":   "inner",
+			"e.md":          "e",
+		})
+
+		writeFiles(right, map[string]string{
+			"a/content.txt": "abd",
+			"a/same.txt":    "x",
+			"a/size.txt":    "xy",
+			"a/touched.txt": "same",
+			"c":             "now a file",
+			"d/new.md":      "new",
+		}, "b")
+
+		touched := epoch.Add(time.Hour)
+		Expect(os.Chtimes(filepath.Join(right, "a", "touched.txt"), touched, touched)).To(Succeed())
+
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	DescribeTable("differences",
+		func(ctx SpecContext, entry *diffTE) {
+			records := make(chan *diff.Record)
+			collected := make(chan []string)
+
+			go func() {
+				result := []string{}
+				for record := range records {
+					result = append(result, format(record))
+				}
+				collected <- result
+			}()
+
+			params := &diff.CompareParams{
+				Left:      left,
+				Right:     right,
+				OptionsFn: entry.optionsFn,
+				Method:    entry.method,
+				Records:   records,
+			}
+
+			if entry.useVFS {
+				params.FS = storage.UseNativeFS()
+			}
+
+			result, err := diff.Compare(ctx, params)
+
+			Expect(err).To(BeNil())
+			Expect(result.Errors).To(BeEmpty())
+			Expect(<-collected).To(Equal(entry.expectedRecords))
+			Expect(result.Summary).To(Equal(entry.expectedSummary))
+		},
+		func(entry *diffTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.given, entry.should)
+		},
+
+		Entry(nil, &diffTE{
+			given:  "size and mod time comparison",
+			should: "report modified files whose size or mod time differ",
+			expectedRecords: []string{
+				"* a/size.txt",
+				"* a/touched.txt",
+				"- b/gone.txt",
+				"~ c",
+				"- c/inner.txt",
+				"+ d",
+				"+ d/new.md",
+				"- e.md",
+			},
+			expectedSummary: diff.Summary{
+				Added:       2,
+				Removed:     3,
+				TypeChanged: 1,
+				Modified:    2,
+				Unchanged:   2,
+			},
+		}),
+
+		Entry(nil, &diffTE{
+			given:  "hash comparison",
+			should: "report modified files whose content differs",
+			method: diff.MethodHashEn,
+			expectedRecords: []string{
+				"* a/content.txt",
+				"* a/size.txt",
+				"- b/gone.txt",
+				"~ c",
+				"- c/inner.txt",
+				"+ d",
+				"+ d/new.md",
+				"- e.md",
+			},
+			expectedSummary: diff.Summary{
+				Added:       2,
+				Removed:     3,
+				TypeChanged: 1,
+				Modified:    2,
+				Unchanged:   2,
+			},
+		}),
+
+		Entry(nil, &diffTE{
+			given:  "hash comparison via virtual file system",
+			should: "report modified files whose content differs",
+			method: diff.MethodHashEn,
+			useVFS: true,
+			expectedRecords: []string{
+				"* a/content.txt",
+				"* a/size.txt",
+				"- b/gone.txt",
+				"~ c",
+				"- c/inner.txt",
+				"+ d",
+				"+ d/new.md",
+				"- e.md",
+			},
+			expectedSummary: diff.Summary{
+				Added:       2,
+				Removed:     3,
+				TypeChanged: 1,
+				Modified:    2,
+				Unchanged:   2,
+			},
+		}),
+
+		Entry(nil, &diffTE{
+			given:  "filter",
+			should: "only compare entries that pass the filter",
+			optionsFn: func(o *nav.TraverseOptions) {
+				o.Store.FilterDefs = &nav.FilterDefinitions{
+					Node: nav.FilterDef{
+						Type:            nav.FilterTypeGlobEn,
+						Description:     "text files",
+						Pattern:         "*.txt",
+						Scope:           nav.ScopeFileEn,
+						IfNotApplicable: nav.TriStateBoolTrueEn,
+					},
+				}
+			},
+			expectedRecords: []string{
+				"* a/size.txt",
+				"* a/touched.txt",
+				"- b/gone.txt",
+				"~ c",
+				"- c/inner.txt",
+				"+ d",
+			},
+			expectedSummary: diff.Summary{
+				Added:       1,
+				Removed:     2,
+				TypeChanged: 1,
+				Modified:    2,
+				Unchanged:   2,
+			},
+		}),
+	)
+
+	When("identical trees", func() {
+		It("🧪 should: report no differences", func(ctx SpecContext) {
+			result, err := diff.Compare(ctx, &diff.CompareParams{
+				Left:  left,
+				Right: left,
+			})
+
+			Expect(err).To(BeNil())
+			Expect(result.Summary.Differences()).To(Equal(0))
+			Expect(result.Summary.Unchanged).To(Equal(7))
+		})
+	})
+
+	When("context is cancelled", func() {
+		It("🧪 should: return context error", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := diff.Compare(ctx, &diff.CompareParams{
+				Left:  left,
+				Right: right,
+			})
+
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+
+	When("children filter defined", func() {
+		It("🧪 should: return unsupported filter error", func(ctx SpecContext) {
+			_, err := diff.Compare(ctx, &diff.CompareParams{
+				Left:  left,
+				Right: right,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.FilterDefs = &nav.FilterDefinitions{
+						Children: nav.CompoundFilterDef{
+							Type:    nav.FilterTypeGlobEn,
+							Pattern: "*.txt",
+						},
+					}
+				},
+			})
+
+			Expect(err).To(MatchError(diff.ErrUnsupportedFilter))
+		})
+	})
+
+	When("directory can not be read", func() {
+		It("🧪 should: report error in result, but not return it", func(ctx SpecContext) {
+			vfs, err := storage.UseFaultyFS(storage.UseNativeFS(), storage.Fault{
+				Op:   "ReadDir",
+				Glob: "a",
+				Err:  fs.ErrPermission,
+			})
+			Expect(err).To(Succeed())
+
+			result, err := diff.Compare(ctx, &diff.CompareParams{
+				Left:  left,
+				Right: right,
+				FS:    vfs,
+			})
+
+			Expect(err).To(BeNil())
+			Expect(result.Errors).To(HaveKey(filepath.Join(left, "a")))
+			Expect(result.Errors[filepath.Join(left, "a")]).To(MatchError(fs.ErrPermission))
+			Expect(result.Summary.Modified).To(BeZero())
+		})
+	})
+
+	When("root is not a directory", func() {
+		It("🧪 should: return error", func(ctx SpecContext) {
+			_, err := diff.Compare(ctx, &diff.CompareParams{
+				Left:  left,
+				Right: filepath.Join(right, "c"),
+			})
+
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
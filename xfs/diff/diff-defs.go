@@ -0,0 +1,135 @@
+package diff
+
+import (
+	"io/fs"
+
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// KindEnum denotes the nature of a difference between the two trees.
+type KindEnum uint
+
+const (
+	KindUndefinedEn KindEnum = iota
+
+	// KindAddedEn, the entry exists only in the right tree
+	//
+	KindAddedEn
+
+	// KindRemovedEn, the entry exists only in the left tree
+	//
+	KindRemovedEn
+
+	// KindTypeChangedEn, the entry exists in both trees, but is of a different
+	// type, eg a file in one tree and a directory in the other
+	//
+	KindTypeChangedEn
+
+	// KindModifiedEn, the file exists in both trees, but its content is deemed
+	// to be different, according to the comparison Method
+	//
+	KindModifiedEn
+)
+
+// MethodEnum defines how files present in both trees are deemed to be
+// modified.
+type MethodEnum uint
+
+const (
+	// MethodSizeModTimeEn, files are modified if their size or modification
+	// time differ. This is the default and does not require any file content
+	// to be read.
+	//
+	MethodSizeModTimeEn MethodEnum = iota
+
+	// MethodHashEn, files are modified if their size or content hash differ.
+	// Modification times are ignored, so files that have merely been copied
+	// are not reported.
+	//
+	MethodHashEn
+)
+
+// CompareParams defines the parameters required to compare two trees.
+type CompareParams struct {
+	// Left is the root of the tree regarded as the original
+	//
+	Left string
+
+	// Right is the root of the tree compared against the original
+	//
+	Right string
+
+	// OptionsFn allows the client to customise the comparison with the same
+	// options that are used to customise a traversal. The following are
+	// honoured: Hooks.QueryStatus, Hooks.ReadDirectory, Hooks.Sort,
+	// Store.Behaviours.Sort.IsCaseSensitive and Store.FilterDefs.Node, which
+	// restricts which entries are compared. Store.FilterDefs.Children is not
+	// supported; defining it causes Compare to return ErrUnsupportedFilter.
+	//
+	OptionsFn nav.TraverseOptionFn
+
+	// Method defines how files present in both trees are compared
+	//
+	Method MethodEnum
+
+	// FS is the file system both trees are read from. When set, the
+	// QueryStatus and ReadDirectory hooks are always set to read from it,
+	// otherwise the native file system is used.
+	//
+	FS storage.ReadOnlyVirtualFS
+
+	// Records, when set, receives a record for each difference found, in
+	// traversal order. The channel is closed by Compare when the comparison
+	// is complete, so the client should consume it on a separate go routine.
+	//
+	Records chan<- *Record
+}
+
+// Record describes a single difference between the two trees.
+type Record struct {
+	// Kind denotes the nature of the difference
+	//
+	Kind KindEnum
+
+	// SubPath is the path of the entry relative to both roots
+	//
+	SubPath string
+
+	// Left is the file info of the entry in the left tree (nil when added)
+	//
+	Left fs.FileInfo
+
+	// Right is the file info of the entry in the right tree (nil when removed)
+	//
+	Right fs.FileInfo
+}
+
+// Summary contains the counts of each kind of difference found.
+type Summary struct {
+	Added       int
+	Removed     int
+	TypeChanged int
+	Modified    int
+
+	// Unchanged is the number of files present in both trees, that are
+	// deemed to be the same
+	//
+	Unchanged int
+}
+
+// Differences returns the total number of differences found
+func (s *Summary) Differences() int {
+	return s.Added + s.Removed + s.TypeChanged + s.Modified
+}
+
+// Result is the outcome of comparing two trees.
+type Result struct {
+	Summary Summary
+
+	// Errors contains the errors encountered for individual paths. Entries
+	// that could not be read are excluded from the comparison. These errors
+	// are only reported here; they are not returned by Compare.
+	//
+	Errors map[string]error
+}
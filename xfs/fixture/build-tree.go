@@ -0,0 +1,149 @@
+package fixture
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	defaultFilePerm = 0o666
+)
+
+// Build creates the tree on the virtual file system provided. The contents
+// of the root directory of the tree are created inside the root path
+// specified, which is created if necessary. Modes and modification times of
+// directories are applied after their contents have been created, so that
+// they are not disturbed by, nor prevent, the creation of their contents.
+func Build(vfs storage.VirtualFS, root string, tree *Tree) error {
+	b := &builder{
+		vfs: vfs,
+	}
+
+	return b.directory(root, &tree.Root)
+}
+
+// Ensure builds the tree with Build, but only if the root path does not
+// already exist.
+func Ensure(vfs storage.VirtualFS, root string, tree *Tree) error {
+	if vfs.DirectoryExists(root) {
+		return nil
+	}
+
+	return Build(vfs, root, tree)
+}
+
+// EnsureFrom loads the tree definition from the file at the path specified
+// on the native file system and builds it, but only if the root path does
+// not already exist.
+func EnsureFrom(vfs storage.VirtualFS, root, definition string) error {
+	if vfs.DirectoryExists(root) {
+		return nil
+	}
+
+	tree, err := Load(storage.UseNativeFS(), definition)
+	if err != nil {
+		return err
+	}
+
+	return Build(vfs, root, tree)
+}
+
+type builder struct {
+	vfs storage.VirtualFS
+}
+
+func (b *builder) directory(path string, dir *Directory) error {
+	if err := b.vfs.MkdirAll(path, os.ModePerm); err != nil {
+		return err
+	}
+
+	for i := range dir.Directories {
+		sub := &dir.Directories[i]
+
+		if err := b.directory(filepath.Join(path, sub.Name), sub); err != nil {
+			return err
+		}
+	}
+
+	for i := range dir.Files {
+		if err := b.file(path, &dir.Files[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, link := range dir.Symlinks {
+		if err := b.vfs.Symlink(link.Target, filepath.Join(path, link.Name)); err != nil {
+			return err
+		}
+	}
+
+	return b.apply(path, dir.Mode, dir.ModTime)
+}
+
+func (b *builder) file(parent string, file *File) error {
+	path := filepath.Join(parent, file.Name)
+	data := []byte(file.Text)
+
+	if padding := file.Size - int64(len(data)); padding > 0 {
+		data = append(data, bytes.Repeat([]byte{0}, int(padding))...)
+	}
+
+	perm, err := parseMode(file.Mode, defaultFilePerm)
+	if err != nil {
+		return fmt.Errorf("fixture: file '%v': %w", path, err)
+	}
+
+	if err := b.vfs.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+
+	return b.apply(path, file.Mode, file.ModTime)
+}
+
+// apply sets the mode and modification time of the node at the path, if
+// they have been specified.
+func (b *builder) apply(path, mode, modTime string) error {
+	if mode != "" {
+		perm, err := parseMode(mode, 0)
+		if err != nil {
+			return fmt.Errorf("fixture: '%v': %w", path, err)
+		}
+
+		if err := b.vfs.Chmod(path, perm); err != nil {
+			return err
+		}
+	}
+
+	if modTime != "" {
+		t, err := time.Parse(time.RFC3339, modTime)
+		if err != nil {
+			return fmt.Errorf("fixture: '%v': invalid mtime: %w", path, err)
+		}
+
+		if err := b.vfs.Chtimes(path, t, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseMode(mode string, def fs.FileMode) (fs.FileMode, error) {
+	if mode == "" {
+		return def, nil
+	}
+
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value > uint64(fs.ModePerm) {
+		return 0, fmt.Errorf("invalid mode '%v'", mode)
+	}
+
+	return fs.FileMode(value), nil
+}
@@ -0,0 +1,220 @@
+package fixture_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/xfs/fixture"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	xmlDefinition = `<?xml version="1.0" encoding="UTF-8"?>
+<tree>
+  <directory name="root">
+    <directory name="docs" mode="0750" mtime="2024-01-01T00:00:00Z">
+      <file name="readme.txt" mode="0640" mtime="2024-02-01T00:00:00Z">hello</file>
+      <file name="padded.bin" size="10">abc</file>
+    </directory>
+    <file name="big.bin" size="4096"/>
+    <symlink name="latest" target="docs/readme.txt"/>
+  </directory>
+</tree>`
+
+	jsonDefinition = `{
+  "directory": {
+    "name": "root",
+    "directories": [
+      {
+        "name": "docs",
+        "mode": "0750",
+        "mtime": "2024-01-01T00:00:00Z",
+        "files": [
+          { "name": "readme.txt", "mode": "0640", "mtime": "2024-02-01T00:00:00Z", "text": "hello" },
+          { "name": "padded.bin", "size": 10, "text": "abc" }
+        ]
+      }
+    ],
+    "files": [
+      { "name": "big.bin", "size": 4096 }
+    ],
+    "symlinks": [
+      { "name": "latest", "target": "docs/readme.txt" }
+    ]
+  }
+}`
+
+	yamlDefinition = `
+directory:
+  name: root
+  directories:
+    - name: docs
+      mode: "0750"
+      mtime: 2024-01-01T00:00:00Z
+      files:
+        - name: readme.txt
+          mode: "0640"
+          mtime: 2024-02-01T00:00:00Z
+          text: hello
+        - name: padded.bin
+          size: 10
+          text: abc
+  files:
+    - name: big.bin
+      size: 4096
+  symlinks:
+    - name: latest
+      target: docs/readme.txt
+`
+)
+
+type fixtureTE struct {
+	format     fixture.FormatEnum
+	definition string
+	backend    string
+}
+
+func backend(name string) storage.VirtualFS {
+	return map[string]func() storage.VirtualFS{
+		"native": storage.UseNativeFS,
+		"mem":    storage.UseMemFS,
+	}[name]()
+}
+
+var _ = Describe("Build", func() {
+	DescribeTable("tree definitions",
+		func(entry *fixtureTE) {
+			vfs := backend(entry.backend)
+			root := filepath.Join(GinkgoT().TempDir(), "root")
+
+			tree, err := fixture.Read(strings.NewReader(entry.definition), entry.format)
+			Expect(err).To(BeNil())
+			Expect(tree.Root.Name).To(Equal("root"))
+			Expect(fixture.Build(vfs, root, tree)).To(Succeed())
+
+			readme := filepath.Join(root, "docs", "readme.txt")
+			content, err := vfs.ReadFile(readme)
+			Expect(err).To(BeNil())
+			Expect(string(content)).To(Equal("hello"))
+
+			info, err := vfs.Lstat(readme)
+			Expect(err).To(BeNil())
+			Expect(info.Mode().Perm()).To(Equal(fs.FileMode(0o640)))
+			Expect(info.ModTime().Equal(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+
+			docs, err := vfs.Lstat(filepath.Join(root, "docs"))
+			Expect(err).To(BeNil())
+			Expect(docs.Mode().Perm()).To(Equal(fs.FileMode(0o750)))
+			Expect(docs.ModTime().Equal(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+
+			padded, err := vfs.ReadFile(filepath.Join(root, "docs", "padded.bin"))
+			Expect(err).To(BeNil())
+			Expect(padded).To(Equal([]byte{'a', 'b', 'c', 0, 0, 0, 0, 0, 0, 0}))
+
+			big, err := vfs.Lstat(filepath.Join(root, "big.bin"))
+			Expect(err).To(BeNil())
+			Expect(big.Size()).To(Equal(int64(4096)))
+
+			link, err := vfs.Lstat(filepath.Join(root, "latest"))
+			Expect(err).To(BeNil())
+			Expect(link.Mode() & fs.ModeSymlink).NotTo(BeZero())
+		},
+		func(entry *fixtureTE) string {
+			return fmt.Sprintf("🧪 ===> format: '%v', backend: '%v'", entry.format, entry.backend)
+		},
+
+		Entry(nil, &fixtureTE{format: fixture.FormatXMLEn, definition: xmlDefinition, backend: "native"}),
+		Entry(nil, &fixtureTE{format: fixture.FormatXMLEn, definition: xmlDefinition, backend: "mem"}),
+		Entry(nil, &fixtureTE{format: fixture.FormatJSONEn, definition: jsonDefinition, backend: "native"}),
+		Entry(nil, &fixtureTE{format: fixture.FormatJSONEn, definition: jsonDefinition, backend: "mem"}),
+		Entry(nil, &fixtureTE{format: fixture.FormatYAMLEn, definition: yamlDefinition, backend: "native"}),
+		Entry(nil, &fixtureTE{format: fixture.FormatYAMLEn, definition: yamlDefinition, backend: "mem"}),
+	)
+
+	When("defined by musico index", func() {
+		It("🧪 should: build tree once", func() {
+			vfs := storage.UseMemFS()
+			root := filepath.Join(GinkgoT().TempDir(), "MUSICO")
+			index := helpers.Path(helpers.Repo("../.."), "Test/data/musico-index.xml")
+
+			Expect(fixture.EnsureFrom(vfs, root, index)).To(Succeed())
+			Expect(vfs.FileExists(
+				filepath.Join(root, "bass", "DUB", "Dreadzone", "Second Light", "02 - Little Britain.flac"),
+			)).To(BeTrue())
+
+			Expect(fixture.EnsureFrom(vfs, root, "does-not-exist.xml")).To(Succeed())
+		})
+	})
+
+	When("mode is invalid", func() {
+		It("🧪 should: return error", func() {
+			tree := &fixture.Tree{
+				Root: fixture.Directory{
+					Files: []fixture.File{
+						{Name: "bad.txt", Mode: "0999"},
+					},
+				},
+			}
+
+			Expect(fixture.Build(storage.UseMemFS(), "/root", tree)).NotTo(Succeed())
+		})
+	})
+
+	When("format can't be inferred", func() {
+		It("🧪 should: return error", func() {
+			_, err := fixture.Load(storage.UseNativeFS(), "definition.txt")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
+
+var _ = Describe("Write", func() {
+	// canonical returns a form of the tree that is independent of the format
+	// it was read from
+	//
+	canonical := func(tree *fixture.Tree) string {
+		data, err := json.Marshal(tree)
+		Expect(err).To(BeNil())
+
+		return string(data)
+	}
+
+	DescribeTable("round trip",
+		func(format fixture.FormatEnum) {
+			tree, err := fixture.Read(strings.NewReader(xmlDefinition), fixture.FormatXMLEn)
+			Expect(err).To(BeNil())
+			tree.Root.Files[0].Hash = "8ed3f6ad685b959ead7022518e1af76cd816f8e8ec7ccdda1ed4018e8f2223f8"
+
+			var buffer bytes.Buffer
+			Expect(fixture.Write(&buffer, tree, format)).To(Succeed())
+
+			restored, err := fixture.Read(&buffer, format)
+			Expect(err).To(BeNil())
+			Expect(canonical(restored)).To(Equal(canonical(tree)))
+		},
+		func(format fixture.FormatEnum) string {
+			return fmt.Sprintf("🧪 ===> format: '%v'", format)
+		},
+
+		Entry(nil, fixture.FormatXMLEn),
+		Entry(nil, fixture.FormatJSONEn),
+		Entry(nil, fixture.FormatYAMLEn),
+	)
+
+	When("format is not supported", func() {
+		It("🧪 should: return error", func() {
+			var buffer bytes.Buffer
+
+			Expect(fixture.Write(&buffer, &fixture.Tree{}, fixture.FormatUndefinedEn)).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,69 @@
+package fixture
+
+import (
+	"encoding/xml"
+)
+
+// FormatEnum defines the serialised form of a tree definition.
+type FormatEnum uint
+
+const (
+	FormatUndefinedEn FormatEnum = iota
+
+	// FormatXMLEn, the tree is defined as nested directory, file and symlink
+	// elements inside a tree element (see Test/data/musico-index.xml)
+	//
+	FormatXMLEn
+
+	// FormatJSONEn, the tree is defined as a JSON object
+	//
+	FormatJSONEn
+
+	// FormatYAMLEn, the tree is defined as a YAML document
+	//
+	FormatYAMLEn
+)
+
+// Tree defines a directory tree that can be built on a virtual file system.
+//
+// Modes are specified as octal strings (eg "0755") and modification times
+// in RFC3339 format (eg "2024-01-01T00:00:00Z"); when not specified, the
+// default for the backend is used.
+type Tree struct {
+	XMLName xml.Name  `xml:"tree" json:"-" yaml:"-"`
+	Root    Directory `xml:"directory" json:"directory" yaml:"directory"`
+}
+
+// Directory defines a directory and its contents.
+type Directory struct {
+	XMLName     xml.Name    `xml:"directory" json:"-" yaml:"-"`
+	Name        string      `xml:"name,attr" json:"name" yaml:"name"`
+	Mode        string      `xml:"mode,attr,omitempty" json:"mode,omitempty" yaml:"mode,omitempty"`
+	ModTime     string      `xml:"mtime,attr,omitempty" json:"mtime,omitempty" yaml:"mtime,omitempty"`
+	Directories []Directory `xml:"directory" json:"directories,omitempty" yaml:"directories,omitempty"`
+	Files       []File      `xml:"file" json:"files,omitempty" yaml:"files,omitempty"`
+	Symlinks    []Symlink   `xml:"symlink" json:"symlinks,omitempty" yaml:"symlinks,omitempty"`
+}
+
+// File defines a file. The file is written with its Text; if Size is
+// greater than the length of the Text, the remainder is padded with zero
+// bytes, so that large files can be defined without specifying content.
+// Hash is the hex encoded sha256 of the content recorded in a manifest (see
+// the manifest package); it is not used when the file is built.
+type File struct {
+	XMLName xml.Name `xml:"file" json:"-" yaml:"-"`
+	Name    string   `xml:"name,attr" json:"name" yaml:"name"`
+	Size    int64    `xml:"size,attr,omitempty" json:"size,omitempty" yaml:"size,omitempty"`
+	Mode    string   `xml:"mode,attr,omitempty" json:"mode,omitempty" yaml:"mode,omitempty"`
+	ModTime string   `xml:"mtime,attr,omitempty" json:"mtime,omitempty" yaml:"mtime,omitempty"`
+	Hash    string   `xml:"hash,attr,omitempty" json:"hash,omitempty" yaml:"hash,omitempty"`
+	Text    string   `xml:",chardata" json:"text,omitempty" yaml:"text,omitempty"`
+}
+
+// Symlink defines a symbolic link to Target, which may be relative to the
+// directory containing the link.
+type Symlink struct {
+	XMLName xml.Name `xml:"symlink" json:"-" yaml:"-"`
+	Name    string   `xml:"name,attr" json:"name" yaml:"name"`
+	Target  string   `xml:"target,attr" json:"target" yaml:"target"`
+}
@@ -0,0 +1,75 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/snivilised/extendio/xfs/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// Read de-serialises a tree definition from the reader in the format
+// specified.
+func Read(r io.Reader, format FormatEnum) (*Tree, error) {
+	var (
+		tree Tree
+		err  error
+	)
+
+	switch format {
+	case FormatXMLEn:
+		err = xml.NewDecoder(r).Decode(&tree)
+
+	case FormatJSONEn:
+		err = json.NewDecoder(r).Decode(&tree)
+
+	case FormatYAMLEn:
+		err = yaml.NewDecoder(r).Decode(&tree)
+
+	case FormatUndefinedEn:
+		err = fmt.Errorf("fixture: unsupported format (%v)", format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tree, nil
+}
+
+// Load reads the tree definition from the file at the path specified. The
+// format is inferred from the file extension (.xml, .json, .yaml or .yml).
+func Load(vfs storage.ReadOnlyVirtualFS, path string) (*Tree, error) {
+	format := FormatOf(path)
+
+	if format == FormatUndefinedEn {
+		return nil, fmt.Errorf("fixture: can't infer format of '%v'", path)
+	}
+
+	data, err := vfs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Read(bytes.NewReader(data), format)
+}
+
+// FormatOf returns the format inferred from the extension of the path
+// specified, or FormatUndefinedEn if not recognised.
+func FormatOf(path string) FormatEnum {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return FormatXMLEn
+	case ".json":
+		return FormatJSONEn
+	case ".yaml", ".yml":
+		return FormatYAMLEn
+	}
+
+	return FormatUndefinedEn
+}
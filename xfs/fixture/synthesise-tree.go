@@ -0,0 +1,89 @@
+package fixture
+
+import (
+	"fmt"
+)
+
+// Shape defines the shape of a synthetic tree.
+type Shape struct {
+	// Depth is the number of levels of directories beneath the root
+	//
+	Depth uint
+
+	// FanOut is the number of sub-directories of each directory, except
+	// those at the deepest level, which have none
+	//
+	FanOut uint
+
+	// Files is the number of files in each directory, including the root
+	//
+	Files uint
+
+	// FileSize is the size of each file
+	//
+	FileSize int64
+
+	// Extensions are assigned to the files in turn, so that they can be
+	// selected by filters. Defaults to "txt".
+	//
+	Extensions []string
+}
+
+// Count returns the number of directories (including the root) and files
+// in a tree of this shape.
+func (s *Shape) Count() (directories, files uint) {
+	level := uint(1)
+
+	for depth := uint(0); depth <= s.Depth; depth++ {
+		directories += level
+		level *= s.FanOut
+	}
+
+	return directories, directories * s.Files
+}
+
+// String returns a compact description of the shape, eg "d3f4n8" for a
+// depth of 3, a fan out of 4 and 8 files per directory.
+func (s *Shape) String() string {
+	return fmt.Sprintf("d%vf%vn%v", s.Depth, s.FanOut, s.Files)
+}
+
+// Synthesise creates the definition of a tree of the shape specified.
+func Synthesise(shape *Shape) *Tree {
+	extensions := shape.Extensions
+	if len(extensions) == 0 {
+		extensions = []string{"txt"}
+	}
+
+	return &Tree{
+		Root: synthesise("synthetic", shape, extensions, shape.Depth),
+	}
+}
+
+func synthesise(name string, shape *Shape, extensions []string, remaining uint) Directory {
+	dir := Directory{
+		Name:  name,
+		Files: make([]File, 0, shape.Files),
+	}
+
+	for i := uint(0); i < shape.Files; i++ {
+		dir.Files = append(dir.Files, File{
+			Name: fmt.Sprintf("file-%03d.%v", i, extensions[i%uint(len(extensions))]),
+			Size: shape.FileSize,
+		})
+	}
+
+	if remaining == 0 {
+		return dir
+	}
+
+	dir.Directories = make([]Directory, 0, shape.FanOut)
+
+	for i := uint(0); i < shape.FanOut; i++ {
+		dir.Directories = append(dir.Directories,
+			synthesise(fmt.Sprintf("dir-%02d", i), shape, extensions, remaining-1),
+		)
+	}
+
+	return dir
+}
@@ -0,0 +1,85 @@
+package fixture_test
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/xfs/fixture"
+)
+
+type synthesiseTE struct {
+	shape   fixture.Shape
+	backend string
+}
+
+var _ = Describe("Synthesise", func() {
+	DescribeTable("shapes",
+		func(entry *synthesiseTE) {
+			vfs := backend(entry.backend)
+			root := filepath.Join(GinkgoT().TempDir(), "synthetic")
+			expectedDirectories, expectedFiles := entry.shape.Count()
+
+			Expect(fixture.Build(vfs, root, fixture.Synthesise(&entry.shape))).To(Succeed())
+
+			var directories, files uint
+
+			extensions := map[string]uint{}
+
+			Expect(vfs.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if d.IsDir() {
+					directories++
+
+					return nil
+				}
+
+				files++
+				extensions[strings.TrimPrefix(filepath.Ext(path), ".")]++
+
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+
+				Expect(info.Size()).To(Equal(entry.shape.FileSize), path)
+
+				return nil
+			})).To(Succeed())
+
+			Expect(directories).To(Equal(expectedDirectories))
+			Expect(files).To(Equal(expectedFiles))
+
+			for _, extension := range entry.shape.Extensions {
+				Expect(extensions[extension]).To(Equal(files/uint(len(entry.shape.Extensions))), extension)
+			}
+		},
+		func(entry *synthesiseTE) string {
+			return fmt.Sprintf("🧪 ===> shape: '%v', backend: '%v'", entry.shape.String(), entry.backend)
+		},
+
+		Entry(nil, &synthesiseTE{
+			shape:   fixture.Shape{Depth: 2, FanOut: 3, Files: 4, FileSize: 16, Extensions: []string{"txt", "jpg"}},
+			backend: "native",
+		}),
+		Entry(nil, &synthesiseTE{
+			shape:   fixture.Shape{Depth: 2, FanOut: 3, Files: 4, FileSize: 16, Extensions: []string{"txt", "jpg"}},
+			backend: "mem",
+		}),
+		Entry(nil, &synthesiseTE{
+			shape:   fixture.Shape{Depth: 0, Files: 5},
+			backend: "mem",
+		}),
+		Entry(nil, &synthesiseTE{
+			shape:   fixture.Shape{Depth: 3, FanOut: 2},
+			backend: "mem",
+		}),
+	)
+})
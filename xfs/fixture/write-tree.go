@@ -0,0 +1,60 @@
+package fixture
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	indentSize = 2
+)
+
+// Write serialises the tree definition to the writer in the format
+// specified, in a form that can be read back with Read.
+func Write(w io.Writer, tree *Tree, format FormatEnum) error {
+	switch format {
+	case FormatXMLEn:
+		return writeXML(w, tree)
+
+	case FormatJSONEn:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(tree)
+
+	case FormatYAMLEn:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(indentSize)
+
+		if err := encoder.Encode(tree); err != nil {
+			return err
+		}
+
+		return encoder.Close()
+
+	case FormatUndefinedEn:
+	}
+
+	return fmt.Errorf("fixture: unsupported format (%v)", format)
+}
+
+func writeXML(w io.Writer, tree *Tree) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(tree); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
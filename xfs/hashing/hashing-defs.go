@@ -0,0 +1,81 @@
+package hashing
+
+import (
+	"crypto/md5"  //nolint:gosec // md5 is offered for compatibility, not security
+	"crypto/sha1" //nolint:gosec // sha1 is offered for compatibility, not security
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// AlgorithmEnum identifies a content hashing algorithm
+type AlgorithmEnum uint
+
+// If these enum definitions change, persisted options that select
+// algorithms also need to be updated.
+
+const (
+	AlgorithmUndefinedEn AlgorithmEnum = iota
+	AlgorithmSHA256En
+	AlgorithmSHA512En
+	AlgorithmSHA1En
+	AlgorithmMD5En
+	AlgorithmXXHash64En
+)
+
+type algorithmInfo struct {
+	name string
+	make func() hash.Hash
+}
+
+var algorithms = map[AlgorithmEnum]algorithmInfo{
+	AlgorithmSHA256En:   {name: "sha256", make: sha256.New},
+	AlgorithmSHA512En:   {name: "sha512", make: sha512.New},
+	AlgorithmSHA1En:     {name: "sha1", make: sha1.New},
+	AlgorithmMD5En:      {name: "md5", make: md5.New},
+	AlgorithmXXHash64En: {name: "xxhash64", make: func() hash.Hash { return NewXXHash64() }},
+}
+
+func (a AlgorithmEnum) String() string {
+	if info, found := algorithms[a]; found {
+		return info.name
+	}
+
+	return "undefined"
+}
+
+// Options defines which algorithms are computed and where the digests
+// are cached.
+type Options struct {
+	// Algorithms are the algorithms computed for each file. When a digest
+	// is requested, all the selected algorithms are computed in a single
+	// pass over the file's content.
+	//
+	Algorithms []AlgorithmEnum
+
+	// CachePath is the path of the file in which digests are persisted, so
+	// that subsequent runs do not re-hash unchanged files. When not set, the
+	// digests are only cached in memory for the lifetime of the service.
+	//
+	CachePath string
+
+	// VirtualFS is the file system from which a navigator's hashing service
+	// reads the files and persists the cache. Defaults to the native file
+	// system.
+	//
+	VirtualFS storage.VirtualFS `json:"-"`
+}
+
+// Statistics records the effectiveness of the cache.
+type Statistics struct {
+	// Hits is the number of digests served from the cache
+	//
+	Hits uint
+
+	// Misses is the number of times a file had to be read to
+	// compute its digests
+	//
+	Misses uint
+}
@@ -0,0 +1,244 @@
+package hashing
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/snivilised/extendio/i18n"
+	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/storage"
+	"github.com/snivilised/extendio/xfs/utils"
+)
+
+const (
+	cacheVersion = 1
+	cachePerm    = 0o644
+	cacheDirPerm = 0o755
+)
+
+// record is the cache entry for a single file. The digests are only valid
+// while the size, modification time and inode of the file are unchanged.
+type record struct {
+	Size    int64             `json:"size"`
+	ModTime int64             `json:"mtime"`
+	Inode   uint64            `json:"inode"`
+	Digests map[string]string `json:"digests"`
+}
+
+func (r *record) matches(info fs.FileInfo) bool {
+	return r.Size == info.Size() &&
+		r.ModTime == info.ModTime().UnixNano() &&
+		r.Inode == utils.Inode(info)
+}
+
+type cacheFile struct {
+	Version int                `json:"version"`
+	Entries map[string]*record `json:"entries"`
+}
+
+// Service computes content digests of files, caching them by path, size,
+// modification time and inode, so that a file is only read again once it
+// has changed. The service is safe for concurrent use, which allows files
+// to be hashed on a worker pool.
+type Service struct {
+	vfs      storage.VirtualFS
+	options  Options
+	selected map[AlgorithmEnum]bool
+	mutex    sync.Mutex
+	entries  map[string]*record
+	stats    Statistics
+	dirty    bool
+}
+
+// New creates a hashing service, that reads files and the cache via the
+// virtual file system. If a cache path is specified, previously persisted
+// digests are loaded from it. A cache that can't be read (eg because it is
+// corrupt or was written by an incompatible version) is discarded, since
+// the digests can always be re-computed.
+func New(vfs storage.VirtualFS, options *Options) *Service {
+	if options == nil {
+		options = &Options{}
+	}
+
+	s := &Service{
+		vfs:      vfs,
+		options:  *options,
+		selected: make(map[AlgorithmEnum]bool, len(options.Algorithms)),
+		entries:  make(map[string]*record),
+	}
+
+	for _, algorithm := range options.Algorithms {
+		if _, found := algorithms[algorithm]; found {
+			s.selected[algorithm] = true
+		}
+	}
+
+	s.load()
+
+	return s
+}
+
+func (s *Service) load() {
+	if s.options.CachePath == "" {
+		return
+	}
+
+	data, err := s.vfs.ReadFile(s.options.CachePath)
+	if err != nil {
+		return
+	}
+
+	var cache cacheFile
+
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Version != cacheVersion {
+		return
+	}
+
+	if cache.Entries != nil {
+		s.entries = cache.Entries
+	}
+}
+
+// IsSelected returns true if the algorithm is one of those selected
+// in the options.
+func (s *Service) IsSelected(algorithm AlgorithmEnum) bool {
+	return s.selected[algorithm]
+}
+
+// Digest returns the hex encoded digest of the content of the file at the
+// path, using the algorithm, which must be one of those selected. The info
+// is used to determine whether a cached digest is still valid; when not
+// provided, the file is stat'ed. On a cache miss, all of the selected
+// algorithms are computed together, in a single pass over the content.
+func (s *Service) Digest(path string, info fs.FileInfo, algorithm AlgorithmEnum) (string, error) {
+	if !s.selected[algorithm] {
+		return "", i18n.NewHashNotAvailableError(algorithm.String(), path)
+	}
+
+	if info == nil {
+		var err error
+
+		if info, err = s.vfs.Stat(path); err != nil {
+			return "", err
+		}
+	}
+
+	if !info.Mode().IsRegular() {
+		return "", i18n.NewHashNotAvailableError(algorithm.String(), path)
+	}
+
+	name := algorithm.String()
+
+	if digest, found := s.lookup(path, info, name); found {
+		return digest, nil
+	}
+
+	digests, err := s.compute(path)
+	if err != nil {
+		return "", err
+	}
+
+	s.store(path, info, digests)
+
+	return digests[name], nil
+}
+
+func (s *Service) lookup(path string, info fs.FileInfo, name string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, found := s.entries[path]; found && entry.matches(info) {
+		if digest, found := entry.Digests[name]; found {
+			s.stats.Hits++
+
+			return digest, true
+		}
+	}
+
+	s.stats.Misses++
+
+	return "", false
+}
+
+func (s *Service) store(path string, info fs.FileInfo, digests map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries[path] = &record{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Inode:   utils.Inode(info),
+		Digests: digests,
+	}
+	s.dirty = true
+}
+
+func (s *Service) compute(path string) (map[string]string, error) {
+	hashes := make(map[string]hash.Hash, len(s.selected))
+	writers := make([]io.Writer, 0, len(s.selected))
+
+	for algorithm := range s.selected {
+		h := algorithms[algorithm].make()
+		hashes[algorithm.String()] = h
+		writers = append(writers, h)
+	}
+
+	file, err := s.vfs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(io.MultiWriter(writers...), file); err != nil {
+		return nil, err
+	}
+
+	return lo.MapValues(hashes, func(h hash.Hash, _ string) string {
+		return hex.EncodeToString(h.Sum(nil))
+	}), nil
+}
+
+// Statistics returns the number of cache hits and misses incurred so far.
+func (s *Service) Statistics() Statistics {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.stats
+}
+
+// Save persists the cache, if a cache path was specified and new digests
+// have been computed since it was loaded or last saved.
+func (s *Service) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.options.CachePath == "" || !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(&cacheFile{
+		Version: cacheVersion,
+		Entries: s.entries,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.vfs.MkdirAll(filepath.Dir(s.options.CachePath), cacheDirPerm); err != nil {
+		return err
+	}
+
+	if err := s.vfs.WriteFile(s.options.CachePath, data, os.FileMode(cachePerm)); err != nil {
+		return err
+	}
+
+	s.dirty = false
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package hashing_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/hashing"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	perm = 0o644
+)
+
+func sha256Of(content string) string {
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("hashing-service", func() {
+	var (
+		root, file, cache string
+		options           *hashing.Options
+		service           *hashing.Service
+	)
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+
+		root = GinkgoT().TempDir()
+		file = filepath.Join(root, "a.flac")
+		cache = filepath.Join(root, "cache", "digests.json")
+		Expect(os.WriteFile(file, []byte("alpha"), perm)).To(Succeed())
+
+		options = &hashing.Options{
+			Algorithms: []hashing.AlgorithmEnum{
+				hashing.AlgorithmSHA256En, hashing.AlgorithmXXHash64En,
+			},
+			CachePath: cache,
+		}
+		service = hashing.New(storage.UseNativeFS(), options)
+	})
+
+	It("🧪 should: compute all selected algorithms in a single pass", func() {
+		digest, err := service.Digest(file, nil, hashing.AlgorithmSHA256En)
+		Expect(err).To(Succeed())
+		Expect(digest).To(Equal(sha256Of("alpha")))
+
+		_, err = service.Digest(file, nil, hashing.AlgorithmXXHash64En)
+		Expect(err).To(Succeed())
+		Expect(service.Statistics()).To(Equal(hashing.Statistics{Hits: 1, Misses: 1}))
+	})
+
+	When("algorithm not selected", func() {
+		It("🧪 should: return hash not available error", func() {
+			_, err := service.Digest(file, nil, hashing.AlgorithmMD5En)
+			Expect(QueryHashNotAvailableError(err)).To(BeTrue())
+		})
+	})
+
+	When("path is a directory", func() {
+		It("🧪 should: return hash not available error", func() {
+			_, err := service.Digest(root, nil, hashing.AlgorithmSHA256En)
+			Expect(QueryHashNotAvailableError(err)).To(BeTrue())
+		})
+	})
+
+	When("file modified", func() {
+		It("🧪 should: re-compute digest", func() {
+			_, err := service.Digest(file, nil, hashing.AlgorithmSHA256En)
+			Expect(err).To(Succeed())
+
+			Expect(os.WriteFile(file, []byte("bravo"), perm)).To(Succeed())
+			later := time.Now().Add(time.Hour)
+			Expect(os.Chtimes(file, later, later)).To(Succeed())
+
+			digest, err := service.Digest(file, nil, hashing.AlgorithmSHA256En)
+			Expect(err).To(Succeed())
+			Expect(digest).To(Equal(sha256Of("bravo")))
+			Expect(service.Statistics().Misses).To(Equal(uint(2)))
+		})
+	})
+
+	When("cache saved", func() {
+		It("🧪 should: serve digests to subsequent service from cache", func() {
+			_, err := service.Digest(file, nil, hashing.AlgorithmSHA256En)
+			Expect(err).To(Succeed())
+			Expect(service.Save()).To(Succeed())
+
+			subsequent := hashing.New(storage.UseNativeFS(), options)
+			digest, err := subsequent.Digest(file, nil, hashing.AlgorithmSHA256En)
+			Expect(err).To(Succeed())
+			Expect(digest).To(Equal(sha256Of("alpha")))
+			Expect(subsequent.Statistics()).To(Equal(hashing.Statistics{Hits: 1}))
+		})
+	})
+
+	When("cache is corrupt", func() {
+		It("🧪 should: discard cache", func() {
+			Expect(os.MkdirAll(filepath.Dir(cache), 0o755)).To(Succeed())
+			Expect(os.WriteFile(cache, []byte("{not json"), perm)).To(Succeed())
+
+			service = hashing.New(storage.UseNativeFS(), options)
+			digest, err := service.Digest(file, nil, hashing.AlgorithmSHA256En)
+			Expect(err).To(Succeed())
+			Expect(digest).To(Equal(sha256Of("alpha")))
+			Expect(service.Save()).To(Succeed())
+		})
+	})
+
+	When("no cache path", func() {
+		It("🧪 should: not persist", func() {
+			service = hashing.New(storage.UseNativeFS(), &hashing.Options{
+				Algorithms: []hashing.AlgorithmEnum{hashing.AlgorithmSHA256En},
+			})
+			_, err := service.Digest(file, nil, hashing.AlgorithmSHA256En)
+			Expect(err).To(Succeed())
+			Expect(service.Save()).To(Succeed())
+			Expect(cache).NotTo(BeAnExistingFile())
+		})
+	})
+})
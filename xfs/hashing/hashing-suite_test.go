@@ -0,0 +1,13 @@
+package hashing_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+)
+
+func TestHashing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hashing Suite")
+}
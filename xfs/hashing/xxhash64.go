@@ -0,0 +1,152 @@
+package hashing
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// An implementation of the 64 bit variant of the xxHash algorithm
+// (https://github.com/Cyan4973/xxHash), which is a fast non-cryptographic
+// hash, suitable for detecting changes in content.
+
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+
+	xxStripeSize = 32
+)
+
+type xxHash64 struct {
+	v1, v2, v3, v4 uint64
+	total          uint64
+	buffer         [xxStripeSize]byte
+	used           int
+}
+
+// NewXXHash64 creates a hash.Hash64 that computes the xxHash64 digest
+// (seed 0). As with the other algorithms, the sum is big endian, so its hex
+// encoding matches the canonical representation.
+func NewXXHash64() hash.Hash64 {
+	h := &xxHash64{}
+	h.Reset()
+
+	return h
+}
+
+func (h *xxHash64) Reset() {
+	// the accumulators are initialised with arithmetic that wraps around,
+	// which is not permitted for constant expressions
+	//
+	prime1, prime2 := xxPrime1, xxPrime2
+
+	h.v1 = prime1 + prime2
+	h.v2 = prime2
+	h.v3 = 0
+	h.v4 = -prime1
+	h.total = 0
+	h.used = 0
+}
+
+func (h *xxHash64) Size() int {
+	return 8 //nolint:mnd // 64 bits
+}
+
+func (h *xxHash64) BlockSize() int {
+	return xxStripeSize
+}
+
+func (h *xxHash64) Write(data []byte) (int, error) {
+	n := len(data)
+	h.total += uint64(n)
+
+	if h.used+n < xxStripeSize {
+		h.used += copy(h.buffer[h.used:], data)
+
+		return n, nil
+	}
+
+	if h.used > 0 {
+		consumed := copy(h.buffer[h.used:], data)
+		h.stripe(h.buffer[:])
+		data = data[consumed:]
+		h.used = 0
+	}
+
+	for ; len(data) >= xxStripeSize; data = data[xxStripeSize:] {
+		h.stripe(data)
+	}
+
+	h.used = copy(h.buffer[:], data)
+
+	return n, nil
+}
+
+func (h *xxHash64) stripe(data []byte) {
+	h.v1 = xxRound(h.v1, binary.LittleEndian.Uint64(data[0:8]))
+	h.v2 = xxRound(h.v2, binary.LittleEndian.Uint64(data[8:16]))
+	h.v3 = xxRound(h.v3, binary.LittleEndian.Uint64(data[16:24]))
+	h.v4 = xxRound(h.v4, binary.LittleEndian.Uint64(data[24:32]))
+}
+
+func (h *xxHash64) Sum64() uint64 {
+	var acc uint64
+
+	if h.total >= xxStripeSize {
+		acc = bits.RotateLeft64(h.v1, 1) + bits.RotateLeft64(h.v2, 7) +
+			bits.RotateLeft64(h.v3, 12) + bits.RotateLeft64(h.v4, 18)
+		acc = xxMerge(acc, h.v1)
+		acc = xxMerge(acc, h.v2)
+		acc = xxMerge(acc, h.v3)
+		acc = xxMerge(acc, h.v4)
+	} else {
+		acc = xxPrime5
+	}
+
+	acc += h.total
+	tail := h.buffer[:h.used]
+
+	for ; len(tail) >= 8; tail = tail[8:] {
+		acc ^= xxRound(0, binary.LittleEndian.Uint64(tail))
+		acc = bits.RotateLeft64(acc, 27)*xxPrime1 + xxPrime4
+	}
+
+	if len(tail) >= 4 { //nolint:mnd // 32 bit lane
+		acc ^= uint64(binary.LittleEndian.Uint32(tail)) * xxPrime1
+		acc = bits.RotateLeft64(acc, 23)*xxPrime2 + xxPrime3
+		tail = tail[4:]
+	}
+
+	for _, b := range tail {
+		acc ^= uint64(b) * xxPrime5
+		acc = bits.RotateLeft64(acc, 11) * xxPrime1
+	}
+
+	acc ^= acc >> 33
+	acc *= xxPrime2
+	acc ^= acc >> 29
+	acc *= xxPrime3
+	acc ^= acc >> 32
+
+	return acc
+}
+
+func (h *xxHash64) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+
+	return acc * xxPrime1
+}
+
+func xxMerge(acc, value uint64) uint64 {
+	acc ^= xxRound(0, value)
+
+	return acc*xxPrime1 + xxPrime4
+}
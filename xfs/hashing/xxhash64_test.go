@@ -0,0 +1,81 @@
+package hashing_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/xfs/hashing"
+)
+
+type xxHashTE struct {
+	message string
+	input   string
+	chunk   int
+	digest  uint64
+}
+
+var _ = Describe("xxhash64", func() {
+	DescribeTable("digest",
+		func(entry *xxHashTE) {
+			h := hashing.NewXXHash64()
+			data := []byte(entry.input)
+
+			// write in chunks, to exercise the buffering of partial stripes
+			//
+			for len(data) > 0 {
+				n := min(entry.chunk, len(data))
+				_, _ = h.Write(data[:n])
+				data = data[n:]
+			}
+
+			Expect(h.Sum64()).To(Equal(entry.digest))
+			Expect(hex.EncodeToString(h.Sum(nil))).To(Equal(fmt.Sprintf("%016x", entry.digest)))
+		},
+		func(entry *xxHashTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'",
+				entry.message, fmt.Sprintf("%016x", entry.digest),
+			)
+		},
+
+		Entry(nil, &xxHashTE{
+			message: "empty input",
+			input:   "",
+			chunk:   1,
+			digest:  0xef46db3751d8e999,
+		}),
+
+		Entry(nil, &xxHashTE{
+			message: "short input",
+			input:   "abc",
+			chunk:   3,
+			digest:  0x44bc2cf5ad770999,
+		}),
+
+		Entry(nil, &xxHashTE{
+			message: "input longer than a stripe",
+			input:   "Nobody inspects the spammish repetition",
+			chunk:   64,
+			digest:  0xfbcea83c8a378bf1,
+		}),
+
+		Entry(nil, &xxHashTE{
+			message: "input longer than a stripe, written in small chunks",
+			input:   "Nobody inspects the spammish repetition",
+			chunk:   5,
+			digest:  0xfbcea83c8a378bf1,
+		}),
+	)
+
+	It("🧪 should: produce same digest after reset", func() {
+		h := hashing.NewXXHash64()
+		_, _ = h.Write([]byte(strings.Repeat("x", 100)))
+		h.Reset()
+		_, _ = h.Write([]byte("abc"))
+
+		Expect(h.Sum64()).To(Equal(uint64(0x44bc2cf5ad770999)))
+	})
+})
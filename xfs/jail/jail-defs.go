@@ -0,0 +1,34 @@
+package jail
+
+import (
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	// maxLinkHops is the maximum number of symbolic links followed when
+	// resolving a path, beyond which the path is considered to be looping.
+	maxLinkHops = 255
+)
+
+// Options customises the jail.
+type Options struct {
+	// ReadOnly causes all the WriteToFS methods (and OpenFile with a
+	// mutative flag) to fail with a ReadOnlyFileSystemError.
+	//
+	ReadOnly bool
+}
+
+// VirtualFS is a storage.VirtualFS that confines all operations to a root
+// directory.
+type VirtualFS interface {
+	storage.VirtualFS
+
+	// Root returns the directory to which operations are confined
+	Root() string
+
+	// Abs returns the absolute representation of the path; a relative path
+	// is interpreted relative to the root. An error is returned if the path
+	// escapes the jail, either lexically via ".." or by resolving a symbolic
+	// link.
+	Abs(path string) (string, error)
+}
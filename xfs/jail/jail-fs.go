@@ -0,0 +1,490 @@
+package jail
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/snivilised/extendio/i18n"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+type jailFS struct {
+	backend storage.VirtualBackend
+	target  storage.VirtualFS
+	root    string
+	options Options
+}
+
+// New creates a VirtualFS that confines all operations on the target to the
+// root directory, which must exist. Relative paths are interpreted relative
+// to the root, so ~ and ./ style paths resolved with utils.ResolvePathIn
+// remain inside the jail. A path is rejected with a PathEscapesJailError if
+// it escapes the root, either lexically via ".." segments, or after
+// resolving the symbolic links it traverses. As with the operations they
+// guard, the final segment of a path is only resolved by the operations
+// that follow symbolic links (eg Stat, but not Lstat). The root should be
+// specified in canonical form, since symbolic links above it are not
+// resolved. Note, the jail does not guard against the file system being
+// concurrently modified by another party between the check and the
+// operation.
+func New(target storage.VirtualFS, root string, options *Options) (VirtualFS, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	root = filepath.Clean(root)
+
+	info, err := target.Stat(root)
+	if err != nil {
+		return nil, i18n.NewPathNotFoundError("Jail Root", root)
+	}
+
+	if !info.IsDir() {
+		return nil, i18n.NewNotADirectoryError(root)
+	}
+
+	return &jailFS{
+		backend: storage.VirtualBackend(fmt.Sprintf("jail(%v)", target.Backend())),
+		target:  target,
+		root:    root,
+		options: *options,
+	}, nil
+}
+
+func (j *jailFS) Backend() storage.VirtualBackend {
+	return j.backend
+}
+
+func (j *jailFS) Root() string {
+	return j.root
+}
+
+func (j *jailFS) Abs(path string) (string, error) {
+	return j.resolve(path, true)
+}
+
+func (j *jailFS) within(path string) bool {
+	if j.root == string(filepath.Separator) {
+		return true
+	}
+
+	return path == j.root || strings.HasPrefix(path, j.root+string(filepath.Separator))
+}
+
+// resolve returns the absolute path that the path represents, after checking
+// that neither it, nor any symbolic links it traverses, escape the jail.
+func (j *jailFS) resolve(path string, follow bool) (string, error) {
+	if path == "" {
+		return "", &fs.PathError{Op: "resolve", Path: path, Err: fs.ErrNotExist}
+	}
+
+	absolute := path
+	if !filepath.IsAbs(absolute) {
+		absolute = filepath.Join(j.root, absolute)
+	}
+
+	absolute = filepath.Clean(absolute)
+
+	if !j.within(absolute) {
+		return "", i18n.NewPathEscapesJailError(path, j.root)
+	}
+
+	if err := j.follow(path, absolute, follow); err != nil {
+		return "", err
+	}
+
+	return absolute, nil
+}
+
+// follow walks the segments of the absolute path below the root, resolving
+// the symbolic links encountered, making sure that none of them lead outside
+// of the jail. The final segment is only resolved when required.
+func (j *jailFS) follow(path, absolute string, final bool) error {
+	relative, err := filepath.Rel(j.root, absolute)
+	if err != nil || relative == "." {
+		return err
+	}
+
+	separator := string(filepath.Separator)
+	pending := strings.Split(relative, separator)
+	current := j.root
+	hops := 0
+
+	for len(pending) > 0 {
+		segment := pending[0]
+		pending = pending[1:]
+		next := filepath.Join(current, segment)
+
+		if !j.within(next) {
+			return i18n.NewPathEscapesJailError(path, j.root)
+		}
+
+		if len(pending) == 0 && !final {
+			return nil
+		}
+
+		info, err := j.target.Lstat(next)
+		if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+			// non existent segments are checked lexically only
+			//
+			current = next
+
+			continue
+		}
+
+		if hops++; hops > maxLinkHops {
+			return &fs.PathError{Op: "resolve", Path: path, Err: syscall.ELOOP}
+		}
+
+		link, err := j.target.ReadLink(next)
+		if err != nil {
+			return err
+		}
+
+		if filepath.IsAbs(link) {
+			link = filepath.Clean(link)
+
+			if !j.within(link) {
+				return i18n.NewPathEscapesJailError(path, j.root)
+			}
+
+			current = j.root
+
+			if link == j.root {
+				continue
+			}
+
+			link, _ = filepath.Rel(j.root, link)
+		}
+
+		pending = append(strings.Split(link, separator), pending...)
+	}
+
+	return nil
+}
+
+func (j *jailFS) writable(op, path string) error {
+	if j.options.ReadOnly {
+		return i18n.NewReadOnlyFileSystemError(op, path)
+	}
+
+	return nil
+}
+
+// mutate checks that the jail is writable and resolves the path.
+func (j *jailFS) mutate(op, path string, follow bool) (string, error) {
+	if err := j.writable(op, path); err != nil {
+		return "", err
+	}
+
+	return j.resolve(path, follow)
+}
+
+// temp resolves the directory of a temporary item, which defaults to the
+// root rather than os.TempDir, since that would be outside of the jail.
+func (j *jailFS) temp(op, dir, pattern string) (string, error) {
+	if err := j.writable(op, dir); err != nil {
+		return "", err
+	}
+
+	if strings.ContainsRune(pattern, filepath.Separator) {
+		return "", &fs.PathError{Op: op, Path: pattern, Err: fs.ErrInvalid}
+	}
+
+	if dir == "" {
+		return j.root, nil
+	}
+
+	return j.resolve(dir, true)
+}
+
+// interface ExistsInFS
+
+func (j *jailFS) FileExists(path string) bool {
+	absolute, err := j.resolve(path, false)
+
+	return err == nil && j.target.FileExists(absolute)
+}
+
+func (j *jailFS) DirectoryExists(path string) bool {
+	absolute, err := j.resolve(path, false)
+
+	return err == nil && j.target.DirectoryExists(absolute)
+}
+
+// end: interface ExistsInFS
+
+// interface ReadOnlyVirtualFS
+
+func (j *jailFS) Lstat(path string) (fs.FileInfo, error) {
+	absolute, err := j.resolve(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.target.Lstat(absolute)
+}
+
+func (j *jailFS) Stat(path string) (fs.FileInfo, error) {
+	absolute, err := j.resolve(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.target.Stat(absolute)
+}
+
+// Glob returns the matches of the pattern that are inside the jail. The
+// matches are absolute, even if the pattern is relative.
+func (j *jailFS) Glob(pattern string) ([]string, error) {
+	absolute, err := j.resolve(pattern, false)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := j.target.Glob(absolute)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(matches))
+
+	for _, match := range matches {
+		if _, err := j.resolve(match, false); err == nil {
+			result = append(result, match)
+		}
+	}
+
+	return result, nil
+}
+
+func (j *jailFS) Open(name string) (storage.File, error) {
+	absolute, err := j.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.target.Open(absolute)
+}
+
+func (j *jailFS) ReadFile(name string) ([]byte, error) {
+	absolute, err := j.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.target.ReadFile(absolute)
+}
+
+func (j *jailFS) ReadDir(name string) ([]os.DirEntry, error) {
+	absolute, err := j.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.target.ReadDir(absolute)
+}
+
+func (j *jailFS) ReadLink(name string) (string, error) {
+	absolute, err := j.resolve(name, false)
+	if err != nil {
+		return "", err
+	}
+
+	return j.target.ReadLink(absolute)
+}
+
+// WalkDir walks the tree at the root, which must be inside the jail. The
+// symbolic links encountered during the walk are not followed, so the walk
+// remains inside the jail. The paths passed to the function are absolute.
+func (j *jailFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	absolute, err := j.resolve(root, false)
+	if err != nil {
+		return err
+	}
+
+	return j.target.WalkDir(absolute, fn)
+}
+
+// end: interface ReadOnlyVirtualFS
+
+// interface WriteToFS
+
+func (j *jailFS) Chmod(name string, mode os.FileMode) error {
+	absolute, err := j.mutate("chmod", name, true)
+	if err != nil {
+		return err
+	}
+
+	return j.target.Chmod(absolute, mode)
+}
+
+func (j *jailFS) Chtimes(name string, atime, mtime time.Time) error {
+	absolute, err := j.mutate("chtimes", name, true)
+	if err != nil {
+		return err
+	}
+
+	return j.target.Chtimes(absolute, atime, mtime)
+}
+
+func (j *jailFS) Chown(name string, uid, gid int) error {
+	absolute, err := j.mutate("chown", name, true)
+	if err != nil {
+		return err
+	}
+
+	return j.target.Chown(absolute, uid, gid)
+}
+
+func (j *jailFS) Create(name string) (storage.File, error) {
+	absolute, err := j.mutate("create", name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.target.Create(absolute)
+}
+
+// CreateTemp creates the file in the root, when no directory is specified.
+func (j *jailFS) CreateTemp(dir, pattern string) (storage.File, error) {
+	absolute, err := j.temp("createtemp", dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.target.CreateTemp(absolute, pattern)
+}
+
+func (j *jailFS) Link(oldname, newname string) error {
+	oldAbsolute, err := j.mutate("link", oldname, false)
+	if err != nil {
+		return err
+	}
+
+	newAbsolute, err := j.resolve(newname, false)
+	if err != nil {
+		return err
+	}
+
+	return j.target.Link(oldAbsolute, newAbsolute)
+}
+
+func (j *jailFS) Mkdir(name string, perm fs.FileMode) error {
+	absolute, err := j.mutate("mkdir", name, false)
+	if err != nil {
+		return err
+	}
+
+	return j.target.Mkdir(absolute, perm)
+}
+
+func (j *jailFS) MkdirAll(path string, perm os.FileMode) error {
+	absolute, err := j.mutate("mkdir", path, true)
+	if err != nil {
+		return err
+	}
+
+	return j.target.MkdirAll(absolute, perm)
+}
+
+// MkdirTemp creates the directory in the root, when no directory is
+// specified.
+func (j *jailFS) MkdirTemp(dir, pattern string) (string, error) {
+	absolute, err := j.temp("mkdirtemp", dir, pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return j.target.MkdirTemp(absolute, pattern)
+}
+
+// OpenFile is permitted on a read only jail, as long as the flag does not
+// permit mutation.
+func (j *jailFS) OpenFile(name string, flag int, perm fs.FileMode) (storage.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		if err := j.writable("open", name); err != nil {
+			return nil, err
+		}
+	}
+
+	absolute, err := j.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.target.OpenFile(absolute, flag, perm)
+}
+
+func (j *jailFS) Remove(name string) error {
+	absolute, err := j.mutate("remove", name, false)
+	if err != nil {
+		return err
+	}
+
+	return j.target.Remove(absolute)
+}
+
+// RemoveAll refuses to remove the root of the jail.
+func (j *jailFS) RemoveAll(path string) error {
+	absolute, err := j.mutate("remove", path, false)
+	if err != nil {
+		return err
+	}
+
+	if absolute == j.root {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrPermission}
+	}
+
+	return j.target.RemoveAll(absolute)
+}
+
+func (j *jailFS) Rename(oldpath, newpath string) error {
+	oldAbsolute, err := j.mutate("rename", oldpath, false)
+	if err != nil {
+		return err
+	}
+
+	newAbsolute, err := j.resolve(newpath, false)
+	if err != nil {
+		return err
+	}
+
+	return j.target.Rename(oldAbsolute, newAbsolute)
+}
+
+// Symlink rejects a link whose target is outside of the jail; the target is
+// passed on unmodified, so a relative target remains relative.
+func (j *jailFS) Symlink(oldname, newname string) error {
+	newAbsolute, err := j.mutate("symlink", newname, false)
+	if err != nil {
+		return err
+	}
+
+	target := oldname
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(newAbsolute), target)
+	}
+
+	if _, err := j.resolve(target, true); err != nil {
+		return err
+	}
+
+	return j.target.Symlink(oldname, newAbsolute)
+}
+
+func (j *jailFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	absolute, err := j.mutate("write", name, true)
+	if err != nil {
+		return err
+	}
+
+	return j.target.WriteFile(absolute, data, perm)
+}
+
+// end: interface WriteToFS
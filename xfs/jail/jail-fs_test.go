@@ -0,0 +1,338 @@
+package jail_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/jail"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+type jailTE struct {
+	message string
+	should  string
+	fn      func(vfs jail.VirtualFS) error
+	escapes bool
+}
+
+const (
+	perm = 0o755
+)
+
+var _ = Describe("jail-fs", func() {
+	var (
+		outside, root string
+		vfs           jail.VirtualFS
+	)
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+
+		parent := GinkgoT().TempDir()
+		root = filepath.Join(parent, "jail")
+		outside = filepath.Join(parent, "outside")
+
+		Expect(os.MkdirAll(filepath.Join(root, "inside"), perm)).To(Succeed())
+		Expect(os.MkdirAll(outside, perm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "inside", "a.txt"), []byte("alpha"), perm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), perm)).To(Succeed())
+
+		Expect(os.Symlink(filepath.Join("..", "outside"), filepath.Join(root, "relative-escape"))).To(Succeed())
+		Expect(os.Symlink(outside, filepath.Join(root, "absolute-escape"))).To(Succeed())
+		Expect(os.Symlink("inside", filepath.Join(root, "relative-inside"))).To(Succeed())
+		Expect(os.Symlink(filepath.Join(root, "inside"), filepath.Join(root, "absolute-inside"))).To(Succeed())
+		Expect(os.Symlink("loop", filepath.Join(root, "loop"))).To(Succeed())
+
+		var err error
+		vfs, err = jail.New(storage.UseNativeFS(), root, nil)
+		Expect(err).To(Succeed())
+	})
+
+	It("🧪 should: report layering", func() {
+		Expect(vfs.Backend()).To(Equal(storage.VirtualBackend("jail(native)")))
+		Expect(vfs.Root()).To(Equal(root))
+	})
+
+	When("root does not exist", func() {
+		It("🧪 should: return path not found error", func() {
+			_, err := jail.New(storage.UseNativeFS(), filepath.Join(root, "missing"), nil)
+			Expect(QueryPathNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	When("root is a file", func() {
+		It("🧪 should: return not a directory error", func() {
+			_, err := jail.New(storage.UseNativeFS(), filepath.Join(root, "inside", "a.txt"), nil)
+			Expect(QueryNotADirectoryError(err)).To(BeTrue())
+		})
+	})
+
+	DescribeTable("confinement",
+		func(entry *jailTE) {
+			err := entry.fn(vfs)
+
+			if entry.escapes {
+				Expect(QueryPathEscapesJailError(err)).To(BeTrue(),
+					fmt.Sprintf("🔥 expected path escapes jail error, got: '%v'", err),
+				)
+			} else {
+				Expect(err).To(Succeed())
+			}
+		},
+		func(entry *jailTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.message, entry.should)
+		},
+
+		Entry(nil, &jailTE{
+			message: "relative path",
+			should:  "resolve relative to root",
+			fn: func(vfs jail.VirtualFS) error {
+				data, err := vfs.ReadFile(filepath.Join("inside", "a.txt"))
+				if err == nil && string(data) != "alpha" {
+					return fmt.Errorf("unexpected content '%v'", string(data))
+				}
+
+				return err
+			},
+		}),
+
+		Entry(nil, &jailTE{
+			message: "dot dot within jail",
+			should:  "permit",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.Stat(filepath.Join(root, "inside", "..", "inside", "a.txt"))
+
+				return err
+			},
+		}),
+
+		Entry(nil, &jailTE{
+			message: "relative dot dot escape",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.ReadFile(filepath.Join("..", "outside", "secret.txt"))
+
+				return err
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "absolute dot dot escape",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.ReadFile(filepath.Join(root, "inside", "..", "..", "outside", "secret.txt"))
+
+				return err
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "absolute path outside",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.Lstat(filepath.Join(outside, "secret.txt"))
+
+				return err
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "relative symlink escape",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.ReadFile(filepath.Join(root, "relative-escape", "secret.txt"))
+
+				return err
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "absolute symlink escape",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				return vfs.WriteFile(filepath.Join(root, "absolute-escape", "new.txt"), nil, perm)
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "stat of escaping symlink",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.Stat(filepath.Join(root, "absolute-escape"))
+
+				return err
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "lstat of escaping symlink",
+			should:  "permit, since the link is not followed",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.Lstat(filepath.Join(root, "absolute-escape"))
+
+				return err
+			},
+		}),
+
+		Entry(nil, &jailTE{
+			message: "remove escaping symlink",
+			should:  "permit, since the link is not followed",
+			fn: func(vfs jail.VirtualFS) error {
+				return vfs.Remove(filepath.Join(root, "relative-escape"))
+			},
+		}),
+
+		Entry(nil, &jailTE{
+			message: "relative symlink inside",
+			should:  "permit",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.ReadFile(filepath.Join(root, "relative-inside", "a.txt"))
+
+				return err
+			},
+		}),
+
+		Entry(nil, &jailTE{
+			message: "absolute symlink inside",
+			should:  "permit",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.ReadFile(filepath.Join(root, "absolute-inside", "a.txt"))
+
+				return err
+			},
+		}),
+
+		Entry(nil, &jailTE{
+			message: "create symlink to outside",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				return vfs.Symlink(filepath.Join("..", "..", "outside"), filepath.Join(root, "inside", "link"))
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "create symlink to inside",
+			should:  "permit",
+			fn: func(vfs jail.VirtualFS) error {
+				return vfs.Symlink("a.txt", filepath.Join(root, "inside", "link"))
+			},
+		}),
+
+		Entry(nil, &jailTE{
+			message: "rename to outside",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				return vfs.Rename(filepath.Join(root, "inside", "a.txt"), filepath.Join(outside, "a.txt"))
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "mkdir all through escaping symlink",
+			should:  "reject",
+			fn: func(vfs jail.VirtualFS) error {
+				return vfs.MkdirAll(filepath.Join(root, "relative-escape", "a", "b"), perm)
+			},
+			escapes: true,
+		}),
+
+		Entry(nil, &jailTE{
+			message: "abs of dot",
+			should:  "permit",
+			fn: func(vfs jail.VirtualFS) error {
+				_, err := vfs.Abs(".")
+
+				return err
+			},
+		}),
+	)
+
+	It("🧪 should: not report files outside of jail", func() {
+		Expect(vfs.FileExists(filepath.Join(outside, "secret.txt"))).To(BeFalse())
+		Expect(vfs.DirectoryExists(outside)).To(BeFalse())
+		Expect(vfs.FileExists(filepath.Join(root, "relative-escape", "secret.txt"))).To(BeFalse())
+		Expect(vfs.FileExists(filepath.Join(root, "relative-inside", "a.txt"))).To(BeTrue())
+	})
+
+	It("🧪 should: detect symlink loop", func() {
+		_, err := vfs.Stat(filepath.Join(root, "loop"))
+		Expect(err).To(MatchError(syscall.ELOOP))
+	})
+
+	It("🧪 should: create temporary items in root by default", func() {
+		dir, err := vfs.MkdirTemp("", "temp-*")
+		Expect(err).To(Succeed())
+		Expect(filepath.Dir(dir)).To(Equal(root))
+
+		_, err = vfs.MkdirTemp("", filepath.Join("..", "temp-*"))
+		Expect(err).To(MatchError(os.ErrInvalid))
+	})
+
+	It("🧪 should: not walk outside of jail", func() {
+		visited := []string{}
+		Expect(vfs.WalkDir(root, func(path string, _ os.DirEntry, err error) error {
+			visited = append(visited, path)
+
+			return err
+		})).To(Succeed())
+
+		Expect(visited).NotTo(ContainElement(filepath.Join(root, "relative-escape", "secret.txt")))
+		Expect(visited).To(ContainElement(filepath.Join(root, "relative-escape")))
+	})
+
+	When("read only", func() {
+		BeforeEach(func() {
+			var err error
+			vfs, err = jail.New(storage.UseNativeFS(), root, &jail.Options{ReadOnly: true})
+			Expect(err).To(Succeed())
+		})
+
+		It("🧪 should: permit reads", func() {
+			data, err := vfs.ReadFile(filepath.Join(root, "inside", "a.txt"))
+			Expect(err).To(Succeed())
+			Expect(string(data)).To(Equal("alpha"))
+
+			file, err := vfs.OpenFile(filepath.Join(root, "inside", "a.txt"), os.O_RDONLY, 0)
+			Expect(err).To(Succeed())
+			Expect(file.Close()).To(Succeed())
+		})
+
+		It("🧪 should: reject writes with localised error", func() {
+			for _, err := range []error{
+				vfs.WriteFile(filepath.Join(root, "inside", "a.txt"), []byte("bravo"), perm),
+				vfs.Remove(filepath.Join(root, "inside", "a.txt")),
+				vfs.MkdirAll(filepath.Join(root, "new"), perm),
+				vfs.Chmod(filepath.Join(root, "inside", "a.txt"), perm),
+				func() error {
+					_, err := vfs.OpenFile(filepath.Join(root, "inside", "a.txt"), os.O_WRONLY, 0)
+					return err
+				}(),
+			} {
+				Expect(QueryReadOnlyFileSystemError(err)).To(BeTrue(),
+					fmt.Sprintf("🔥 expected read only file system error, got: '%v'", err),
+				)
+			}
+
+			data, err := os.ReadFile(filepath.Join(root, "inside", "a.txt"))
+			Expect(err).To(Succeed())
+			Expect(string(data)).To(Equal("alpha"))
+		})
+	})
+})
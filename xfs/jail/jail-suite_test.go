@@ -0,0 +1,13 @@
+package jail_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+)
+
+func TestJail(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Jail Suite")
+}
@@ -0,0 +1,153 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/snivilised/extendio/xfs/nav"
+)
+
+// Capture traverses the tree rooted at the path specified and returns a
+// manifest describing each of the nodes encountered.
+func Capture(params *CaptureParams) (*Manifest, error) {
+	var (
+		result = &Manifest{
+			Name:    filepath.Base(params.Path),
+			Entries: []*Entry{},
+		}
+		failure error
+	)
+
+	optionsFn := func(o *nav.TraverseOptions) {
+		if params.OptionsFn != nil {
+			params.OptionsFn(o)
+		}
+
+		o.Store.Subscription = nav.SubscribeAny
+
+		if params.FS != nil {
+			o.Hooks.QueryStatus = nav.VirtualQueryStatusHookFn(params.FS)
+			o.Hooks.ReadDirectory = nav.VirtualReadEntriesHookFn(params.FS)
+		}
+
+		o.Callback = &nav.LabelledTraverseCallback{
+			Label: "manifest capture callback",
+			Fn: func(item *nav.TraverseItem) error {
+				if item.Error != nil {
+					return item.Error
+				}
+
+				if item.Path == params.Path {
+					return nil
+				}
+
+				entry, err := capture(params, item)
+				if err != nil {
+					failure = err
+
+					return err
+				}
+
+				result.Entries = append(result.Entries, entry)
+
+				return nil
+			},
+		}
+	}
+
+	_, err := nav.New().Primary(&nav.Prime{
+		Path:      params.Path,
+		OptionsFn: optionsFn,
+	}).Run()
+
+	if failure != nil {
+		return result, failure
+	}
+
+	return result, err
+}
+
+func capture(params *CaptureParams, item *nav.TraverseItem) (*Entry, error) {
+	info := item.Info
+
+	if info == nil {
+		var err error
+
+		if info, err = item.Entry.Info(); err != nil {
+			return nil, err
+		}
+	}
+
+	subPath, err := filepath.Rel(params.Path, item.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		SubPath: filepath.ToSlash(subPath),
+		Name:    info.Name(),
+		Type:    typeOf(info.Mode()),
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+	}
+
+	if params.Hash && entry.Type == EntryTypeFileEn {
+		if entry.Hash, err = digest(params, item.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	if entry.Type == EntryTypeSymlinkEn {
+		if entry.Target, err = target(params, item.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	return entry, nil
+}
+
+func typeOf(mode fs.FileMode) EntryTypeEnum {
+	switch {
+	case mode.IsDir():
+		return EntryTypeDirectoryEn
+	case mode.IsRegular():
+		return EntryTypeFileEn
+	case mode&fs.ModeSymlink != 0:
+		return EntryTypeSymlinkEn
+	}
+
+	return EntryTypeOtherEn
+}
+
+func digest(params *CaptureParams, path string) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if params.FS != nil {
+		data, err = params.FS.ReadFile(path)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func target(params *CaptureParams, path string) (string, error) {
+	if params.FS != nil {
+		return params.FS.ReadLink(path)
+	}
+
+	return os.Readlink(path)
+}
@@ -0,0 +1,116 @@
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/snivilised/extendio/xfs/fixture"
+)
+
+// Write serialises the manifest to the writer in the format specified.
+func (m *Manifest) Write(w io.Writer, format FormatEnum) error {
+	switch format {
+	case FormatJSONLinesEn:
+		return m.writeJSONLines(w)
+
+	case FormatXMLEn:
+		return m.writeXML(w)
+
+	case FormatUndefinedEn:
+	}
+
+	return fmt.Errorf("manifest: unsupported format (%v)", format)
+}
+
+// Read de-serialises a manifest from the reader in the format specified.
+// Since the JSON Lines form contains only the entries, the Name of a
+// manifest read from it is empty.
+func Read(r io.Reader, format FormatEnum) (*Manifest, error) {
+	switch format {
+	case FormatJSONLinesEn:
+		return readJSONLines(r)
+
+	case FormatXMLEn:
+		return readXML(r)
+
+	case FormatUndefinedEn:
+	}
+
+	return nil, fmt.Errorf("manifest: unsupported format (%v)", format)
+}
+
+func (m *Manifest) writeJSONLines(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	for _, entry := range m.Entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readJSONLines(r io.Reader) (*Manifest, error) {
+	result := &Manifest{
+		Entries: []*Entry{},
+	}
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" {
+			continue
+		}
+
+		entry := &Entry{}
+		if err := json.Unmarshal([]byte(text), entry); err != nil {
+			return nil, fmt.Errorf("manifest: invalid entry at line %v: %w", line, err)
+		}
+
+		result.Entries = append(result.Entries, entry)
+	}
+
+	return result, scanner.Err()
+}
+
+func (m *Manifest) writeXML(w io.Writer) error {
+	tree, err := m.Tree()
+	if err != nil {
+		return err
+	}
+
+	return fixture.Write(w, tree, fixture.FormatXMLEn)
+}
+
+func readXML(r io.Reader) (*Manifest, error) {
+	tree, err := fixture.Read(r, fixture.FormatXMLEn)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromTree(tree)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, text)
+}
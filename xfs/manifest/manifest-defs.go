@@ -0,0 +1,118 @@
+package manifest
+
+import (
+	"io/fs"
+	"time"
+
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// FormatEnum defines the serialised form of a manifest.
+type FormatEnum uint
+
+const (
+	FormatUndefinedEn FormatEnum = iota
+
+	// FormatJSONLinesEn, each entry is written as a JSON object on its own line
+	//
+	FormatJSONLinesEn
+
+	// FormatXMLEn, the entries are written as a nested tree of directory,
+	// file and symlink elements; the tree definition of the fixture package
+	// (see fixture.Tree), which is also the form of the musico test index.
+	//
+	FormatXMLEn
+)
+
+// EntryTypeEnum denotes the type of file system node an entry describes.
+type EntryTypeEnum string
+
+const (
+	EntryTypeDirectoryEn EntryTypeEnum = "directory"
+	EntryTypeFileEn      EntryTypeEnum = "file"
+	EntryTypeSymlinkEn   EntryTypeEnum = "symlink"
+	EntryTypeOtherEn     EntryTypeEnum = "other"
+)
+
+// Entry describes a single node in the tree.
+type Entry struct {
+	// SubPath is the path of the node relative to the root of the tree,
+	// always with a forward slash separator, so that manifests are portable.
+	//
+	SubPath string `json:"sub-path"`
+
+	// Name is the leaf name of the node
+	//
+	Name string `json:"name"`
+
+	// Type is the type of the node
+	//
+	Type EntryTypeEnum `json:"type"`
+
+	// Size is the size of the node in bytes
+	//
+	Size int64 `json:"size"`
+
+	// Mode contains the permission and mode bits of the node
+	//
+	Mode fs.FileMode `json:"mode"`
+
+	// ModTime is the modification time of the node
+	//
+	ModTime time.Time `json:"mtime"`
+
+	// Hash is the hex encoded sha256 of the content of a file; only present if
+	// requested at capture time.
+	//
+	Hash string `json:"hash,omitempty"`
+
+	// Target is the target of a symlink
+	//
+	Target string `json:"target,omitempty"`
+
+	// Content is optional inline content, written when the entry is
+	// materialised and padded with zero bytes up to Size. It is never
+	// captured, but allows manifests to be written by hand as test fixtures.
+	//
+	Content string `json:"content,omitempty"`
+}
+
+// IsDirectory returns true if the entry describes a directory
+func (e *Entry) IsDirectory() bool {
+	return e.Type == EntryTypeDirectoryEn
+}
+
+// Manifest is a portable description of the structure of a tree.
+type Manifest struct {
+	// Name is the name of the root directory of the tree
+	//
+	Name string
+
+	// Entries describes the nodes in the tree in traversal order, so that
+	// every directory precedes its contents. The root is not included.
+	//
+	Entries []*Entry
+}
+
+// CaptureParams defines the parameters required to capture a manifest.
+type CaptureParams struct {
+	// Path is the root of the tree to capture
+	//
+	Path string
+
+	// OptionsFn allows the client to customise the traversal, eg to define
+	// filters which restrict which nodes are captured. The Subscription and
+	// Callback are always overridden.
+	//
+	OptionsFn nav.TraverseOptionFn
+
+	// Hash, when set, causes the content hash of each file to be captured
+	//
+	Hash bool
+
+	// FS is the file system the tree is read from. When not set, the native
+	// file system is used.
+	//
+	FS storage.ReadOnlyVirtualFS
+}
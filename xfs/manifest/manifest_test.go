@@ -0,0 +1,223 @@
+package manifest_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/manifest"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+var epoch = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+func writeTree(root string) {
+	files := map[string]string{
+		"a/one.txt":     "alpha",
+		"a/b/two.txt":   "bravo",
+		"a/b/three.bin": "charlie",
+		"four.md":       "delta",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(path), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o640)).To(Succeed())
+		Expect(os.Chtimes(path, epoch, epoch)).To(Succeed())
+	}
+
+	Expect(os.Symlink("one.txt", filepath.Join(root, "a", "link"))).To(Succeed())
+
+	for _, name := range []string{"a/b", "a"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		Expect(os.Chtimes(path, epoch, epoch)).To(Succeed())
+	}
+}
+
+func subPaths(m *manifest.Manifest) []string {
+	return lo.Map(m.Entries, func(entry *manifest.Entry, _ int) string {
+		return entry.SubPath
+	})
+}
+
+func expectSameEntries(actual, expected *manifest.Manifest, withTimes bool) {
+	Expect(actual.Entries).To(HaveLen(len(expected.Entries)))
+
+	for i, entry := range expected.Entries {
+		other := actual.Entries[i]
+
+		Expect(other.SubPath).To(Equal(entry.SubPath))
+		Expect(other.Name).To(Equal(entry.Name), entry.SubPath)
+		Expect(other.Type).To(Equal(entry.Type), entry.SubPath)
+		Expect(other.Mode).To(Equal(entry.Mode), entry.SubPath)
+		Expect(other.Hash).To(Equal(entry.Hash), entry.SubPath)
+		Expect(other.Target).To(Equal(entry.Target), entry.SubPath)
+
+		if entry.Type == manifest.EntryTypeSymlinkEn {
+			// the tree definition does not describe the size or modification
+			// time of a symlink
+			//
+			continue
+		}
+
+		if !entry.IsDirectory() {
+			Expect(other.Size).To(Equal(entry.Size), entry.SubPath)
+		}
+
+		if withTimes {
+			Expect(other.ModTime.Equal(entry.ModTime)).To(BeTrue(), entry.SubPath)
+		}
+	}
+}
+
+var _ = Describe("Manifest", Ordered, func() {
+	var root string
+
+	BeforeAll(func() {
+		root = filepath.Join(GinkgoT().TempDir(), "tree")
+		writeTree(root)
+
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	Context("Capture", func() {
+		It("🧪 should: describe each node in traversal order", func() {
+			captured, err := manifest.Capture(&manifest.CaptureParams{
+				Path: root,
+				Hash: true,
+			})
+
+			Expect(err).To(BeNil())
+			Expect(captured.Name).To(Equal("tree"))
+			Expect(subPaths(captured)).To(Equal([]string{
+				"a", "a/b", "a/b/three.bin", "a/b/two.txt", "a/link", "a/one.txt", "four.md",
+			}))
+
+			link := captured.Entries[4]
+			Expect(link.Type).To(Equal(manifest.EntryTypeSymlinkEn))
+			Expect(link.Target).To(Equal("one.txt"))
+
+			one := captured.Entries[5]
+			Expect(one.Type).To(Equal(manifest.EntryTypeFileEn))
+			Expect(one.Size).To(Equal(int64(len("alpha"))))
+			Expect(one.ModTime.Equal(epoch)).To(BeTrue())
+			Expect(one.Hash).To(Equal(
+				"8ed3f6ad685b959ead7022518e1af76cd816f8e8ec7ccdda1ed4018e8f2223f8",
+			))
+			Expect(captured.Entries[0].Type).To(Equal(manifest.EntryTypeDirectoryEn))
+			Expect(captured.Entries[0].Hash).To(BeEmpty())
+		})
+	})
+
+	DescribeTable("round trip",
+		func(format manifest.FormatEnum) {
+			captured, err := manifest.Capture(&manifest.CaptureParams{
+				Path: root,
+				Hash: true,
+			})
+			Expect(err).To(BeNil())
+
+			var buffer bytes.Buffer
+			Expect(captured.Write(&buffer, format)).To(Succeed())
+
+			restored, err := manifest.Read(&buffer, format)
+			Expect(err).To(BeNil())
+			expectSameEntries(restored, captured, true)
+		},
+		Entry("🧪 json lines", manifest.FormatJSONLinesEn),
+		Entry("🧪 xml", manifest.FormatXMLEn),
+	)
+
+	Context("Materialise", func() {
+		It("🧪 should: reproduce captured tree in memory", func() {
+			captured, err := manifest.Capture(&manifest.CaptureParams{
+				Path: root,
+				Hash: true,
+			})
+			Expect(err).To(BeNil())
+
+			vfs := storage.UseMemFS()
+			Expect(captured.Materialise(vfs, root)).To(Succeed())
+
+			reproduced, err := manifest.Capture(&manifest.CaptureParams{
+				Path: root,
+				Hash: true,
+				FS:   vfs,
+			})
+			Expect(err).To(BeNil())
+			Expect(subPaths(reproduced)).To(Equal(subPaths(captured)))
+
+			for i, entry := range captured.Entries {
+				if entry.Type == manifest.EntryTypeFileEn {
+					Expect(reproduced.Entries[i].Size).To(Equal(entry.Size), entry.SubPath)
+					Expect(reproduced.Entries[i].Mode.Perm()).To(Equal(entry.Mode.Perm()), entry.SubPath)
+				}
+			}
+		})
+
+		It("🧪 should: restore symlinks and modification times", func() {
+			captured, err := manifest.Capture(&manifest.CaptureParams{
+				Path: root,
+			})
+			Expect(err).To(BeNil())
+
+			vfs := storage.UseMemFS()
+			Expect(captured.Materialise(vfs, root)).To(Succeed())
+
+			target, err := vfs.ReadLink(filepath.Join(root, "a", "link"))
+			Expect(err).To(BeNil())
+			Expect(target).To(Equal("one.txt"))
+
+			for _, name := range []string{"a", "a/b", "a/one.txt", "a/b/two.txt"} {
+				info, err := vfs.Lstat(filepath.Join(root, filepath.FromSlash(name)))
+				Expect(err).To(BeNil())
+				Expect(info.ModTime().Equal(epoch)).To(BeTrue(), name)
+			}
+		})
+
+		It("🧪 should: build tree from musico index", func() {
+			data, err := os.ReadFile(helpers.Path(helpers.Repo("../.."), "Test/data/musico-index.xml"))
+			Expect(err).To(BeNil())
+
+			index, err := manifest.Read(bytes.NewReader(data), manifest.FormatXMLEn)
+			Expect(err).To(BeNil())
+			Expect(index.Name).To(Equal("MUSICO"))
+
+			vfs := storage.UseMemFS()
+			musico := filepath.Join(root, "..", index.Name)
+			Expect(index.Materialise(vfs, musico)).To(Succeed())
+
+			reproduced, err := manifest.Capture(&manifest.CaptureParams{
+				Path: musico,
+				FS:   vfs,
+			})
+			Expect(err).To(BeNil())
+			Expect(subPaths(reproduced)).To(ConsistOf(subPaths(index)))
+			Expect(vfs.FileExists(
+				filepath.Join(musico, "bass", "DUB", "Dreadzone", "Second Light", "02 - Little Britain.flac"),
+			)).To(BeTrue())
+		})
+	})
+
+	When("format is not supported", func() {
+		It("🧪 should: return error", func() {
+			var buffer bytes.Buffer
+
+			Expect((&manifest.Manifest{}).Write(&buffer, manifest.FormatUndefinedEn)).NotTo(Succeed())
+
+			_, err := manifest.Read(&buffer, manifest.FormatUndefinedEn)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
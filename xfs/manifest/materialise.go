@@ -0,0 +1,22 @@
+package manifest
+
+import (
+	"github.com/snivilised/extendio/xfs/fixture"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// Materialise creates the tree described by the manifest on the virtual file
+// system provided, under the root path specified. Files are written with
+// their inline Content if present, padded with zero bytes up to Size. The
+// permission bits and modification times of each node are applied and
+// symlinks are created with their targets. Entries that are neither
+// directories, files nor symlinks (eg devices) are skipped, since they can't
+// be created via the virtual file system.
+func (m *Manifest) Materialise(vfs storage.VirtualFS, root string) error {
+	tree, err := m.Tree()
+	if err != nil {
+		return err
+	}
+
+	return fixture.Build(vfs, root, tree)
+}
@@ -0,0 +1,189 @@
+package manifest
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/snivilised/extendio/xfs/fixture"
+)
+
+// Tree returns the manifest as a fixture tree definition, which is the
+// form in which it is written as XML and from which it is materialised.
+// Entries that are neither directories, files nor symlinks (eg devices)
+// can't be defined in a tree, so they are omitted.
+func (m *Manifest) Tree() (*fixture.Tree, error) {
+	children := map[string][]*Entry{}
+	directories := map[string]bool{
+		".": true,
+	}
+
+	for _, entry := range m.Entries {
+		parent := path.Dir(entry.SubPath)
+
+		if !directories[parent] {
+			return nil, fmt.Errorf("manifest: entry '%v' precedes its parent directory", entry.SubPath)
+		}
+
+		if entry.IsDirectory() {
+			directories[entry.SubPath] = true
+		}
+
+		children[parent] = append(children[parent], entry)
+	}
+
+	return &fixture.Tree{
+		Root: directoryOf(&Entry{Name: m.Name, SubPath: "."}, children),
+	}, nil
+}
+
+func directoryOf(entry *Entry, children map[string][]*Entry) fixture.Directory {
+	directory := fixture.Directory{
+		Name:    entry.Name,
+		Mode:    formatMode(entry.Mode),
+		ModTime: formatTime(entry.ModTime),
+	}
+
+	for _, child := range children[entry.SubPath] {
+		switch child.Type {
+		case EntryTypeDirectoryEn:
+			directory.Directories = append(directory.Directories, directoryOf(child, children))
+
+		case EntryTypeFileEn:
+			directory.Files = append(directory.Files, fixture.File{
+				Name:    child.Name,
+				Size:    child.Size,
+				Mode:    formatMode(child.Mode),
+				ModTime: formatTime(child.ModTime),
+				Hash:    child.Hash,
+				Text:    child.Content,
+			})
+
+		case EntryTypeSymlinkEn:
+			directory.Symlinks = append(directory.Symlinks, fixture.Symlink{
+				Name:   child.Name,
+				Target: child.Target,
+			})
+
+		case EntryTypeOtherEn:
+		}
+	}
+
+	return directory
+}
+
+// FromTree creates a manifest from a fixture tree definition. The entries
+// are in traversal order; so within each directory, its sub-directories
+// precede its files and symlinks, which are ordered by name, ignoring case.
+func FromTree(tree *fixture.Tree) (*Manifest, error) {
+	result := &Manifest{
+		Name:    tree.Root.Name,
+		Entries: []*Entry{},
+	}
+
+	if err := result.flatten(&tree.Root, ""); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (m *Manifest) flatten(directory *fixture.Directory, parent string) error {
+	for i := range directory.Directories {
+		d := &directory.Directories[i]
+		subPath := path.Join(parent, d.Name)
+
+		entry, err := entryOf(subPath, EntryTypeDirectoryEn, d.Mode, d.ModTime)
+		if err != nil {
+			return err
+		}
+
+		m.Entries = append(m.Entries, entry)
+
+		if err := m.flatten(d, subPath); err != nil {
+			return err
+		}
+	}
+
+	entries := make([]*Entry, 0, len(directory.Files)+len(directory.Symlinks))
+
+	for i := range directory.Files {
+		f := &directory.Files[i]
+
+		entry, err := entryOf(path.Join(parent, f.Name), EntryTypeFileEn, f.Mode, f.ModTime)
+		if err != nil {
+			return err
+		}
+
+		entry.Size = f.Size
+		entry.Hash = f.Hash
+		entry.Content = f.Text
+		entries = append(entries, entry)
+	}
+
+	for _, link := range directory.Symlinks {
+		entry, _ := entryOf(path.Join(parent, link.Name), EntryTypeSymlinkEn, "", "")
+		entry.Mode = fs.ModeSymlink | fs.ModePerm
+		entry.Target = link.Target
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+
+	m.Entries = append(m.Entries, entries...)
+
+	return nil
+}
+
+func entryOf(subPath string, entryType EntryTypeEnum, mode, modTime string) (*Entry, error) {
+	entry := &Entry{
+		SubPath: subPath,
+		Name:    path.Base(subPath),
+		Type:    entryType,
+	}
+
+	perm, err := parseMode(mode)
+	if err != nil {
+		return nil, fmt.Errorf("manifest: '%v': %w", subPath, err)
+	}
+
+	if entry.ModTime, err = parseTime(modTime); err != nil {
+		return nil, fmt.Errorf("manifest: '%v': invalid mtime: %w", subPath, err)
+	}
+
+	entry.Mode = perm
+
+	if entryType == EntryTypeDirectoryEn {
+		entry.Mode |= fs.ModeDir
+	}
+
+	return entry, nil
+}
+
+// formatMode returns the permission bits as an octal string, as defined by
+// the fixture package; the type bits are implied by the element.
+func formatMode(mode fs.FileMode) string {
+	if mode.Perm() == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%04o", mode.Perm())
+}
+
+func parseMode(mode string) (fs.FileMode, error) {
+	if mode == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value > uint64(fs.ModePerm) {
+		return 0, fmt.Errorf("invalid mode '%v'", mode)
+	}
+
+	return fs.FileMode(value), nil
+}
@@ -48,6 +48,10 @@ func (b *bootstrapper) initNotifiers() {
 		b.o.Notify.OnStop = func(_ string) {}
 	}
 
+	if b.o.Notify.OnRemoved == nil {
+		b.o.Notify.OnRemoved = func(_ *RemovedItem) {}
+	}
+
 	b.nc.frame.notifiers.init(&b.o.Notify)
 }
 
@@ -100,6 +104,7 @@ func (b *bootstrapper) initResume(ps *persistState) {
 	}
 
 	b.nc.frame.metrics.load(ps.Active)
+	b.nc.frame.completed = newCompletedItems(ps.Active)
 	b.rc.strategy.init(strategyParams)
 	b.detacher = b.rc
 }
@@ -17,6 +17,12 @@ const (
 
 	// DirectoryContentsOrderFilesFirstEn invoke files first
 	DirectoryContentsOrderFilesFirstEn
+
+	// DirectoryContentsOrderInterleavedEn invoke files and folders in a single
+	// sequence, merged together by the sort hook (Options.Hooks.Sort), so that
+	// a folder's entries are visited in the same order as a directory listing
+	// that does not distinguish between files and folders.
+	DirectoryContentsOrderInterleavedEn
 )
 
 type newDirectoryContentsParams struct {
@@ -37,35 +43,60 @@ func newDirectoryContents(params *newDirectoryContentsParams) *DirectoryContents
 // DirectoryContents represents the contents of a directory's contents and
 // handles sorting order which by default is different between various
 // operating systems. This abstraction removes the differences in sorting
-// behaviour on different platforms.
+// behaviour on different platforms. Given the same directory contents and the
+// same sort hook, the order in which entries are visited is guaranteed to be
+// identical regardless of the backend the entries were read from, be that
+// the native file system on Linux or macOS, or an in-memory file system. This
+// is because the order returned by the underlying ReadDir is never relied
+// upon; the default sort hooks impose a total order on entry names.
 type DirectoryContents struct {
 	Options *TraverseOptions
 	Folders []fs.DirEntry
 	Files   []fs.DirEntry
+
+	// streamed denotes that the entries have not been read up front, but are
+	// read in batches as they are navigated (see StreamingOptions), in which
+	// case Folders and Files are empty.
+	streamed bool
 }
 
 // All returns the contents of a directory respecting the directory sorting
-// order defined in the traversal options.
+// order defined in the traversal options. The result is always a new slice,
+// so that it does not share the backing array of Folders or Files, which
+// are re-used when the contents are recycled.
 func (e *DirectoryContents) All() []fs.DirEntry {
-	result := []fs.DirEntry{}
+	result := make([]fs.DirEntry, 0, len(e.Folders)+len(e.Files))
 
 	switch e.Options.Store.Behaviours.Sort.DirectoryEntryOrder {
 	case DirectoryContentsOrderFoldersFirstEn:
-		result = append(e.Folders, e.Files...) //nolint:gocritic // no alternative known
+		result = append(result, e.Folders...)
+		result = append(result, e.Files...)
 	case DirectoryContentsOrderFilesFirstEn:
-		result = append(e.Files, e.Folders...) //nolint:gocritic // no alternative known
+		result = append(result, e.Files...)
+		result = append(result, e.Folders...)
+	case DirectoryContentsOrderInterleavedEn:
+		result = append(result, e.Folders...)
+		result = append(result, e.Files...)
+		e.sort(result)
 	}
 
 	return result
 }
 
 func (e *DirectoryContents) arrange(entries []fs.DirEntry) {
-	grouped := lo.GroupBy(entries, func(entry fs.DirEntry) bool {
-		return entry.IsDir()
-	})
-
-	e.Folders = grouped[true]
-	e.Files = grouped[false]
+	// the entries are appended to the existing slices, so that the capacity
+	// of recycled contents is re-used
+	//
+	e.Folders = e.Folders[:0]
+	e.Files = e.Files[:0]
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			e.Folders = append(e.Folders, entry)
+		} else {
+			e.Files = append(e.Files, entry)
+		}
+	}
 
 	if e.Folders == nil {
 		e.Folders = []fs.DirEntry{}
@@ -82,11 +113,6 @@ func (e *DirectoryContents) sort(entries []fs.DirEntry) {
 	}
 }
 
-func (e *DirectoryContents) clear() {
-	e.Files = []fs.DirEntry{}
-	e.Folders = []fs.DirEntry{}
-}
-
 func newEmptyDirectoryEntries(o *TraverseOptions, prealloc ...*EntryQuantities) *DirectoryContents {
 	return lo.TernaryF(len(prealloc) == 0,
 		func() *DirectoryContents {
@@ -64,3 +64,11 @@ func NewResumeControllerNotSetNativeError(from string) error {
 var ErrUndefinedSubscriptionType = errors.New(
 	"undefined subscription type; please set in traverse options (/Options.Store.Subscription)",
 )
+
+// ErrShardFailed indicates that a shard of a sharded traversal could not be
+// navigated, even after being retried.
+var ErrShardFailed = errors.New("shard failed")
+
+// ErrJobTransportFailed indicates that a job could not be executed because
+// its transport failed.
+var ErrJobTransportFailed = errors.New("job transport failed")
@@ -18,7 +18,10 @@ func DefaultExtendHookFn(navi *NavigationInfo, entries *DirectoryContents) {
 	)
 
 	if navi.Item.IsDirectory() {
-		isLeaf = len(entries.Folders) == 0
+		// the contents of a streamed directory are not known up front, so
+		// it can't be determined to be a leaf
+		//
+		isLeaf = !entries.streamed && len(entries.Folders) == 0
 		scope = navi.frame.periscope.scope(isLeaf)
 		scope |= ScopeFolderEn
 	} else {
@@ -35,17 +38,23 @@ func DefaultExtendHookFn(navi *NavigationInfo, entries *DirectoryContents) {
 		NodeScope: scope,
 	}
 
+	navi.Item.Extension.digests = navi.frame.digests(navi.Item)
+	navi.Item.Extension.SubPath = subPathOf(navi.Options, navi.frame.root.Get(), navi.Item)
+}
+
+// subPathOf derives the sub-path of the item using the sub-path hooks.
+func subPathOf(o *TraverseOptions, root string, item *TraverseItem) string {
 	spInfo := &SubPathInfo{
-		Root:      navi.frame.root.Get(),
-		Item:      navi.Item,
-		Behaviour: &navi.Options.Store.Behaviours.SubPath,
+		Root:      root,
+		Item:      item,
+		Behaviour: &o.Store.Behaviours.SubPath,
 	}
-	subpath := lo.TernaryF(navi.Item.IsDirectory(),
-		func() string { return navi.Options.Hooks.FolderSubPath(spInfo) },
-		func() string { return navi.Options.Hooks.FileSubPath(spInfo) },
+	subpath := lo.TernaryF(item.IsDirectory(),
+		func() string { return o.Hooks.FolderSubPath(spInfo) },
+		func() string { return o.Hooks.FileSubPath(spInfo) },
 	)
 
-	subpath = lo.TernaryF(navi.Options.Store.Behaviours.SubPath.KeepTrailingSep,
+	return lo.TernaryF(o.Store.Behaviours.SubPath.KeepTrailingSep,
 		func() string { return subpath },
 		func() string {
 			result := subpath
@@ -58,6 +67,4 @@ func DefaultExtendHookFn(navi *NavigationInfo, entries *DirectoryContents) {
 			return result
 		},
 	)
-
-	navi.Item.Extension.SubPath = subpath
 }
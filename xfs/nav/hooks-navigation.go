@@ -5,6 +5,8 @@ import (
 	"os"
 	"sort"
 	"strings"
+
+	"github.com/snivilised/extendio/xfs/storage"
 )
 
 // Lstat function signature that enables the default t be overridden
@@ -15,6 +17,19 @@ type QueryStatusHookFn func(path string) (fs.FileInfo, error)
 // by the client.
 type ReadDirectoryHookFn func(dirname string) ([]fs.DirEntry, error)
 
+// DirectoryStream is an open directory whose entries are read in batches.
+type DirectoryStream interface {
+	// ReadDir reads at most n entries; at the end of the directory, it
+	// returns no entries with io.EOF (see fs.ReadDirFile).
+	ReadDir(n int) ([]fs.DirEntry, error)
+	Close() error
+}
+
+// OpenDirectoryHookFn hook function to define how a directory is opened, for
+// its entries to be streamed (see StreamingOptions). A default implementation
+// is preset, so does not have to be set by the client.
+type OpenDirectoryHookFn func(dirname string) (DirectoryStream, error)
+
 // SortEntriesHookFn hook function to define how directory entries are sorted. Does not
 // have to be set explicitly. This will be set according to the IsCaseSensitive on
 // the TraverseOptions, but can be overridden if needed.
@@ -33,6 +48,7 @@ type SubPathHookFn func(info *SubPathInfo) string
 type TraverseHooks struct {
 	QueryStatus   QueryStatusHookFn
 	ReadDirectory ReadDirectoryHookFn
+	OpenDirectory OpenDirectoryHookFn
 	FolderSubPath SubPathHookFn
 	FileSubPath   SubPathHookFn
 	InitFilters   FilterInitHookFn
@@ -45,6 +61,14 @@ func LstatHookFn(path string) (fs.FileInfo, error) {
 	return os.Lstat(path)
 }
 
+// VirtualQueryStatusHookFn creates a Query Status hook function that is serviced
+// by the virtual file system provided, rather than the native file system.
+func VirtualQueryStatusHookFn(vfs storage.ReadOnlyVirtualFS) QueryStatusHookFn {
+	return func(path string) (fs.FileInfo, error) {
+		return vfs.Lstat(path)
+	}
+}
+
 // CaseSensitiveSortHookFn hook function for case sensitive directory traversal. A
 // directory of "a" will be visited after a sibling directory "B".
 func CaseSensitiveSortHookFn(entries []fs.DirEntry, _ ...any) error {
@@ -56,10 +80,18 @@ func CaseSensitiveSortHookFn(entries []fs.DirEntry, _ ...any) error {
 }
 
 // CaseInSensitiveSortHookFn hook function for case insensitive directory traversal. A
-// directory of "a" will be visited before a sibling directory "B".
+// directory of "a" will be visited before a sibling directory "B". Names that only
+// differ by case (eg "a" and "A") are ordered case sensitively, so that the
+// resultant order does not depend on the order in which entries were read.
 func CaseInSensitiveSortHookFn(entries []fs.DirEntry, _ ...any) error {
 	sort.Slice(entries, func(i, j int) bool {
-		return strings.ToLower(entries[i].Name()) < strings.ToLower(entries[j].Name())
+		lhs, rhs := strings.ToLower(entries[i].Name()), strings.ToLower(entries[j].Name())
+
+		if lhs == rhs {
+			return entries[i].Name() < entries[j].Name()
+		}
+
+		return lhs < rhs
 	})
 
 	return nil
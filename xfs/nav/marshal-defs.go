@@ -36,6 +36,12 @@ type ActiveState struct {
 	NodePath string
 	Depth    int
 	Metrics  *MetricCollection
+
+	// Completed contains the items beyond the node path, that had already
+	// been completed by the workers of an accelerated traversal, when it
+	// was interrupted.
+	//
+	Completed []string
 }
 
 type persistState struct {
@@ -43,6 +49,21 @@ type persistState struct {
 	Active *ActiveState
 }
 
+// IndexEntry records the state of an item, at the time it was visited
+// during an incremental traversal. The index is designed to be compact, as
+// it contains an entry for every item in the tree.
+type IndexEntry struct {
+	Size        int64  `json:"s"`
+	ModTime     int64  `json:"m"`
+	Inode       uint64 `json:"i,omitempty"`
+	IsDirectory bool   `json:"d,omitempty"`
+}
+
+type persistIndex struct {
+	Root    string                 `json:"root"`
+	Entries map[string]*IndexEntry `json:"entries"`
+}
+
 type stateMarshaller interface {
 	marshal(path string) error
 	unmarshal(path string) error
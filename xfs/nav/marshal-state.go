@@ -52,14 +52,40 @@ func (m *stateMarshallerJSON) validate() {
 	}
 }
 
-func writeBytes(bytes []byte, path string) error {
-	if file, err := os.Create(path); err == nil {
-		defer file.Close()
+type indexMarshallerJSON struct {
+	index *persistIndex
+}
+
+func (m *indexMarshallerJSON) marshal(path string) error {
+	// the index is not indented, because it can be large
+	//
+	bytes, err := json.Marshal(m.index)
+
+	if err == nil {
+		return writeBytes(bytes, path)
+	}
+
+	return err
+}
 
-		_, e := file.Write(bytes)
+func (m *indexMarshallerJSON) unmarshal(path string) error {
+	bytes, err := os.ReadFile(path)
+
+	if err == nil {
+		err = json.Unmarshal(bytes, m.index)
+	}
+
+	return err
+}
 
-		return e
+func writeBytes(bytes []byte, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	return nil
+	_, err = file.Write(bytes)
+
+	return err
 }
@@ -2,6 +2,7 @@ package nav
 
 import (
 	"errors"
+	"io"
 	"io/fs"
 	"path/filepath"
 
@@ -15,6 +16,8 @@ type newAgentParams struct {
 	o                    *TraverseOptions
 	handler              fileSystemErrorHandler
 	samplingFilterActive bool
+	streaming            bool
+	recycling            bool
 }
 
 func newAgent(params *newAgentParams) *navigationAgent {
@@ -24,6 +27,11 @@ func newAgent(params *newAgentParams) *navigationAgent {
 		handler:              params.handler,
 		cache:                make(inspectCache),
 		samplingFilterActive: params.samplingFilterActive,
+		streaming:            params.streaming,
+	}
+
+	if params.recycling {
+		instance.recycler = newRecycler()
 	}
 
 	return &instance
@@ -35,6 +43,8 @@ type navigationAgent struct {
 	handler              fileSystemErrorHandler
 	cache                inspectCache
 	samplingFilterActive bool
+	streaming            bool
+	recycler             *recycler // only present when pooling
 }
 
 type agentTopParams struct {
@@ -83,17 +93,47 @@ func (a *navigationAgent) top(params *agentTopParams) (*TraverseResult, error) {
 
 func (a *navigationAgent) read(
 	path string,
+	frame *navigationFrame,
 ) (*DirectoryContents, error) {
 	// this method was spun out from notify, as there needs to be a separation
 	// between these pieces of functionality to support 'extension'; ie we
 	// need to read the contents of an items contents to determine the properties
 	// created for the extension.
 	//
-	entries, err := a.o.Hooks.ReadDirectory(path)
-	de := newDirectoryContents(&newDirectoryContentsParams{
-		o:       a.o,
-		entries: entries,
-	})
+	var (
+		entries []fs.DirEntry
+		err     error
+	)
+
+	if a.streaming {
+		// the entries are read in batches, as they are navigated (see stream)
+		//
+		de := a.contents(nil)
+		de.streamed = true
+
+		return de, nil
+	}
+
+	if frame.readAhead != nil {
+		entries, err = frame.readAhead.read(path)
+	} else {
+		entries, err = a.o.Hooks.ReadDirectory(path)
+	}
+
+	de := a.contents(entries)
+
+	if err == nil && frame.incremental != nil {
+		frame.incremental.listed(path, de)
+	}
+
+	if err == nil && frame.readAhead != nil &&
+		frame.periscope.deeper(a.o.Store.Behaviours.Cascade.Depth) {
+		// sorted, so that the sub-directories are read in the order they
+		// are navigated
+		//
+		de.sort(de.Folders)
+		frame.readAhead.ahead(path, de.Folders)
+	}
 
 	return de, err
 }
@@ -131,81 +171,225 @@ func (a *navigationAgent) notify(params *agentNotifyParams) (SkipTraversal, erro
 }
 
 type agentTraverseParams struct {
-	impl    navigatorImpl
-	entries []fs.DirEntry
-	parent  *TraverseItem
-	frame   *navigationFrame
+	impl     navigatorImpl
+	entries  []fs.DirEntry
+	parent   *TraverseItem
+	frame    *navigationFrame
+	streamed bool
 }
 
 var dontSkipTraverseItem *TraverseItem
 
 func (a *navigationAgent) traverse(params *agentTraverseParams) (*TraverseItem, error) {
-	for _, entry := range params.entries {
-		path := filepath.Join(params.parent.Path, entry.Name())
-		info, e := entry.Info()
-
-		var current *TraverseItem
-
-		if a.samplingFilterActive {
-			inspection, found := a.cache[path]
-			current = lo.TernaryF(found,
-				func() *TraverseItem {
-					return inspection.current
-				},
-				func() *TraverseItem {
-					return nil
-				},
-			)
+	if params.streamed {
+		return a.stream(params)
+	}
+
+	if params.frame.readAhead != nil {
+		defer params.frame.readAhead.discard(params.parent.Path)
+	}
+
+	sequence := params.frame.sequence(params.parent.Path, params.entries)
+
+	for i := range params.entries {
+		if skipItem, done, err := a.step(params, sequence.at(i)); done {
+			return skipItem, err
 		}
+	}
+
+	return dontSkipTraverseItem, nil
+}
+
+// stream navigates the entries of the parent as they are read, a batch
+// at a time, so that the entries of a huge directory are never all held
+// in memory. Each batch is sorted on its own.
+func (a *navigationAgent) stream(params *agentTraverseParams) (*TraverseItem, error) {
+	reader, err := a.o.Hooks.OpenDirectory(params.parent.Path)
+	if err != nil {
+		return a.unreadable(params, err)
+	}
+
+	defer reader.Close()
+
+	for {
+		entries, err := reader.ReadDir(int(a.o.Store.Streaming.BatchSize))
+
+		if len(entries) > 0 {
+			batch := a.contents(withoutSystemEntries(entries))
+			batch.sort(batch.Files)
+			batch.sort(batch.Folders)
 
-		if current == nil {
-			current = newTraverseItem(
-				path,
-				entry,
-				info,
-				params.parent,
-				e,
+			visit := lo.TernaryF(a.o.Store.Subscription == SubscribeFolders,
+				func() []fs.DirEntry { return batch.Folders },
+				func() []fs.DirEntry { return batch.All() },
 			)
-		}
 
-		if skipItem, err := params.impl.traverse(&traverseParams{
-			current: current,
-			frame:   params.frame,
-		}); skipItem == dontSkipTraverseItem {
-			if err != nil {
-				if errors.Is(err, fs.SkipDir) {
-					// The returning of the parent traverse item by the child, denotes
-					// a skip; params.parent is the skipItem. So when a child item
-					// returns a SkipDir error and return's it parent item, what we're
-					// saying is that we want to skip processing all successive siblings
-					// but continue traversal. The skipItem indicates we're skipping
-					// the remaining processing of all of the parent item's remaining children.
-					// (see the ✨ below ...)
-					//
-					return params.parent, err
-				}
+			skipItem, done, se := a.steps(params, visit)
+			a.releaseContents(batch)
 
-				return dontSkipTraverseItem, err
+			if done {
+				return skipItem, se
 			}
-		} else if err != nil {
-			// ✨ ... we skip processing all the remaining children for
-			// this item, but still continue the overall traversal.
-			//
-			switch {
-			case errors.Is(err, fs.SkipDir):
-				continue
-			case errors.Is(err, fs.SkipAll):
-				break
-			default:
-				return dontSkipTraverseItem, err
+		}
+
+		if errors.Is(err, io.EOF) {
+			return dontSkipTraverseItem, nil
+		}
+
+		if err != nil {
+			return a.unreadable(params, err)
+		}
+	}
+}
+
+// steps navigates the entries of a batch.
+func (a *navigationAgent) steps(params *agentTraverseParams, entries []fs.DirEntry) (*TraverseItem, bool, error) {
+	for _, entry := range entries {
+		if skipItem, done, err := a.step(params, entry); done {
+			return skipItem, done, err
+		}
+	}
+
+	return nil, false, nil
+}
+
+// unreadable reports the failure to read the contents of the parent while
+// streaming, in the same way as when the contents are read up front.
+func (a *navigationAgent) unreadable(params *agentTraverseParams, readErr error) (*TraverseItem, error) {
+	_, err := a.notify(&agentNotifyParams{
+		frame:   params.frame,
+		current: params.parent,
+		readErr: readErr,
+	})
+
+	return dontSkipTraverseItem, err
+}
+
+// step navigates a single entry of the parent; done indicates that the
+// remaining entries should not be navigated, in which case the skip item
+// and error are to be returned.
+func (a *navigationAgent) step(params *agentTraverseParams, entry fs.DirEntry) (*TraverseItem, bool, error) {
+	path := filepath.Join(params.parent.Path, entry.Name())
+	info, e := entry.Info()
+
+	var current *TraverseItem
+
+	if a.samplingFilterActive {
+		inspection, found := a.cache[path]
+		current = lo.TernaryF(found,
+			func() *TraverseItem {
+				return inspection.current
+			},
+			func() *TraverseItem {
+				return nil
+			},
+		)
+	}
+
+	if current == nil {
+		current = a.item(
+			path,
+			entry,
+			info,
+			params.parent,
+			e,
+		)
+
+		defer a.releaseItem(current)
+	}
+
+	if skipItem, err := params.impl.traverse(&traverseParams{
+		current: current,
+		frame:   params.frame,
+	}); skipItem == dontSkipTraverseItem {
+		if err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				// The returning of the parent traverse item by the child, denotes
+				// a skip; params.parent is the skipItem. So when a child item
+				// returns a SkipDir error and return's it parent item, what we're
+				// saying is that we want to skip processing all successive siblings
+				// but continue traversal. The skipItem indicates we're skipping
+				// the remaining processing of all of the parent item's remaining children.
+				// (see the ✨ below ...)
+				//
+				return params.parent, true, err
 			}
+
+			return dontSkipTraverseItem, true, err
+		}
+	} else if err != nil {
+		// ✨ ... we skip processing all the remaining children for
+		// this item, but still continue the overall traversal.
+		//
+		switch {
+		case errors.Is(err, fs.SkipDir):
+			return nil, false, nil
+		case errors.Is(err, fs.SkipAll):
+			break
+		default:
+			return dontSkipTraverseItem, true, err
 		}
 	}
 
-	return dontSkipTraverseItem, nil
+	return nil, false, nil
 }
 
 func (a *navigationAgent) keep(stash *inspection) {
 	a.cache[stash.current.key()] = stash
 	stash.current.filtered()
 }
+
+// item creates the item for an entry, which is recycled when pooling.
+func (a *navigationAgent) item(
+	path string, entry fs.DirEntry, info fs.FileInfo, parent *TraverseItem, err error,
+) *TraverseItem {
+	if a.recycler != nil {
+		return a.recycler.item(path, entry, info, parent, err)
+	}
+
+	return newTraverseItem(path, entry, info, parent, err)
+}
+
+func (a *navigationAgent) releaseItem(item *TraverseItem) {
+	if a.recycler != nil {
+		a.recycler.releaseItem(item)
+	}
+}
+
+// inspection creates the stash for the inspection of the current item,
+// which is recycled when pooling.
+func (a *navigationAgent) inspection(current *TraverseItem) *inspection {
+	if a.recycler != nil {
+		return a.recycler.inspection(current)
+	}
+
+	return &inspection{
+		current: current,
+		isDir:   current.IsDirectory(),
+	}
+}
+
+func (a *navigationAgent) releaseInspection(stash *inspection) {
+	if a.recycler != nil {
+		a.recycler.releaseInspection(stash)
+	}
+}
+
+// contents creates the contents of a directory from its entries, which
+// are recycled when pooling.
+func (a *navigationAgent) contents(entries []fs.DirEntry) *DirectoryContents {
+	if a.recycler != nil {
+		return a.recycler.directoryContents(a.o, entries)
+	}
+
+	return newDirectoryContents(&newDirectoryContentsParams{
+		o:       a.o,
+		entries: entries,
+	})
+}
+
+func (a *navigationAgent) releaseContents(contents *DirectoryContents) {
+	if a.recycler != nil {
+		a.recycler.releaseContents(contents)
+	}
+}
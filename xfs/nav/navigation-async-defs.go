@@ -8,6 +8,9 @@ type TraverseItemInput struct {
 	Item  *TraverseItem
 	Label string
 	Fn    TraverseCallback
+
+	top    string // the top level directory containing the item, when throttled
+	ticket int    // identifies the job to the in-flight tracker
 }
 type TraverseItemJob = boost.Job[TraverseItemInput]
 type TraverseItemJobStream = boost.JobStream[TraverseItemInput]
@@ -17,6 +20,11 @@ type TraverseItemJobStreamW = boost.JobStreamW[TraverseItemInput]
 type TraverseOutput struct {
 	Item  *TraverseItem
 	Error error
+
+	// SequenceNo is the position of the item in traversal order (only
+	// assigned when the output is ordered)
+	//
+	SequenceNo int
 }
 type TraverseItemOutput = boost.JobOutput[TraverseOutput]
 type TraverseItemOutputStream = boost.JobOutputStream[TraverseOutput]
@@ -38,4 +46,9 @@ type AsyncInfo struct {
 	// Consume & outputCh are optional.
 	//
 	JobsChanOut TraverseItemJobStream
+
+	sequencer *resequencer
+	throttle  *Throttle
+	launch    func() // starts the pool, on submission of the first job
+	inflight  *inflightTracker
 }
@@ -0,0 +1,91 @@
+package nav
+
+import (
+	"context"
+	"time"
+
+	"github.com/snivilised/lorax/boost"
+)
+
+const (
+	asyncNavigatorRoutineName = boost.GoRoutineName("🧭 async navigator")
+	asyncSessionRoutineName   = boost.GoRoutineName("🧭 async session")
+
+	// DefaultAsyncOutputChTimeout is the time a worker waits for the client
+	// to receive a result from an async session, before the session is
+	// cancelled.
+	DefaultAsyncOutputChTimeout = time.Minute
+)
+
+// AsyncSession is a traversal running in the background with a worker
+// pool, created by NavigationRunner.RunAsync. The session owns the channels
+// and wait group required by the pool and closes them once the traversal
+// is complete.
+type AsyncSession struct {
+	results TraverseItemOutputStream
+	waitAQ  boost.WaitGroupAn
+	cancel  context.CancelFunc
+	done    chan struct{}
+	result  *TraverseResult
+	err     error
+}
+
+// Results returns the stream of outputs produced by the workers, which is
+// closed once all the jobs have completed.
+func (s *AsyncSession) Results() <-chan TraverseItemOutput {
+	return s.results
+}
+
+// Wait waits for the session to complete and returns the result of the
+// traversal. Any results that have not been received from Results are
+// discarded, so Wait should only be invoked once the client has finished
+// receiving them (or if the client is not interested in them at all).
+func (s *AsyncSession) Wait() (*TraverseResult, error) {
+	// the results not received by the client are discarded
+	//
+	for ok := true; ok; {
+		_, ok = <-s.results
+	}
+
+	<-s.done
+	s.waitAQ.Wait(asyncSessionRoutineName)
+	s.cancel()
+
+	return s.result, s.err
+}
+
+// Cancel requests the session to finish early; the result is still
+// retrieved via Wait.
+func (s *AsyncSession) Cancel() {
+	s.cancel()
+}
+
+// RunAsync starts the traversal in the background with a pool of the
+// number of workers specified and returns immediately. This is the
+// equivalent of using WithPool, NoW and Consume, without the client having
+// to create and manage the channels and wait group.
+func (r *runner) RunAsync(ctx context.Context, noWorkers int) *AsyncSession {
+	ctx, cancel := context.WithCancel(ctx)
+	waitAQ := boost.NewAnnotatedWaitGroup("🍂 async session")
+	session := &AsyncSession{
+		results: make(TraverseItemOutputStream, noWorkers),
+		waitAQ:  waitAQ,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	waitAQ.Add(1, asyncNavigatorRoutineName)
+	r.WithPool(&AsyncInfo{
+		NavigatorRoutineName: asyncNavigatorRoutineName,
+		WaitAQ:               waitAQ,
+		JobsChanOut:          make(TraverseItemJobStream, noWorkers),
+	}).NoW(noWorkers).Consume(session.results, DefaultAsyncOutputChTimeout)
+
+	go func() {
+		defer close(session.done)
+
+		session.result, session.err = r.Run(ctx, cancel)
+	}()
+
+	return session
+}
@@ -305,3 +305,74 @@ var _ = Describe("navigation", Ordered, func() {
 		}, SpecTimeout(time.Second*2)),
 	)
 })
+
+var _ = Describe("RunAsync", Ordered, func() {
+	var root string
+
+	BeforeAll(func() {
+		root = musico()
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	runner := func() nav.NavigationRunner {
+		return nav.New().Primary(&nav.Prime{
+			Path: helpers.Path(root, "RETRO-WAVE"),
+			OptionsFn: func(o *nav.TraverseOptions) {
+				o.Store.Subscription = nav.SubscribeAny
+				o.Callback = boostCallback("run async")
+			},
+		})
+	}
+
+	invoked := func(result *nav.TraverseResult) uint {
+		return result.Metrics.Count(nav.MetricNoFilesInvokedEn) +
+			result.Metrics.Count(nav.MetricNoFoldersInvokedEn)
+	}
+
+	When("results consumed", func() {
+		It("🧪 should: deliver an output for each item and complete", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			session := runner().RunAsync(ctx, 4)
+			count := uint(0)
+
+			for output := range session.Results() {
+				Expect(output.Payload.Error).To(Succeed())
+				count++
+			}
+
+			result, err := session.Wait()
+			Expect(err).To(Succeed())
+			Expect(count).To(Equal(invoked(result)))
+			Expect(count).To(BeNumerically(">", 0))
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("results not consumed", func() {
+		It("🧪 should: complete", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			result, err := runner().RunAsync(ctx, 2).Wait()
+			Expect(err).To(Succeed())
+			Expect(invoked(result)).To(BeNumerically(">", 0))
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("cancelled", func() {
+		It("🧪 should: complete", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			session := runner().RunAsync(ctx, 2)
+			session.Cancel()
+
+			_, _ = session.Wait()
+		}, SpecTimeout(time.Second*5))
+	})
+})
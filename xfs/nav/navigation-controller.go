@@ -5,30 +5,82 @@ import (
 	"log/slog"
 
 	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/hashing"
+	"github.com/snivilised/extendio/xfs/storage"
 	"github.com/snivilised/extendio/xfs/utils"
 )
 
 type navigationController struct {
-	impl  navigatorImpl
-	frame *navigationFrame
-	ns    *NavigationState
+	impl   navigatorImpl
+	frame  *navigationFrame
+	ns     *NavigationState
+	ctx    context.Context // only set when accelerated
+	result *TraverseResult
 }
 
 func (nc *navigationController) makeFrame() *navigationFrame {
 	o := nc.impl.options()
+	client := nc.terminal(o.Callback)
 	nc.frame = &navigationFrame{
 		root:        utils.VarProp[string]{},
 		currentPath: utils.VarProp[string]{},
-		client:      o.Callback,
-		raw:         o.Callback,
+		client:      client,
+		raw:         client,
 		notifiers:   notificationsSink{},
 		periscope:   &navigationPeriscope{},
 		metrics:     navigationMetricsFactory{}.new(),
 	}
 
+	if len(o.Store.Hashing.Algorithms) > 0 {
+		vfs := lo.TernaryF(o.Store.Hashing.VirtualFS == nil,
+			storage.UseNativeFS,
+			func() storage.VirtualFS { return o.Store.Hashing.VirtualFS },
+		)
+		nc.frame.hasher = hashing.New(vfs, &o.Store.Hashing)
+	}
+
+	if o.Store.Incremental.IndexPath != "" {
+		nc.frame.incremental = newIncrementalIndex(o, nc.frame, nc.logger())
+	}
+
+	if o.Store.ReadAhead.NoReaders > 1 {
+		nc.frame.readAhead = newDirectoryReader(o)
+	}
+
 	return nc.frame
 }
 
+// terminal creates the callback at the bottom of the decorator chain, which
+// invokes the client callback, unless the item is to be forwarded to the
+// executors of a transport; so that the decorators (eg the filter and the
+// listener) are always applied in process. The outcome is recorded in the
+// incremental index, which can only happen once the callback has returned,
+// which when accelerated, is on the pool worker.
+func (nc *navigationController) terminal(callback *LabelledTraverseCallback) *LabelledTraverseCallback {
+	if callback == nil {
+		return nil
+	}
+
+	return &LabelledTraverseCallback{
+		Label: callback.Label,
+		Fn: func(item *TraverseItem) error {
+			var err error
+
+			if item.forward != nil {
+				err = item.forward(item)
+			} else {
+				err = callback.Fn(item)
+			}
+
+			if nc.frame.incremental != nil {
+				nc.frame.incremental.visited(item, err)
+			}
+
+			return err
+		},
+	}
+}
+
 func (nc *navigationController) init() {
 	nc.ns = &NavigationState{
 		Filters: nc.frame.filters,
@@ -43,16 +95,39 @@ func (nc *navigationController) logger() *slog.Logger {
 }
 
 func (nc *navigationController) ensync(ctx context.Context, cancel context.CancelFunc, ai *AsyncInfo) {
+	nc.ctx = ctx
 	nc.impl.ensync(ctx, cancel, nc.frame, ai)
 }
 
+// abandon prevents the incremental index from being persisted, when the
+// traversal did not run to completion.
+func (nc *navigationController) abandon() {
+	if nc.frame != nil && nc.frame.incremental != nil {
+		nc.frame.incremental.abandon()
+	}
+}
+
 func (nc *navigationController) walk(root string) (*TraverseResult, error) {
 	nc.frame.root.Set(root)
 	nc.impl.logger().Info("walk", slog.String("root", root))
 
 	nc.frame.notifiers.begin.invoke(nc.ns)
 
+	w := nc.watcher(root)
 	result, err := nc.impl.top(nc.frame, root)
+	nc.result = result
+
+	if err != nil {
+		nc.abandon()
+	}
+
+	if w != nil {
+		if err == nil {
+			err = nc.watch(w)
+		}
+
+		_ = w.close()
+	}
 
 	fields := []any{}
 	for _, m := range result.Metrics.collection {
@@ -93,5 +168,43 @@ func (nc *navigationController) save(path string) error {
 }
 
 func (nc *navigationController) finish() error {
+	if err := nc.persist(); err != nil {
+		return err
+	}
+
 	return nc.impl.finish()
 }
+
+// drained is invoked once the worker pool has completed all of its jobs.
+// When accelerated, the client callback (and therefore any hashing it
+// performs) may still be running after the navigation has finished, so the
+// digests computed by the workers are only persisted at this point. Since
+// the result has already been returned to the client, the error is reported
+// through it (see TraverseResult.Err).
+func (nc *navigationController) drained() {
+	if err := nc.persist(); err != nil && nc.result != nil && nc.result.err == nil {
+		nc.result.err = err
+	}
+}
+
+func (nc *navigationController) persist() error {
+	if nc.frame == nil {
+		return nil
+	}
+
+	if nc.frame.incremental != nil {
+		if nc.ctx != nil && nc.ctx.Err() != nil {
+			nc.frame.incremental.abandon()
+		}
+
+		if err := nc.frame.incremental.save(); err != nil {
+			return err
+		}
+	}
+
+	if nc.frame.hasher != nil {
+		return nc.frame.hasher.Save()
+	}
+
+	return nil
+}
@@ -1,7 +1,10 @@
 package nav
 
 import (
+	"io/fs"
+
 	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/hashing"
 	"github.com/snivilised/extendio/xfs/utils"
 )
 
@@ -15,6 +18,11 @@ type navigationFrame struct {
 	notifiers   notificationsSink
 	periscope   *navigationPeriscope
 	metrics     *NavigationMetrics
+	hasher      *hashing.Service
+	incremental *incrementalIndex
+	readAhead   *directoryReader
+	inflight    *inflightTracker
+	completed   completedItems
 }
 
 // attach/decorate
@@ -49,11 +57,41 @@ func (f *navigationFrame) decorate(_ string, decorator *LabelledTraverseCallback
 	f.client = decorator
 }
 
+// digests binds a file item to the hashing service, if hashing is enabled
+func (f *navigationFrame) digests(item *TraverseItem) *itemDigests {
+	if f.hasher == nil || item.IsDirectory() {
+		return nil
+	}
+
+	return &itemDigests{
+		hasher: f.hasher,
+		path:   item.Path,
+		entry:  item.Entry,
+		info:   item.Info,
+	}
+}
+
+// sequence returns the sequence in which the entries of the parent are to
+// be navigated.
+func (f *navigationFrame) sequence(parent string, entries []fs.DirEntry) *entrySequence {
+	if f.readAhead == nil {
+		return &entrySequence{
+			entries: entries,
+		}
+	}
+
+	return f.readAhead.sequence(parent, entries)
+}
+
 func (f *navigationFrame) save(active *ActiveState) {
 	active.Root = f.root.Get()
 	active.NodePath = f.currentPath.Get()
 	active.Depth = f.periscope.depth()
 	f.metrics.save(active)
+
+	if f.inflight != nil {
+		f.inflight.save(active)
+	}
 }
 
 func (f *navigationFrame) collate() *TraverseResult {
@@ -91,6 +129,20 @@ func (f *navigationFrame) proxy(item *TraverseItem, compoundCounts *compoundCoun
 
 func (f *navigationFrame) invoke(item *TraverseItem, compoundCounts *compoundCounters) error {
 	f.currentPath.Set(item.Path)
+
+	if f.completed != nil && f.completed.skip(item) {
+		// already completed by the interrupted traversal being resumed
+		//
+		return nil
+	}
+
+	if f.incremental != nil && item.Error == nil &&
+		item.Extension.Event != WatchEventOverflowEn && !f.incremental.changed(item) {
+		// unchanged since the previous incremental run
+		//
+		return nil
+	}
+
 	err := f.client.Fn(item)
 	f.track(item, compoundCounts)
 
@@ -0,0 +1,268 @@
+package nav
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/utils"
+)
+
+// incrementalIndex tracks the items visited during an incremental traversal
+// and compares them against the index persisted by the previous run. The
+// new index starts out as a copy of the previous one, so that items which
+// are not visited in this run (eg because they are beyond the depth limit,
+// or inside a skipped folder) are retained. Entries are only dropped when
+// their parent folder is read and they are found to be missing.
+type incrementalIndex struct {
+	o         *TraverseOptions
+	frame     *navigationFrame
+	previous  *persistIndex
+	entries   map[string]*IndexEntry
+	prepared  bool
+	abandoned bool
+	mutex     sync.Mutex
+}
+
+func newIncrementalIndex(o *TraverseOptions, frame *navigationFrame, logger *slog.Logger) *incrementalIndex {
+	path := o.Store.Incremental.IndexPath
+	previous := &persistIndex{}
+
+	if err := (&marshallerFactory{}).index(o, previous).unmarshal(path); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Warn("discarding unreadable incremental index",
+				slog.String("path", path),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		previous = &persistIndex{}
+	}
+
+	return &incrementalIndex{
+		o:        o,
+		frame:    frame,
+		previous: previous,
+	}
+}
+
+// prepare binds the index to the root, which is not known until the
+// traversal starts. The previous index is disregarded, if it was created
+// for a different root.
+func (x *incrementalIndex) prepare() {
+	if x.prepared {
+		return
+	}
+
+	x.prepared = true
+
+	if x.previous.Root != x.frame.root.Get() {
+		x.previous.Entries = nil
+	}
+
+	x.entries = make(map[string]*IndexEntry, len(x.previous.Entries))
+
+	for key, entry := range x.previous.Entries {
+		x.entries[key] = entry
+	}
+}
+
+func (x *incrementalIndex) key(path string) string {
+	relative, err := filepath.Rel(x.frame.root.Get(), path)
+
+	return lo.Ternary(err == nil, relative, path)
+}
+
+func (x *incrementalIndex) entry(item *TraverseItem) (*IndexEntry, bool) {
+	info := item.Info
+
+	if utils.IsNil(info) && !utils.IsNil(item.Entry) {
+		info, _ = item.Entry.Info()
+	}
+
+	if utils.IsNil(info) {
+		return nil, false
+	}
+
+	return &IndexEntry{
+		Size:        info.Size(),
+		ModTime:     info.ModTime().UnixNano(),
+		Inode:       utils.Inode(info),
+		IsDirectory: info.IsDir(),
+	}, true
+}
+
+// changed returns true if the item is new, or differs from the state
+// recorded by the previous run. The state of the item is only recorded by
+// visited, once the client callback has succeeded.
+func (x *incrementalIndex) changed(item *TraverseItem) bool {
+	entry, ok := x.entry(item)
+	if !ok {
+		return true
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.prepare()
+
+	previous, found := x.previous.Entries[x.key(item.Path)]
+
+	return !found || *previous != *entry
+}
+
+// visited records the current state of the item, after the client callback
+// has been invoked for it. When the callback fails, the previous state (if
+// any) is retained, so that the item is invoked again by the next run; and
+// when unchanged folders are skipped, its ancestors are dropped, so that
+// the next run descends into them.
+func (x *incrementalIndex) visited(item *TraverseItem, err error) {
+	if item.Error != nil {
+		return
+	}
+
+	entry, ok := x.entry(item)
+	if !ok {
+		return
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.prepare()
+
+	key := x.key(item.Path)
+
+	if err == nil {
+		x.entries[key] = entry
+
+		return
+	}
+
+	if x.o.Store.Incremental.SkipUnchangedFolders {
+		for parent := filepath.Dir(key); parent != "." && parent != key; key, parent = parent, filepath.Dir(parent) {
+			delete(x.entries, parent)
+		}
+	}
+}
+
+// abandon prevents the index from being saved, because the traversal did
+// not run to completion (eg it failed, or was cancelled).
+func (x *incrementalIndex) abandon() {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.abandoned = true
+}
+
+// skip returns true if the item is a folder that should not be descended
+// into, because it is unchanged and unchanged folders are to be skipped.
+// The root is never skipped.
+func (x *incrementalIndex) skip(item *TraverseItem) bool {
+	if !x.o.Store.Incremental.SkipUnchangedFolders || !item.IsDirectory() {
+		return false
+	}
+
+	if item.Path == x.frame.root.Get() {
+		return false
+	}
+
+	return !x.changed(item)
+}
+
+// listed compares the contents of a folder that has just been read with its
+// children recorded in the index and reports those that have been removed,
+// along with their descendants.
+func (x *incrementalIndex) listed(path string, contents *DirectoryContents) {
+	names := make(map[string]bool, len(contents.Folders)+len(contents.Files))
+
+	for _, entries := range [][]fs.DirEntry{contents.Folders, contents.Files} {
+		for _, entry := range entries {
+			names[entry.Name()] = true
+		}
+	}
+
+	x.mutex.Lock()
+	x.prepare()
+
+	var (
+		parent    = x.key(path)
+		separator = string(filepath.Separator)
+		missing   = []string{}
+	)
+
+	for key := range x.entries {
+		if key != parent && filepath.Dir(key) == parent && !names[filepath.Base(key)] {
+			missing = append(missing, key)
+		}
+	}
+
+	removed := []*RemovedItem{}
+
+	for key, entry := range x.entries {
+		for _, gone := range missing {
+			if key == gone || strings.HasPrefix(key, gone+separator) {
+				removed = append(removed, &RemovedItem{
+					Path:        filepath.Join(x.frame.root.Get(), key),
+					SubPath:     key,
+					IsDirectory: entry.IsDirectory,
+				})
+
+				break
+			}
+		}
+	}
+
+	for _, item := range removed {
+		delete(x.entries, item.SubPath)
+	}
+
+	x.mutex.Unlock()
+
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].SubPath < removed[j].SubPath
+	})
+
+	for _, item := range removed {
+		x.frame.notifiers.removed.invoke(item)
+	}
+}
+
+// forget drops the item and its descendants from the index, once it is
+// known to have been deleted.
+func (x *incrementalIndex) forget(path string) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.prepare()
+
+	gone := x.key(path)
+	separator := string(filepath.Separator)
+
+	for key := range x.entries {
+		if key == gone || strings.HasPrefix(key, gone+separator) {
+			delete(x.entries, key)
+		}
+	}
+}
+
+// save persists the index, so that it can be used by the next run. Nothing
+// is saved if the traversal never started, or was abandoned.
+func (x *incrementalIndex) save() error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if !x.prepared || x.abandoned {
+		return nil
+	}
+
+	return (&marshallerFactory{}).index(x.o, &persistIndex{
+		Root:    x.frame.root.Get(),
+		Entries: x.entries,
+	}).marshal(x.o.Store.Incremental.IndexPath)
+}
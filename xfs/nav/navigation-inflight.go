@@ -0,0 +1,113 @@
+package nav
+
+import (
+	"sync"
+)
+
+// inflightTracker keeps track of the jobs dispatched to the worker pool of
+// an accelerated traversal. Because the navigator runs ahead of the workers,
+// the node path of the navigator does not denote the point up to which the
+// traversal is complete. Instead, the tracker maintains the low-water mark,
+// ie the earliest job (in traversal order) that has not yet completed,
+// along with the jobs after it that have.
+type inflightTracker struct {
+	mutex sync.Mutex
+	next  int
+	jobs  []*inflightJob // in dispatch order, starting at the low-water mark
+}
+
+type inflightJob struct {
+	ticket int
+	path   string
+	done   bool
+}
+
+// dispatch registers the job for the item and returns the ticket with
+// which its completion is reported.
+func (t *inflightTracker) dispatch(item *TraverseItem) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	ticket := t.next
+	t.next++
+	t.jobs = append(t.jobs, &inflightJob{
+		ticket: ticket,
+		path:   item.Path,
+	})
+
+	return ticket
+}
+
+// complete reports that the job with the ticket has been executed, which
+// may advance the low-water mark.
+func (t *inflightTracker) complete(ticket int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.jobs) == 0 {
+		return
+	}
+
+	// tickets are contiguous, so the position of the job is relative to
+	// the job at the low-water mark
+	//
+	if index := ticket - t.jobs[0].ticket; index >= 0 && index < len(t.jobs) {
+		t.jobs[index].done = true
+	}
+
+	for len(t.jobs) > 0 && t.jobs[0].done {
+		t.jobs[0] = nil
+		t.jobs = t.jobs[1:]
+	}
+}
+
+// save records the low-water mark as the node path, so that a resume
+// starts from the earliest job not completed, along with the items after
+// it that have already been completed, so that they are not re-delivered.
+func (t *inflightTracker) save(active *ActiveState) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.jobs) == 0 {
+		return
+	}
+
+	active.NodePath = t.jobs[0].path
+	active.Completed = []string{}
+
+	for _, job := range t.jobs {
+		if job.done {
+			active.Completed = append(active.Completed, job.path)
+		}
+	}
+}
+
+// completedItems contains the items of an interrupted accelerated
+// traversal that were completed beyond its low-water mark, which are
+// skipped when resumed.
+type completedItems map[string]struct{}
+
+func newCompletedItems(active *ActiveState) completedItems {
+	if len(active.Completed) == 0 {
+		return nil
+	}
+
+	completed := make(completedItems, len(active.Completed))
+	for _, path := range active.Completed {
+		completed[path] = struct{}{}
+	}
+
+	return completed
+}
+
+// skip indicates whether the item was completed by the interrupted
+// traversal; each item is only ever skipped once.
+func (c completedItems) skip(item *TraverseItem) bool {
+	if _, found := c[item.Path]; found {
+		delete(c, item.Path)
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,201 @@
+package nav_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/lorax/boost"
+)
+
+type inflightTE struct {
+	given    string
+	should   string
+	strategy nav.ResumeStrategyEnum
+}
+
+// inflightWaitGroup satisfies boost.AnnotatedWgAQ with a plain wait group,
+// because the annotations of boost.AnnotatedWaitGroup are not synchronised,
+// which would fail these specs when run with the race detector.
+type inflightWaitGroup struct {
+	sync.WaitGroup
+}
+
+func (wg *inflightWaitGroup) Add(delta int, _ ...boost.GoRoutineName) {
+	wg.WaitGroup.Add(delta)
+}
+
+func (wg *inflightWaitGroup) Done(_ ...boost.GoRoutineName) {
+	wg.WaitGroup.Done()
+}
+
+var _ = Describe("In-flight Resume", Ordered, func() {
+	var root string
+
+	BeforeAll(func() {
+		root = musico()
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	// run performs a pooled traversal with the runner and waits for all of
+	// its go routines to complete.
+	run := func(ctxSpec SpecContext, runner nav.NavigationRunner, after func()) {
+		ctx, cancel := context.WithCancel(ctxSpec)
+		defer cancel()
+
+		wg := &inflightWaitGroup{}
+		wg.Add(1, navigatorRoutineName)
+
+		runner.WithPool(&nav.AsyncInfo{
+			NavigatorRoutineName: navigatorRoutineName,
+			WaitAQ:               wg,
+			JobsChanOut:          make(nav.TraverseItemJobStream, DefaultJobsChSize),
+		}).NoW(3)
+
+		_, err := runner.Run(ctx, cancel)
+		Expect(err).To(Succeed())
+
+		if after != nil {
+			after()
+		}
+
+		wg.Wait()
+	}
+
+	DescribeTable("interrupted pool",
+		func(ctxSpec SpecContext, entry *inflightTE) {
+			defer leaktest.Check(GinkgoT())()
+
+			var (
+				mutex     sync.Mutex
+				completed []string
+				sequence  []string
+			)
+
+			path := helpers.Path(root, "RETRO-WAVE")
+			blocker := helpers.Path(root, ResumeAtTeenageColor)
+			statePath := filepath.Join(GinkgoT().TempDir(), "in-flight-state.json")
+			release := make(chan struct{})
+			settled := make(chan struct{})
+
+			_, err := nav.New().Primary(&nav.Prime{
+				Path: path,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.Subscription = nav.SubscribeAny
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "sequence callback",
+						Fn: func(item *nav.TraverseItem) error {
+							sequence = append(sequence, item.Path)
+
+							return nil
+						},
+					}
+				},
+			}).Run()
+			Expect(err).To(Succeed())
+
+			// the job for the blocker does not complete until the state has
+			// been saved, which only happens once all the other jobs have
+			//
+			primary := nav.New().Primary(&nav.Prime{
+				Path: path,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.Subscription = nav.SubscribeAny
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "blocking callback",
+						Fn: func(item *nav.TraverseItem) error {
+							if item.Path == blocker {
+								<-release
+
+								return nil
+							}
+
+							mutex.Lock()
+							defer mutex.Unlock()
+
+							completed = append(completed, item.Path)
+							if len(completed) == len(sequence)-1 {
+								close(settled)
+							}
+
+							return nil
+						},
+					}
+				},
+			})
+
+			run(ctxSpec, primary, func() {
+				<-settled
+				Expect(primary.Save(statePath)).To(Succeed())
+				close(release)
+			})
+
+			resumed := []string{}
+			resumption := nav.New().Resume(&nav.Resumption{
+				RestorePath: statePath,
+				Restorer: func(o *nav.TraverseOptions, active *nav.ActiveState) {
+					Expect(active.NodePath).To(Equal(blocker))
+					Expect(active.Completed).NotTo(BeEmpty())
+
+					// the primary session was not listening
+					//
+					active.Listen = nav.ListenDeaf
+
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "resumed callback",
+						Fn: func(item *nav.TraverseItem) error {
+							mutex.Lock()
+							resumed = append(resumed, item.Path)
+							mutex.Unlock()
+
+							return nil
+						},
+					}
+				},
+				Strategy: entry.strategy,
+			})
+
+			run(ctxSpec, resumption, nil)
+
+			// none of the items preceding the resume point can be delivered
+			// again, they all completed
+			//
+			at := lo.IndexOf(sequence, blocker)
+			Expect(at).To(BeNumerically(">", 0))
+			Expect(resumed).NotTo(ContainElement(BeElementOf(sequence[:at])))
+			Expect(resumed).To(Equal([]string{blocker}))
+		},
+		func(entry *inflightTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.given, entry.should)
+		},
+
+		Entry(nil, &inflightTE{
+			given:    "fastward resume",
+			should:   "only re-deliver the job that did not complete",
+			strategy: nav.ResumeStrategyFastwardEn,
+		}, SpecTimeout(time.Second*5)),
+
+		Entry(nil, &inflightTE{
+			given:    "spawn resume",
+			should:   "only re-deliver the job that did not complete",
+			strategy: nav.ResumeStrategySpawnEn,
+		}, SpecTimeout(time.Second*5)),
+	)
+})
@@ -48,6 +48,7 @@ type navigationListener struct {
 	current     *LabelledTraverseCallback
 	resumeStack *collections.Stack[*ListenTriggers]
 	triggers    *ListenTriggers
+	detacher    resumeDetacher
 }
 
 func (l *navigationListener) init() {
@@ -67,6 +68,7 @@ func (l *navigationListener) makeStates(params *listenStatesParams) {
 	// of the resume stack which is updated with the resume specific ListenTriggers and
 	// reverted at a later point via detach (resume stack pop).
 	//
+	l.detacher = params.detacher
 	l.states = navigationListeningStates{
 
 		// Just use the original unadulterated (filtered) client
@@ -79,24 +81,15 @@ func (l *navigationListener) makeStates(params *listenStatesParams) {
 			Fn: func(item *TraverseItem) error {
 				// fast forwarding to resume point
 				//
-				if params.frame.listener.triggers.Stop.IsMatch(item) {
-					if params.detacher != nil {
-						// detach performs state transition
-						//
-						params.detacher.detach(params.frame)
-
-						// NB: ok to call the client here without concern over causing an infinite
-						// loop because the detach has performed a state transition.
-						//
-						return params.frame.client.Fn(item)
-					}
-
-					panic(NewMissingListenDetacherFunctionNativeError("fastward"))
+				if params.frame.listener.fastward(params.frame, item) {
+					return nil
 				}
 
-				item.filteredOut = true
-
-				return nil
+				// NB: invoke the new state directly rather than frame.client, because
+				// when accelerated, frame.client is the boost decorator, which would
+				// submit the item as a job again.
+				//
+				return params.frame.listener.current.Fn(item)
 			},
 		},
 
@@ -147,6 +140,25 @@ func (l *navigationListener) makeStates(params *listenStatesParams) {
 	}
 }
 
+// fastward reports whether the item precedes the resume point, in which case
+// it is muted. When the item is the resume point, detach performs the state
+// transition, so the item must be passed on to the new state.
+func (l *navigationListener) fastward(frame *navigationFrame, item *TraverseItem) bool {
+	if !l.triggers.Stop.IsMatch(item) {
+		item.filteredOut = true
+
+		return true
+	}
+
+	if l.detacher == nil {
+		panic(NewMissingListenDetacherFunctionNativeError("fastward"))
+	}
+
+	l.detacher.detach(frame)
+
+	return false
+}
+
 func (l *navigationListener) transition(state ListeningState) {
 	l.state = state
 	l.current = l.states[state]
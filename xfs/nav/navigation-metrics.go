@@ -32,6 +32,12 @@ const (
 	// the folders with files subscription
 	//
 	MetricNoChildFilesFilteredOutEn
+
+	// MetricNoReorderStallsEn represents the number of times the navigator had
+	// to wait for room in the reorder buffer, when the output of the worker
+	// pool is ordered
+	//
+	MetricNoReorderStallsEn
 )
 
 // Metric
@@ -86,6 +92,7 @@ func (f navigationMetricsFactory) new() *NavigationMetrics {
 	instance.collection[MetricNoFoldersFilteredOutEn] = &Metric{Name: "foldersFilteredOut"}
 	instance.collection[MetricNoChildFilesFoundEn] = &Metric{Name: "childrenFound"}
 	instance.collection[MetricNoChildFilesFilteredOutEn] = &Metric{Name: "childrenFilteredOut"}
+	instance.collection[MetricNoReorderStallsEn] = &Metric{Name: "reorderStalls"}
 
 	return instance
 }
@@ -12,13 +12,16 @@ type navigationPeriscope struct {
 }
 
 func (p *navigationPeriscope) scope(isLeaf bool) FilterScopeBiEnum {
+	return scopeAt(p.depth(), isLeaf)
+}
+
+// scopeAt returns the scope of a folder at the depth specified.
+func scopeAt(depth int, isLeaf bool) FilterScopeBiEnum {
 	result := ScopeIntermediateEn
 
 	// Root=0
 	// Top=1
 	//
-	depth := p.depth()
-
 	switch {
 	case isLeaf && depth == 0:
 		result = ScopeRootEn | ScopeLeafEn
@@ -50,8 +53,11 @@ func (p *navigationPeriscope) difference(root, current string) {
 	p._offset = currentSize - rootSize
 }
 
+// descend determines whether the current item can be descended into; the
+// offset is taken into account, so that the maximum depth is relative to the
+// root, even when the navigation begins beneath it (eg spawn resume).
 func (p *navigationPeriscope) descend(max uint) bool {
-	if max > 0 && p._depth > int(max) {
+	if max > 0 && p._offset+p._depth > int(max) {
 		return false
 	}
 
@@ -60,6 +66,12 @@ func (p *navigationPeriscope) descend(max uint) bool {
 	return true
 }
 
+// deeper determines whether the children of the current item will be
+// descended into.
+func (p *navigationPeriscope) deeper(max uint) bool {
+	return max == 0 || p._offset+p._depth <= int(max)
+}
+
 func (p *navigationPeriscope) ascend() {
 	p._depth--
 }
@@ -0,0 +1,233 @@
+package nav
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// directoryReader reads the sub-directories of a directory ahead of the
+// traversal reaching them. The reads are performed by a bounded number of
+// go routines, so the traversal itself remains single threaded; the
+// callback is never invoked concurrently as a result of reading ahead.
+type directoryReader struct {
+	o         *TraverseOptions
+	semaphore chan struct{}
+	mutex     sync.Mutex
+	pending   map[string]*readAhead      // directory => its read
+	batches   map[string]*readAheadBatch // parent => the reads of its sub-directories
+}
+
+// readAhead is the read of a single directory
+type readAhead struct {
+	entries []fs.DirEntry
+	err     error
+	done    chan struct{}
+}
+
+// readAheadBatch are the reads of the sub-directories of a parent
+type readAheadBatch struct {
+	paths     []string
+	ready     chan fs.DirEntry // the sub-directories, in the order their reads complete
+	discarded atomic.Bool
+}
+
+func newDirectoryReader(o *TraverseOptions) *directoryReader {
+	return &directoryReader{
+		o:         o,
+		semaphore: make(chan struct{}, o.Store.ReadAhead.NoReaders),
+		pending:   make(map[string]*readAhead),
+		batches:   make(map[string]*readAheadBatch),
+	}
+}
+
+// read returns the entries of the directory, waiting for the read ahead to
+// complete, if one is in progress, otherwise it is read directly.
+func (r *directoryReader) read(path string) ([]fs.DirEntry, error) {
+	r.mutex.Lock()
+	ahead, found := r.pending[path]
+	delete(r.pending, path)
+	r.mutex.Unlock()
+
+	if !found {
+		return r.o.Hooks.ReadDirectory(path)
+	}
+
+	<-ahead.done
+
+	return ahead.entries, ahead.err
+}
+
+// ahead starts reading the folders of the parent, in the order specified.
+func (r *directoryReader) ahead(parent string, folders []fs.DirEntry) {
+	if len(folders) == 0 {
+		return
+	}
+
+	batch := &readAheadBatch{
+		paths: make([]string, 0, len(folders)),
+		ready: make(chan fs.DirEntry, len(folders)),
+	}
+	reads := make([]*readAhead, 0, len(folders))
+
+	r.mutex.Lock()
+	for _, folder := range folders {
+		path := filepath.Join(parent, folder.Name())
+		ahead := &readAhead{
+			done: make(chan struct{}),
+		}
+
+		r.pending[path] = ahead
+		batch.paths = append(batch.paths, path)
+		reads = append(reads, ahead)
+	}
+	r.batches[parent] = batch
+	r.mutex.Unlock()
+
+	go r.dispatch(batch, folders, reads)
+}
+
+// dispatch performs the reads of the batch, without exceeding the maximum
+// number of concurrent readers across all batches.
+func (r *directoryReader) dispatch(batch *readAheadBatch, folders []fs.DirEntry, reads []*readAhead) {
+	var wg sync.WaitGroup
+
+	for i, folder := range folders {
+		r.semaphore <- struct{}{}
+
+		if batch.discarded.Load() {
+			// the traversal has moved on from the parent, so no one is
+			// waiting for the remaining reads
+			//
+			<-r.semaphore
+			close(reads[i].done)
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(path string, folder fs.DirEntry, ahead *readAhead) {
+			defer func() {
+				<-r.semaphore
+				wg.Done()
+			}()
+
+			entries, err := r.o.Hooks.ReadDirectory(path)
+			ahead.entries = resolve(entries)
+			ahead.err = err
+
+			close(ahead.done)
+			batch.ready <- folder
+		}(batch.paths[i], folder, reads[i])
+	}
+
+	wg.Wait()
+	close(batch.ready)
+}
+
+// discard abandons the reads of the sub-directories of the parent that
+// have not been consumed, because the traversal of the parent is complete.
+func (r *directoryReader) discard(parent string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	batch, found := r.batches[parent]
+	if !found {
+		return
+	}
+
+	batch.discarded.Store(true)
+	delete(r.batches, parent)
+
+	for _, path := range batch.paths {
+		delete(r.pending, path)
+	}
+}
+
+// sequence returns the sequence in which the entries of the parent are to
+// be navigated.
+func (r *directoryReader) sequence(parent string, entries []fs.DirEntry) *entrySequence {
+	sequence := &entrySequence{
+		entries: entries,
+	}
+
+	if !r.o.Store.ReadAhead.Unordered {
+		return sequence
+	}
+
+	r.mutex.Lock()
+	batch, found := r.batches[parent]
+	r.mutex.Unlock()
+
+	if found {
+		sequence.ready = batch.ready
+		sequence.wanted = make(map[string]bool, len(entries))
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				sequence.wanted[entry.Name()] = true
+			}
+		}
+	}
+
+	return sequence
+}
+
+// entrySequence yields the entries of a directory. When unordered, the
+// position of each sub-directory is taken by the next sub-directory whose
+// read has completed.
+type entrySequence struct {
+	entries []fs.DirEntry
+	ready   <-chan fs.DirEntry
+	wanted  map[string]bool
+}
+
+func (s *entrySequence) at(i int) fs.DirEntry {
+	entry := s.entries[i]
+
+	if s.ready == nil || !entry.IsDir() {
+		return entry
+	}
+
+	// the batch contains all the sub-directories of the parent, which may
+	// have been reduced by sampling
+	//
+	for next := range s.ready {
+		if s.wanted[next.Name()] {
+			delete(s.wanted, next.Name())
+
+			return next
+		}
+	}
+
+	return entry
+}
+
+// resolvedEntry is a directory entry whose file info was retrieved at the
+// time it was read ahead.
+type resolvedEntry struct {
+	fs.DirEntry
+	info fs.FileInfo
+	err  error
+}
+
+func (e *resolvedEntry) Info() (fs.FileInfo, error) {
+	return e.info, e.err
+}
+
+func resolve(entries []fs.DirEntry) []fs.DirEntry {
+	resolved := make([]fs.DirEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		resolved = append(resolved, &resolvedEntry{
+			DirEntry: entry,
+			info:     info,
+			err:      err,
+		})
+	}
+
+	return resolved
+}
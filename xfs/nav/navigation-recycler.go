@@ -0,0 +1,98 @@
+package nav
+
+import (
+	"io/fs"
+	"sync"
+)
+
+// recycler recycles the objects allocated for each item navigated, when
+// pooling is enabled (see PoolingOptions), to relieve the garbage collector
+// on massive traversals. An object is released once the navigation of its
+// item is complete, so it must not be retained beyond that point.
+type recycler struct {
+	items       sync.Pool
+	inspections sync.Pool
+	contents    sync.Pool
+}
+
+func newRecycler() *recycler {
+	return &recycler{
+		items: sync.Pool{
+			New: func() any {
+				return &TraverseItem{}
+			},
+		},
+		inspections: sync.Pool{
+			New: func() any {
+				return &inspection{}
+			},
+		},
+		contents: sync.Pool{
+			New: func() any {
+				return &DirectoryContents{}
+			},
+		},
+	}
+}
+
+func (r *recycler) item(
+	path string, entry fs.DirEntry, info fs.FileInfo, parent *TraverseItem, err error,
+) *TraverseItem {
+	item, _ := r.items.Get().(*TraverseItem)
+	item.Path = path
+	item.Entry = entry
+	item.Info = info
+	item.Parent = parent
+	item.Children = []fs.DirEntry{}
+	item.Error = err
+	item.dir = isDir(item)
+
+	return item
+}
+
+func (r *recycler) releaseItem(item *TraverseItem) {
+	*item = TraverseItem{}
+	r.items.Put(item)
+}
+
+func (r *recycler) inspection(current *TraverseItem) *inspection {
+	stash, _ := r.inspections.Get().(*inspection)
+	stash.current = current
+	stash.isDir = current.IsDirectory()
+
+	return stash
+}
+
+func (r *recycler) releaseInspection(stash *inspection) {
+	if stash.contents != nil {
+		r.releaseContents(stash.contents)
+	}
+
+	*stash = inspection{}
+	r.inspections.Put(stash)
+}
+
+// directoryContents arranges the entries into contents whose slices
+// are re-used, so their capacity is retained.
+func (r *recycler) directoryContents(o *TraverseOptions, entries []fs.DirEntry) *DirectoryContents {
+	contents, _ := r.contents.Get().(*DirectoryContents)
+	contents.Options = o
+	contents.arrange(entries)
+
+	return contents
+}
+
+func (r *recycler) releaseContents(contents *DirectoryContents) {
+	// the entries are cleared up to capacity, because All may have appended
+	// beyond the length of the slices
+	//
+	clear(contents.Folders[:cap(contents.Folders)])
+	clear(contents.Files[:cap(contents.Files)])
+
+	contents.Options = nil
+	contents.Folders = contents.Folders[:0]
+	contents.Files = contents.Files[:0]
+	contents.streamed = false
+
+	r.contents.Put(contents)
+}
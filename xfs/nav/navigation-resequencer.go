@@ -0,0 +1,108 @@
+package nav
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/snivilised/lorax/boost"
+)
+
+const (
+	resequencerRoutineName = boost.GoRoutineName("🔢 resequencer")
+
+	// MinReorderBufferSize is the minimum size of the reorder buffer
+	MinReorderBufferSize = 1
+)
+
+// resequencer restores the traversal order of the outputs of the worker
+// pool, which arrive in the order the jobs complete. Each job is assigned
+// a sequence number as it is submitted and the outputs are held back until
+// all of those that precede them have been emitted. The number of jobs in
+// flight is limited by the size of the reorder buffer, so when the buffer
+// is full, the navigator stalls until the output at the head of the buffer
+// is emitted.
+type resequencer struct {
+	slots chan struct{}
+	next  int
+}
+
+func newResequencer(size int) *resequencer {
+	return &resequencer{
+		slots: make(chan struct{}, size),
+	}
+}
+
+// admit assigns the sequence number of the next job, waiting for room in
+// the reorder buffer, if necessary. Only invoked by the navigator.
+func (r *resequencer) admit(ctx context.Context) (sequenceNo int, stalled, ok bool) {
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		stalled = true
+
+		select {
+		case r.slots <- struct{}{}:
+		case <-ctx.Done():
+			return 0, stalled, false
+		}
+	}
+
+	sequenceNo = r.next
+	r.next++
+
+	return sequenceNo, stalled, true
+}
+
+// run emits the outputs received from the pool in sequence, until the
+// pool closes its output channel. Any outputs still buffered at that point
+// (because a preceding job was abandoned when the context was cancelled),
+// are emitted in sequence, skipping over the gaps. As with the workers when
+// the outputs are not ordered, if the client does not receive an output
+// within the timeout, the session is cancelled.
+func (r *resequencer) run(ctx context.Context,
+	cancel context.CancelFunc,
+	timeout time.Duration,
+	outputsChIn <-chan TraverseItemOutput,
+	outputsChOut chan<- TraverseItemOutput,
+) {
+	defer close(outputsChOut)
+
+	buffer := make(map[int]TraverseItemOutput)
+	expected := 0
+
+	emit := func(output TraverseItemOutput) {
+		<-r.slots
+
+		outputCtx, outputCancel := context.WithTimeout(ctx, timeout)
+		defer outputCancel()
+
+		select {
+		case outputsChOut <- output:
+		case <-ctx.Done():
+		case <-outputCtx.Done():
+			cancel()
+		}
+	}
+
+	for output := range outputsChIn {
+		buffer[output.Payload.SequenceNo] = output
+
+		for head, found := buffer[expected]; found; head, found = buffer[expected] {
+			delete(buffer, expected)
+			emit(head)
+			expected++
+		}
+	}
+
+	remaining := make([]int, 0, len(buffer))
+	for sequenceNo := range buffer {
+		remaining = append(remaining, sequenceNo)
+	}
+
+	sort.Ints(remaining)
+
+	for _, sequenceNo := range remaining {
+		emit(buffer[sequenceNo])
+	}
+}
@@ -0,0 +1,208 @@
+package nav_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/lorax/boost"
+)
+
+type orderedTE struct {
+	given      string
+	should     string
+	noWorkers  int
+	bufferSize int
+	stalls     bool
+}
+
+var _ = Describe("Ordered Output", Ordered, func() {
+	var root string
+
+	BeforeAll(func() {
+		root = musico()
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	options := func(fn nav.TraverseCallback) nav.TraverseOptionFn {
+		return func(o *nav.TraverseOptions) {
+			o.Store.Subscription = nav.SubscribeAny
+			o.Callback = &nav.LabelledTraverseCallback{
+				Label: "ordered callback",
+				Fn:    fn,
+			}
+		}
+	}
+
+	DescribeTable("pool",
+		func(ctxSpec SpecContext, entry *orderedTE) {
+			defer leaktest.Check(GinkgoT())()
+
+			path := helpers.Path(root, "RETRO-WAVE")
+			expected := []string{}
+
+			_, err := nav.New().Primary(&nav.Prime{
+				Path: path,
+				OptionsFn: options(func(item *nav.TraverseItem) error {
+					expected = append(expected, item.Path)
+
+					return nil
+				}),
+			}).Run()
+			Expect(err).To(Succeed())
+
+			ctx, cancel := context.WithCancel(ctxSpec)
+			defer cancel()
+
+			wgan := boost.NewAnnotatedWaitGroup("🍂 ordered traversal")
+			wgan.Add(1, navigatorRoutineName)
+			outputCh := nav.CreateTraverseOutputCh(3)
+
+			runner := nav.New().With(nav.RunnerWithPool, &nav.RunnerInfo{
+				PrimeInfo: &nav.Prime{
+					Path: path,
+					OptionsFn: options(func(item *nav.TraverseItem) error {
+						// the jobs complete out of order, as the later ones are quicker
+						//
+						time.Sleep(time.Millisecond * time.Duration(len(item.Path)%5))
+
+						return nil
+					}),
+				},
+				AccelerationInfo: &nav.Acceleration{
+					WgAn:              wgan,
+					RoutineName:       navigatorRoutineName,
+					NoW:               entry.noWorkers,
+					JobsChOut:         make(nav.TraverseItemJobStream, DefaultJobsChSize),
+					JobResultsCh:      outputCh,
+					OutputChTimeout:   outputChTimeout,
+					ReorderBufferSize: entry.bufferSize,
+				},
+			})
+
+			actual := []string{}
+			sequence := []int{}
+			consumed := make(chan struct{})
+
+			go func() {
+				defer close(consumed)
+
+				for output := range outputCh {
+					actual = append(actual, output.Payload.Item.Path)
+					sequence = append(sequence, output.Payload.SequenceNo)
+				}
+			}()
+
+			result, err := runner.Run(ctx, cancel)
+			Expect(err).To(Succeed())
+
+			wgan.Wait("👾 test-main")
+			<-consumed
+
+			Expect(actual).To(Equal(expected))
+
+			for i, no := range sequence {
+				Expect(no).To(Equal(i))
+			}
+
+			stalls := result.Metrics.Count(nav.MetricNoReorderStallsEn)
+			if entry.stalls {
+				Expect(stalls).To(BeNumerically(">", 0))
+			} else {
+				Expect(stalls).To(BeZero())
+			}
+		},
+		func(entry *orderedTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.given, entry.should)
+		},
+
+		Entry(nil, &orderedTE{
+			given:      "reorder buffer larger than the number of items",
+			should:     "deliver outputs in traversal order without stalling",
+			noWorkers:  4,
+			bufferSize: 100,
+		}, SpecTimeout(time.Second*5)),
+
+		Entry(nil, &orderedTE{
+			given:      "reorder buffer smaller than the number of workers",
+			should:     "deliver outputs in traversal order with stalls",
+			noWorkers:  4,
+			bufferSize: 1,
+			stalls:     true,
+		}, SpecTimeout(time.Second*5)),
+	)
+
+	When("outputs not received by the client", func() {
+		It("🧪 should: cancel the session once timed out", func(ctxSpec SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			ctx, cancel := context.WithCancel(ctxSpec)
+			defer cancel()
+
+			wgan := boost.NewAnnotatedWaitGroup("🍂 ordered traversal")
+			wgan.Add(1, navigatorRoutineName)
+			outputCh := nav.CreateTraverseOutputCh(1)
+
+			// the outputs of the 2 folders fit in the channels of the pool, so
+			// the workers are never blocked; only the resequencer is.
+			//
+			runner := nav.New().With(nav.RunnerWithPool, &nav.RunnerInfo{
+				PrimeInfo: &nav.Prime{
+					Path: helpers.Path(root, "RETRO-WAVE/Chromatics"),
+					OptionsFn: func(o *nav.TraverseOptions) {
+						options(func(_ *nav.TraverseItem) error {
+							return nil
+						})(o)
+						o.Store.Subscription = nav.SubscribeFolders
+					},
+				},
+				AccelerationInfo: &nav.Acceleration{
+					WgAn:              wgan,
+					RoutineName:       navigatorRoutineName,
+					NoW:               2,
+					JobsChOut:         make(nav.TraverseItemJobStream, DefaultJobsChSize),
+					JobResultsCh:      outputCh,
+					OutputChTimeout:   time.Millisecond * 50,
+					ReorderBufferSize: 4,
+				},
+			})
+
+			_, _ = runner.Run(ctx, cancel)
+			wgan.Wait("👾 test-main")
+
+			Expect(ctx.Err()).To(MatchError(context.Canceled))
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("reorder buffer size is less than minimum", func() {
+		It("🧪 should: panic with reorder buffer too small error", func() {
+			defer func() {
+				pe := recover()
+				err, ok := pe.(error)
+				Expect(ok).To(BeTrue())
+				Expect(QueryReorderBufferTooSmallError(err)).To(BeTrue())
+			}()
+
+			nav.New().Primary(&nav.Prime{
+				Path:      root,
+				OptionsFn: options(func(_ *nav.TraverseItem) error { return nil }),
+			}).WithPool(&nav.AsyncInfo{}).Ordered(nav.MinReorderBufferSize - 1)
+
+			Fail("expected panic")
+		})
+	})
+})
@@ -1,10 +1,12 @@
 package nav
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"time"
 
+	"github.com/snivilised/extendio/i18n"
 	"github.com/snivilised/extendio/internal/lo"
 	"github.com/snivilised/lorax/boost"
 )
@@ -24,6 +26,21 @@ type Acceleration struct {
 	JobsChOut       TraverseItemJobStream
 	JobResultsCh    boost.JobOutputStream[TraverseOutput]
 	OutputChTimeout time.Duration
+
+	// ReorderBufferSize, when set, causes the outputs to be delivered to
+	// JobResultsCh in traversal order (see AccelerationOperators.Ordered)
+	//
+	ReorderBufferSize int
+
+	// Throttle restricts the rate at which the workers process items (see
+	// AccelerationOperators.Throttle)
+	//
+	Throttle *Throttle
+
+	// Transport executes the jobs outside of the process (see
+	// AccelerationOperators.Transport)
+	//
+	Transport JobTransport
 }
 
 type RunnerInfo struct {
@@ -56,6 +73,26 @@ type AccelerationOperators interface {
 	Runnable
 	NoW(now int) AccelerationOperators
 	Consume(outputCh boost.JobOutputStream[TraverseOutput], timeout time.Duration) AccelerationOperators
+
+	// Ordered causes the outputs to be delivered to the consumer in traversal
+	// order, rather than the order in which the jobs complete. The size of the
+	// reorder buffer limits the number of jobs in flight, so it should be
+	// larger than the number of workers, otherwise the workers will be starved.
+	// The number of times the navigator had to wait for room in the buffer is
+	// reported by MetricNoReorderStallsEn. Only applies when consuming.
+	Ordered(bufferSize int) AccelerationOperators
+
+	// Throttle restricts the rate at which the workers process items. The
+	// client retains the throttle, so that its limits can be adjusted while
+	// the session is running.
+	Throttle(throttle *Throttle) AccelerationOperators
+
+	// Transport causes the client callback to be executed outside of the
+	// process, by the executors served by the transport. The workers still
+	// apply the filters and the listener, so only the items they admit are
+	// sent. The session closes the transport once all the jobs have
+	// completed.
+	Transport(transport JobTransport) AccelerationOperators
 }
 
 type SessionRunner interface {
@@ -68,6 +105,10 @@ type NavigationRunner interface {
 	AccelerationOperators
 	WithPool(ai *AsyncInfo) AccelerationOperators
 	Save(path string) error
+
+	// RunAsync runs the traversal in the background with a worker pool
+	// (see AsyncSession)
+	RunAsync(ctx context.Context, noWorkers int) *AsyncSession
 }
 
 func New() SessionRunner {
@@ -129,6 +170,18 @@ func (r *runner) With(with CreateNewRunnerWith, info *RunnerInfo) NavigationRunn
 		if info.AccelerationInfo.NoW > 0 {
 			r.NoW(info.AccelerationInfo.NoW)
 		}
+
+		if info.AccelerationInfo.ReorderBufferSize > 0 {
+			r.Ordered(info.AccelerationInfo.ReorderBufferSize)
+		}
+
+		if info.AccelerationInfo.Throttle != nil {
+			r.Throttle(info.AccelerationInfo.Throttle)
+		}
+
+		if info.AccelerationInfo.Transport != nil {
+			r.Transport(info.AccelerationInfo.Transport)
+		}
 	}
 
 	return r
@@ -201,6 +254,28 @@ func (r *runner) Consume(
 	return r
 }
 
+func (r *runner) Ordered(bufferSize int) AccelerationOperators {
+	if bufferSize < MinReorderBufferSize {
+		panic(i18n.NewReorderBufferTooSmallError(bufferSize, MinReorderBufferSize))
+	}
+
+	r.sync.reorderSize = bufferSize
+
+	return r
+}
+
+func (r *runner) Throttle(throttle *Throttle) AccelerationOperators {
+	r.sync.throttle = throttle
+
+	return r
+}
+
+func (r *runner) Transport(transport JobTransport) AccelerationOperators {
+	r.sync.transport = transport
+
+	return r
+}
+
 func (r *runner) Run(args ...any) (*TraverseResult, error) {
 	sync := lo.TernaryF(r.sync == nil,
 		func() NavigationSync {
@@ -85,17 +85,25 @@ func (s *Primary) run(sync NavigationSync, args ...any) (*TraverseResult, error)
 		args...,
 	)
 
-	s.finish(result, err)
+	if fe := s.finish(result, err); err == nil {
+		err = fe
+	}
 
 	return result, err
 }
 
-func (s *Primary) finish(result *TraverseResult, err error) {
+// finish returns the error that occurred persisting the state of the
+// navigator (eg the incremental index), if any.
+func (s *Primary) finish(result *TraverseResult, err error) error {
+	var fe error
+
 	if s.navigator != nil {
-		_ = s.navigator.finish()
+		fe = s.navigator.finish()
 	}
 
 	s.session.finish(result, err)
+
+	return fe
 }
 
 // Resume represents a traversal that is invoked as a result
@@ -141,15 +149,21 @@ func (s *Resume) run(sync NavigationSync, args ...any) (*TraverseResult, error)
 		args...,
 	)
 
-	s.finish(result, err)
+	if fe := s.finish(result, err); err == nil {
+		err = fe
+	}
 
 	return result, err
 }
 
-func (s *Resume) finish(result *TraverseResult, err error) {
+func (s *Resume) finish(result *TraverseResult, err error) error {
+	var fe error
+
 	if s.rsc != nil {
-		_ = s.rsc.finish()
+		fe = s.rsc.finish()
 	}
 
 	s.session.finish(result, err)
+
+	return fe
 }
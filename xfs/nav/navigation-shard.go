@@ -0,0 +1,494 @@
+package nav
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/snivilised/extendio/internal/lo"
+)
+
+// ShardOptions
+type ShardOptions struct {
+	// Depth is the depth at which the tree is partitioned (1 denotes the top
+	// level directories). The contents of each directory at this depth form a
+	// shard, which is navigated by a worker process. The items down to and
+	// including this depth form a shard of their own. Defaults to 1.
+	//
+	Depth uint
+
+	// NoWorkers is the number of worker processes launched. Defaults to 1.
+	//
+	NoWorkers int
+
+	// NoRetries is the number of times a shard is re-submitted after a
+	// failure, either of the traversal or of the worker process, before
+	// the shard is abandoned.
+	//
+	NoRetries uint
+
+	// ReadDirectory is used to discover the shards and should be the same
+	// as the hook used by the workers. Defaults to ReadEntriesHookFn.
+	//
+	ReadDirectory ReadDirectoryHookFn
+}
+
+// ShardLauncher starts a worker and returns the connection over which shards
+// are submitted to it; the worker serves the other end of the connection with
+// ServeShards. Closing the connection signifies to the worker that there are
+// no more shards and must release any resources associated with the worker.
+// The connection could be the stdin/stdout pipes of a process (see
+// CommandShardLauncher) or a unix socket.
+type ShardLauncher func(ctx context.Context) (io.ReadWriteCloser, error)
+
+// shardRequest is the message sent to a worker to navigate a shard.
+type shardRequest struct {
+	Root    string `json:"root"`
+	Path    string `json:"path"`
+	Depth   uint   `json:"depth"`
+	Residue bool   `json:"residue,omitempty"`
+}
+
+// shardResponse is the message returned by a worker once it has navigated
+// a shard.
+type shardResponse struct {
+	Path    string           `json:"path"`
+	Metrics MetricCollection `json:"metrics"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// ShardCoordinator splits a traversal across a number of worker processes.
+// The tree is partitioned into shards at a configured depth, which are
+// handed to the workers, whose results are merged together.
+type ShardCoordinator struct {
+	session
+	o      ShardOptions
+	launch ShardLauncher
+}
+
+// NewShardCoordinator creates a ShardCoordinator that uses the launcher
+// to start its workers.
+func NewShardCoordinator(options *ShardOptions, launch ShardLauncher) *ShardCoordinator {
+	c := &ShardCoordinator{
+		o:      *options,
+		launch: launch,
+	}
+
+	c.o.Depth = max(c.o.Depth, 1)
+	c.o.NoWorkers = max(c.o.NoWorkers, 1)
+
+	if c.o.ReadDirectory == nil {
+		c.o.ReadDirectory = ReadEntriesHookFn
+	}
+
+	return c
+}
+
+type shardTask struct {
+	request  *shardRequest
+	attempts uint
+}
+
+// Run navigates the tree at root. The shards that still fail after being
+// retried are reported as an error (wrapping ErrShardFailed), but the
+// result contains the merged metrics of all those that succeeded.
+func (c *ShardCoordinator) Run(ctx context.Context, root string) (*TraverseResult, error) {
+	c.start()
+
+	folders, err := c.partition(root)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := []*shardRequest{{Root: root, Path: root, Depth: c.o.Depth, Residue: true}}
+	for _, folder := range folders {
+		requests = append(requests, &shardRequest{Root: root, Path: folder, Depth: c.o.Depth})
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		failures []error
+		tasks    = make(chan *shardTask, len(requests))
+		compound = &TraverseResult{
+			Metrics: navigationMetricsFactory{}.new(),
+		}
+	)
+
+	// a task is only ever in the channel once, so re-submitting a failed
+	// task never blocks
+	//
+	for _, request := range requests {
+		tasks <- &shardTask{request: request}
+	}
+
+	wg.Add(len(requests))
+
+	go func() {
+		wg.Wait()
+		close(tasks)
+	}()
+
+	complete := func(response *shardResponse, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if err != nil {
+			failures = append(failures, err)
+		} else {
+			_, _ = compound.merge(&TraverseResult{
+				Metrics: &NavigationMetrics{collection: response.Metrics},
+			})
+		}
+
+		wg.Done()
+	}
+
+	fail := func(task *shardTask, err error) {
+		if task.attempts++; task.attempts <= c.o.NoRetries && ctx.Err() == nil {
+			tasks <- task
+
+			return
+		}
+
+		complete(nil, fmt.Errorf("%w: '%v' (%w)", ErrShardFailed, task.request.Path, err))
+	}
+
+	var workers sync.WaitGroup
+
+	workers.Add(c.o.NoWorkers)
+
+	for w := 0; w < c.o.NoWorkers; w++ {
+		go func() {
+			defer workers.Done()
+
+			c.work(ctx, tasks, complete, fail)
+		}()
+	}
+
+	workers.Wait()
+
+	err = errors.Join(failures...)
+	compound.err = err
+	c.finish(compound, err)
+
+	return compound, err
+}
+
+// work submits tasks to a worker, which is re-launched if its connection
+// fails.
+func (c *ShardCoordinator) work(ctx context.Context, tasks chan *shardTask,
+	complete func(*shardResponse, error),
+	fail func(*shardTask, error),
+) {
+	var conn *shardConn
+
+	defer func() {
+		if conn != nil {
+			conn.close()
+		}
+	}()
+
+	for task := range tasks {
+		if err := ctx.Err(); err != nil {
+			fail(task, err)
+
+			continue
+		}
+
+		if conn == nil {
+			rwc, err := c.launch(ctx)
+			if err != nil {
+				fail(task, err)
+
+				continue
+			}
+
+			conn = newShardConn(ctx, rwc)
+		}
+
+		response, err := conn.exchange(task.request)
+		if err != nil {
+			// the worker can no longer be relied upon
+			//
+			conn.close()
+			conn = nil
+
+			fail(task, err)
+
+			continue
+		}
+
+		if response.Error != "" {
+			fail(task, errors.New(response.Error))
+
+			continue
+		}
+
+		complete(response, nil)
+	}
+}
+
+// partition returns the directories at the shard depth, in sorted order.
+func (c *ShardCoordinator) partition(root string) ([]string, error) {
+	level := []string{root}
+
+	for depth := uint(0); depth < c.o.Depth; depth++ {
+		next := []string{}
+
+		for _, parent := range level {
+			entries, err := c.o.ReadDirectory(parent)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, entry := range entries {
+				if entry.IsDir() {
+					next = append(next, filepath.Join(parent, entry.Name()))
+				}
+			}
+		}
+
+		level = next
+	}
+
+	sort.Strings(level)
+
+	return level, nil
+}
+
+// shardConn is the coordinator's end of the connection to a worker.
+type shardConn struct {
+	rwc     io.ReadWriteCloser
+	encoder *json.Encoder
+	decoder *json.Decoder
+	stop    func() bool
+}
+
+func newShardConn(ctx context.Context, rwc io.ReadWriteCloser) *shardConn {
+	return &shardConn{
+		rwc:     rwc,
+		encoder: json.NewEncoder(rwc),
+		decoder: json.NewDecoder(rwc),
+		// a worker that is busy when the context is cancelled is unblocked by
+		// closing its connection
+		//
+		stop: context.AfterFunc(ctx, func() {
+			_ = rwc.Close()
+		}),
+	}
+}
+
+func (c *shardConn) exchange(request *shardRequest) (*shardResponse, error) {
+	if err := c.encoder.Encode(request); err != nil {
+		return nil, err
+	}
+
+	response := &shardResponse{}
+	if err := c.decoder.Decode(response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *shardConn) close() {
+	if c.stop() {
+		_ = c.rwc.Close()
+	}
+}
+
+// ServeShards navigates the shards received over the connection from a
+// ShardCoordinator, with the traverse options defined by fn, until the
+// connection is closed. It is invoked by the worker process. When the
+// connection is the process's stdout, the callback must not write to it.
+func ServeShards(conn io.ReadWriter, fn TraverseOptionFn) error {
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+
+	for {
+		request := &shardRequest{}
+		if err := decoder.Decode(request); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		result, err := navigateShard(request, fn)
+		response := &shardResponse{
+			Path: request.Path,
+		}
+
+		if result != nil && result.Metrics != nil {
+			response.Metrics = result.Metrics.collection
+		}
+
+		if err != nil {
+			response.Error = err.Error()
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return err
+		}
+	}
+}
+
+// navigateShard navigates the contents of the directory of the shard, in
+// the same way as the spawn resume strategy navigates following siblings;
+// the directory itself belongs to the residue shard.
+func navigateShard(request *shardRequest, fn TraverseOptionFn) (*TraverseResult, error) {
+	o := composeTraverseOptions(fn)
+	depth := o.Store.Behaviours.Cascade.Depth
+
+	if !request.Residue && depth != 0 && depth <= request.Depth {
+		// the contents of the shard are beyond the depth of the traversal
+		//
+		return &TraverseResult{Metrics: navigationMetricsFactory{}.new()}, nil
+	}
+
+	if request.Residue {
+		o.Store.Behaviours.Cascade.Depth = lo.Ternary(depth == 0, request.Depth, min(depth, request.Depth))
+		nc, _ := navigatorFactory{}.new(o).(*navigationController)
+		result, err := nc.walk(request.Root)
+
+		if fe := nc.finish(); err == nil {
+			err = fe
+		}
+
+		return result, err
+	}
+
+	entries, err := o.Hooks.ReadDirectory(request.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	nc, _ := navigatorFactory{}.new(o).(*navigationController)
+
+	contents := newDirectoryContents(&newDirectoryContentsParams{
+		o:       o,
+		entries: entries,
+	})
+	contents.sort(contents.Files)
+	contents.sort(contents.Folders)
+
+	nc.frame.root.Set(request.Root)
+
+	compound := &TraverseResult{
+		Metrics: navigationMetricsFactory{}.new(),
+	}
+
+	for _, entry := range contents.All() {
+		path := filepath.Join(request.Path, entry.Name())
+
+		nc.frame.link(&linkParams{
+			root:    request.Root,
+			current: path,
+		})
+
+		result, err := nc.impl.top(nc.frame, path)
+		_, _ = compound.merge(result)
+
+		if err != nil {
+			nc.abandon()
+
+			break
+		}
+	}
+
+	if err := nc.finish(); err != nil && compound.err == nil {
+		compound.err = err
+	}
+
+	return compound, compound.err
+}
+
+// CommandShardLauncher creates a ShardLauncher that runs the command as the
+// worker process, which serves the shards over its stdin and stdout, eg
+// by invoking ServeShards(nav.StdioConn(), fn).
+func CommandShardLauncher(name string, args ...string) ShardLauncher {
+	return func(ctx context.Context) (io.ReadWriteCloser, error) {
+		return startCommand(ctx, name, args...)
+	}
+}
+
+// startCommand runs the command and returns the connection to it over its
+// stdin and stdout.
+func startCommand(ctx context.Context, name string, args ...string) (io.ReadWriteCloser, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &commandConn{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: stdout,
+	}, nil
+}
+
+// commandConn is the connection to a worker process over its stdin and
+// stdout.
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	once   sync.Once
+	err    error
+}
+
+func (c *commandConn) Read(p []byte) (int, error) {
+	return c.stdout.Read(p)
+}
+
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// Close closes the worker's stdin, which signifies there are no more
+// shards, then waits for the worker to exit.
+func (c *commandConn) Close() error {
+	c.once.Do(func() {
+		_ = c.stdin.Close()
+		c.err = c.cmd.Wait()
+	})
+
+	return c.err
+}
+
+// StdioConn returns the connection of a process launched by
+// CommandShardLauncher or CommandJobTransport, to the process that
+// launched it.
+func StdioConn() io.ReadWriter {
+	return stdioConn{}
+}
+
+type stdioConn struct{}
+
+func (stdioConn) Read(p []byte) (int, error) {
+	return os.Stdin.Read(p)
+}
+
+func (stdioConn) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
@@ -0,0 +1,227 @@
+package nav_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/xfs/nav"
+)
+
+const shardWorkerSubscriptionEnv = "NAV_SHARD_WORKER_SUBSCRIPTION"
+
+// TestShardWorker is not a test, but the worker process launched by the
+// sharding specs, which re-run the test binary.
+func TestShardWorker(t *testing.T) {
+	value, found := os.LookupEnv(shardWorkerSubscriptionEnv)
+	if !found {
+		t.Skip("only runs as a shard worker")
+	}
+
+	subscription, _ := strconv.Atoi(value)
+
+	if err := nav.ServeShards(nav.StdioConn(), shardOptions(nav.TraverseSubscription(subscription))); err != nil {
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+func shardOptions(subscription nav.TraverseSubscription) nav.TraverseOptionFn {
+	return func(o *nav.TraverseOptions) {
+		o.Store.Subscription = subscription
+		o.Callback = &nav.LabelledTraverseCallback{
+			Label: "shard callback",
+			Fn: func(_ *nav.TraverseItem) error {
+				return nil
+			},
+		}
+	}
+}
+
+type shardTE struct {
+	given        string
+	should       string
+	subscription nav.TraverseSubscription
+	depth        uint
+}
+
+var _ = Describe("Sharded Traversal", Ordered, func() {
+	var (
+		root string
+		path string
+	)
+
+	BeforeAll(func() {
+		root = musico()
+		path = helpers.Path(root, "RETRO-WAVE")
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	expect := func(result *nav.TraverseResult, fn ...nav.TraverseOptionFn) {
+		expected, err := nav.New().Primary(&nav.Prime{
+			Path: path,
+			OptionsFn: func(o *nav.TraverseOptions) {
+				for _, f := range fn {
+					f(o)
+				}
+			},
+		}).Run()
+		Expect(err).To(Succeed())
+
+		for _, metric := range []nav.MetricEnum{
+			nav.MetricNoFilesInvokedEn,
+			nav.MetricNoFoldersInvokedEn,
+		} {
+			Expect(result.Metrics.Count(metric)).To(
+				Equal(expected.Metrics.Count(metric)), fmt.Sprintf("metric: '%v'", metric),
+			)
+		}
+	}
+
+	// pipe launches workers within this process, the first of which fails
+	// after receiving its first shard, when flaky
+	//
+	pipe := func(flaky bool, fn ...nav.TraverseOptionFn) nav.ShardLauncher {
+		launched := atomic.Int32{}
+
+		return func(_ context.Context) (io.ReadWriteCloser, error) {
+			coordinator, worker := net.Pipe()
+
+			go func(first bool) {
+				defer worker.Close()
+
+				if first && flaky {
+					_, _ = worker.Read(make([]byte, 1))
+
+					return
+				}
+
+				_ = nav.ServeShards(worker, func(o *nav.TraverseOptions) {
+					shardOptions(nav.SubscribeAny)(o)
+
+					for _, f := range fn {
+						f(o)
+					}
+				})
+			}(launched.Add(1) == 1)
+
+			return coordinator, nil
+		}
+	}
+
+	DescribeTable("worker processes",
+		func(ctx SpecContext, entry *shardTE) {
+			Expect(os.Setenv(shardWorkerSubscriptionEnv, strconv.Itoa(int(entry.subscription)))).To(Succeed())
+			DeferCleanup(os.Unsetenv, shardWorkerSubscriptionEnv)
+
+			coordinator := nav.NewShardCoordinator(&nav.ShardOptions{
+				Depth:     entry.depth,
+				NoWorkers: 3,
+			}, nav.CommandShardLauncher(os.Args[0], "-test.run=^TestShardWorker$"))
+
+			result, err := coordinator.Run(ctx, path)
+			Expect(err).To(Succeed())
+			Expect(result.Session).NotTo(BeNil())
+
+			expect(result, shardOptions(entry.subscription))
+		},
+		func(entry *shardTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.given, entry.should)
+		},
+
+		Entry(nil, &shardTE{
+			given:        "universal, sharded at top level",
+			should:       "navigate the same items as a single traversal",
+			subscription: nav.SubscribeAny,
+			depth:        1,
+		}, SpecTimeout(time.Second*20)),
+
+		Entry(nil, &shardTE{
+			given:        "universal, sharded beneath top level",
+			should:       "navigate the same items as a single traversal",
+			subscription: nav.SubscribeAny,
+			depth:        2,
+		}, SpecTimeout(time.Second*20)),
+
+		Entry(nil, &shardTE{
+			given:        "files, sharded at top level",
+			should:       "navigate the same items as a single traversal",
+			subscription: nav.SubscribeFiles,
+			depth:        1,
+		}, SpecTimeout(time.Second*20)),
+
+		Entry(nil, &shardTE{
+			given:        "folders, sharded at top level",
+			should:       "navigate the same items as a single traversal",
+			subscription: nav.SubscribeFolders,
+			depth:        1,
+		}, SpecTimeout(time.Second*20)),
+	)
+
+	When("worker fails", func() {
+		It("🧪 should: retry the shard with a new worker", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			coordinator := nav.NewShardCoordinator(&nav.ShardOptions{
+				NoWorkers: 2,
+				NoRetries: 1,
+			}, pipe(true))
+
+			result, err := coordinator.Run(ctx, path)
+			Expect(err).To(Succeed())
+
+			expect(result, shardOptions(nav.SubscribeAny))
+		}, SpecTimeout(time.Second*5))
+
+		It("🧪 should: report the shard as failed when retries exhausted", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			coordinator := nav.NewShardCoordinator(&nav.ShardOptions{
+				NoWorkers: 1,
+			}, pipe(true))
+
+			_, err := coordinator.Run(ctx, path)
+			Expect(errors.Is(err, nav.ErrShardFailed)).To(BeTrue())
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("depth limited", func() {
+		It("🧪 should: not navigate beneath the depth within the shards", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			limit := func(o *nav.TraverseOptions) {
+				o.Store.Behaviours.Cascade.Depth = 2
+			}
+
+			coordinator := nav.NewShardCoordinator(&nav.ShardOptions{
+				NoWorkers: 2,
+			}, pipe(false, limit))
+
+			result, err := coordinator.Run(ctx, path)
+			Expect(err).To(Succeed())
+
+			expect(result, shardOptions(nav.SubscribeAny), limit)
+		}, SpecTimeout(time.Second*5))
+	})
+})
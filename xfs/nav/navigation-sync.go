@@ -4,11 +4,17 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
+	"github.com/snivilised/extendio/internal/lo"
 	"github.com/snivilised/lorax/boost"
 )
 
+const (
+	drainRoutineName = boost.GoRoutineName("🧽 drain")
+)
+
 type NavigationSync interface {
 	Run(callback sessionCallback, nc syncable, args ...any) (*TraverseResult, error)
 }
@@ -62,7 +68,13 @@ type acceleratedSync struct {
 	noWorkers       int
 	outputChOut     boost.JobOutputStream[TraverseOutput]
 	outputChTimeout time.Duration
+	reorderSize     int
+	throttle        *Throttle
+	transport       JobTransport
+	remote          *remoteExecutor
 	pool            *boost.WorkerPool[TraverseItemInput, TraverseOutput]
+	launching       sync.Once
+	closing         sync.Once
 }
 
 func (s *acceleratedSync) Run(callback sessionCallback, nc syncable, args ...any) (*TraverseResult, error) {
@@ -76,18 +88,43 @@ func (s *acceleratedSync) Run(callback sessionCallback, nc syncable, args ...any
 		panic("failed to extract context")
 	}
 
+	if s.reorderSize > 0 && s.outputChOut != nil {
+		s.ai.sequencer = newResequencer(s.reorderSize)
+	}
+
+	s.ai.throttle = s.throttle
+	s.ai.inflight = &inflightTracker{}
+	s.ai.launch = func() {
+		s.launching.Do(func() {
+			s.start(ctx, cancel, nc)
+		})
+	}
+
 	nc.ensync(ctx, cancel, s.ai)
-	s.start(ctx, cancel)
 
 	return callback()
 }
 
-func (s *acceleratedSync) start(ctx context.Context, cancel context.CancelFunc) {
+func (s *acceleratedSync) start(ctx context.Context, cancel context.CancelFunc, nc syncable) {
+	if s.transport != nil {
+		// the jobs are executed out of process; the results are routed back
+		// to the workers by the receiver
+		//
+		s.remote = newRemoteExecutor(s.transport)
+		s.ai.WaitAQ.Add(1, transportRoutineName)
+
+		go func() {
+			defer s.ai.WaitAQ.Done(transportRoutineName)
+
+			s.remote.receive()
+		}()
+	}
+
 	s.pool = boost.NewWorkerPool[TraverseItemInput, TraverseOutput](
 		&boost.NewWorkerPoolParams[TraverseItemInput, TraverseOutput]{
 			NoWorkers:       s.noWorkers,
 			OutputChTimeout: s.outputChTimeout,
-			Exec:            workerExecutive,
+			Exec:            s.executive(ctx),
 			JobsCh:          s.ai.JobsChanOut,
 			WaitAQ:          s.ai.WaitAQ,
 		})
@@ -97,15 +134,102 @@ func (s *acceleratedSync) start(ctx context.Context, cancel context.CancelFunc)
 	// and thus knows when to close it.
 	//
 	s.ai.WaitAQ.Add(1, s.pool.RoutineName)
+	s.ai.WaitAQ.Add(1, drainRoutineName)
+
+	outputChOut := s.outputChOut
+
+	if s.ai.sequencer != nil {
+		// the pool's outputs are re-sequenced before being forwarded to the
+		// client's output channel
+		//
+		outputChOut = make(TraverseItemOutputStream, s.noWorkers)
+		s.ai.WaitAQ.Add(1, resequencerRoutineName)
+
+		go func(outputsChIn <-chan TraverseItemOutput) {
+			defer s.ai.WaitAQ.Done(resequencerRoutineName)
+
+			s.ai.sequencer.run(ctx, cancel, s.outputChTimeout, outputsChIn, s.outputChOut)
+		}(outputChOut)
+	}
+
+	go func() {
+		defer s.ai.WaitAQ.Done(drainRoutineName)
 
-	go s.pool.Start(ctx, cancel, s.outputChOut)
+		s.pool.Start(ctx, cancel, outputChOut)
+
+		if s.transport != nil {
+			_ = s.transport.Close()
+		}
+
+		nc.drained()
+	}()
 }
 
 func (s *acceleratedSync) finish(
 	ai *AsyncInfo,
 ) {
-	close(ai.JobsChanOut) // ⚠️ fastward: intermittent panic on close
-	ai.WaitAQ.Done(ai.NavigatorRoutineName)
+	// the jobs channel must only be closed once, even if the session is
+	// finished more than once (eg when cancelled)
+	//
+	s.closing.Do(func() {
+		// the pool is only started when the first job is submitted, because
+		// it can not drain without having spawned a worker; so when there
+		// were no jobs (eg an empty traversal, or cancelled before the first
+		// item), the output channel is closed here instead.
+		//
+		s.launching.Do(func() {
+			if s.outputChOut != nil {
+				close(s.outputChOut)
+			}
+
+			if s.transport != nil {
+				_ = s.transport.Close()
+			}
+		})
+
+		close(ai.JobsChanOut)
+		ai.WaitAQ.Done(ai.NavigatorRoutineName)
+	})
+}
+
+// executive returns the function the workers use to execute jobs, either
+// locally or via the transport. It waits for the throttle, if there is one,
+// and reports the completion of each job to the in-flight tracker.
+func (s *acceleratedSync) executive(ctx context.Context) boost.ExecutiveFunc[TraverseItemInput, TraverseOutput] {
+	execute := workerExecutive
+
+	if s.remote != nil {
+		execute = s.remote.execute
+	}
+
+	return func(job boost.Job[TraverseItemInput]) (boost.JobOutput[TraverseOutput], error) {
+		if s.throttle != nil {
+			size := lo.TernaryF(job.Input.Item.Info != nil && !job.Input.Item.IsDirectory(),
+				func() int64 { return job.Input.Item.Info.Size() },
+				func() int64 { return 0 },
+			)
+
+			release, err := s.throttle.acquire(ctx, job.Input.top, size)
+			if err != nil {
+				// the job remains outstanding, as the callback was not invoked
+				//
+				return boost.JobOutput[TraverseOutput]{
+					Payload: TraverseOutput{
+						Item:       job.Input.Item,
+						Error:      err,
+						SequenceNo: job.SequenceNo,
+					},
+				}, err
+			}
+
+			defer release()
+		}
+
+		output, err := execute(job)
+		s.ai.inflight.complete(job.Input.ticket)
+
+		return output, err
+	}
 }
 
 func workerExecutive(job boost.Job[TraverseItemInput]) (boost.JobOutput[TraverseOutput], error) {
@@ -113,8 +237,9 @@ func workerExecutive(job boost.Job[TraverseItemInput]) (boost.JobOutput[Traverse
 
 	return boost.JobOutput[TraverseOutput]{
 		Payload: TraverseOutput{
-			Item:  job.Input.Item,
-			Error: err,
+			Item:       job.Input.Item,
+			Error:      err,
+			SequenceNo: job.SequenceNo,
 		},
 	}, err
 }
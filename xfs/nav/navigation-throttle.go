@@ -0,0 +1,229 @@
+package nav
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/snivilised/extendio/internal/lo"
+)
+
+// ThrottleOptions
+type ThrottleOptions struct {
+	// ItemsPerSecond limits the rate at which the workers invoke the
+	// callback (0 denotes no limit)
+	//
+	ItemsPerSecond float64
+
+	// BytesPerSecond limits the rate at which the workers process file
+	// content, based on the size of each file item (0 denotes no limit).
+	// The limit is an approximation that assumes the callback reads the
+	// whole of the file.
+	//
+	BytesPerSecond float64
+
+	// PerTopLimit limits the number of jobs that can be executing at the
+	// same time for items beneath the same top level directory, ie a direct
+	// child folder of the root (0 denotes no limit). The root and the files
+	// directly within it count as a group of their own.
+	//
+	PerTopLimit int
+}
+
+// Throttle restricts the rate at which the workers of an accelerated
+// traversal process items. The limits can be adjusted while the session is
+// running; a change also applies to the jobs waiting to start.
+type Throttle struct {
+	mutex   sync.Mutex
+	items   rateLimiter
+	bytes   rateLimiter
+	perTop  int
+	active  map[string]int
+	changed chan struct{} // closed (and replaced) when a slot may have become available
+}
+
+// NewThrottle creates a Throttle with the limits specified.
+func NewThrottle(options *ThrottleOptions) *Throttle {
+	now := time.Now()
+	t := &Throttle{
+		perTop:  options.PerTopLimit,
+		active:  make(map[string]int),
+		changed: make(chan struct{}),
+	}
+
+	t.items.limit(options.ItemsPerSecond, now)
+	t.bytes.limit(options.BytesPerSecond, now)
+
+	return t
+}
+
+// SetItemsPerSecond adjusts the items rate limit (0 denotes no limit).
+func (t *Throttle) SetItemsPerSecond(rate float64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.items.limit(rate, time.Now())
+	t.signal()
+}
+
+// SetBytesPerSecond adjusts the bytes rate limit (0 denotes no limit).
+func (t *Throttle) SetBytesPerSecond(rate float64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.bytes.limit(rate, time.Now())
+	t.signal()
+}
+
+// SetPerTopLimit adjusts the number of jobs that can be executing at the
+// same time for items beneath the same top level directory (0 denotes no
+// limit).
+func (t *Throttle) SetPerTopLimit(limit int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.perTop = limit
+	t.signal()
+}
+
+// signal wakes the jobs waiting to start, as the limits may now permit
+// them to; must be invoked with the mutex held.
+func (t *Throttle) signal() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// acquire waits until the job for the item beneath the top directory is
+// permitted to run. The returned function must be invoked once the job
+// is complete.
+func (t *Throttle) acquire(ctx context.Context, top string, size int64) (func(), error) {
+	for {
+		t.mutex.Lock()
+
+		var delay time.Duration
+
+		if t.perTop <= 0 || t.active[top] < t.perTop {
+			now := time.Now()
+			delay = max(t.items.wait(1, now), t.bytes.wait(float64(size), now))
+
+			if delay == 0 {
+				t.items.take(1)
+				t.bytes.take(float64(size))
+				t.active[top]++
+				t.mutex.Unlock()
+
+				return func() {
+					t.release(top)
+				}, nil
+			}
+		}
+
+		changed := t.changed
+		t.mutex.Unlock()
+
+		if err := t.sleep(ctx, delay, changed); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for the delay to elapse (if there is one) or for the limits
+// to change.
+func (t *Throttle) sleep(ctx context.Context, delay time.Duration, changed <-chan struct{}) error {
+	var elapsed <-chan time.Time
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		elapsed = timer.C
+	}
+
+	select {
+	case <-elapsed:
+	case <-changed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+func (t *Throttle) release(top string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.active[top]--; t.active[top] == 0 {
+		delete(t.active, top)
+	}
+
+	t.signal()
+}
+
+// topOf returns the top level directory beneath the root, that is or
+// contains the item.
+func topOf(root string, item *TraverseItem) string {
+	relative, err := filepath.Rel(root, item.Path)
+	if err != nil || relative == "." {
+		return ""
+	}
+
+	top, _, found := strings.Cut(relative, string(filepath.Separator))
+
+	return lo.Ternary(found || item.IsDirectory(), top, "")
+}
+
+// rateLimiter is a token bucket, whose capacity is a second's worth of
+// tokens. A request for more tokens than the capacity is granted when the
+// bucket is full and puts the bucket into debt, which is repaid by the
+// delay of the requests that follow.
+type rateLimiter struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func (l *rateLimiter) limit(rate float64, now time.Time) {
+	if l.last.IsZero() {
+		l.tokens = rate
+	} else {
+		l.refill(now)
+		l.tokens = math.Min(l.tokens, rate)
+	}
+
+	l.rate = rate
+	l.last = now
+}
+
+func (l *rateLimiter) refill(now time.Time) {
+	if l.rate > 0 && !l.last.IsZero() {
+		l.tokens = math.Min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+
+	l.last = now
+}
+
+// wait returns how long to wait until n tokens can be taken.
+func (l *rateLimiter) wait(n float64, now time.Time) time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.refill(now)
+
+	if need := math.Min(n, l.rate); l.tokens < need {
+		return time.Duration((need - l.tokens) / l.rate * float64(time.Second))
+	}
+
+	return 0
+}
+
+// take removes n tokens, once wait has indicated they are available.
+func (l *rateLimiter) take(n float64) {
+	if l.rate > 0 {
+		l.tokens -= n
+	}
+}
@@ -0,0 +1,177 @@
+package nav_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/lorax/boost"
+)
+
+var _ = Describe("Throttled Pool", func() {
+	const (
+		noOfTops  = 2
+		noOfFiles = 15
+		fileSize  = 1000
+	)
+
+	var root string
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+
+		root = GinkgoT().TempDir()
+
+		for t := 0; t < noOfTops; t++ {
+			top := filepath.Join(root, fmt.Sprintf("top-%v", t))
+			Expect(os.MkdirAll(top, 0o755)).To(Succeed())
+
+			for f := 0; f < noOfFiles; f++ {
+				path := filepath.Join(top, fmt.Sprintf("%02v.flac", f))
+				Expect(os.WriteFile(path, []byte(strings.Repeat("x", fileSize)), 0o644)).To(Succeed())
+			}
+		}
+	})
+
+	// run performs a pooled traversal of the files with the throttle and
+	// returns how long it took.
+	run := func(ctx context.Context, throttle *nav.Throttle, fn nav.TraverseCallback) time.Duration {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		wgan := boost.NewAnnotatedWaitGroup("🍂 throttled traversal")
+		wgan.Add(1, navigatorRoutineName)
+
+		runner := nav.New().With(nav.RunnerWithPool, &nav.RunnerInfo{
+			PrimeInfo: &nav.Prime{
+				Path: root,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.Subscription = nav.SubscribeFiles
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "throttled callback",
+						Fn:    fn,
+					}
+				},
+			},
+			AccelerationInfo: &nav.Acceleration{
+				WgAn:        wgan,
+				RoutineName: navigatorRoutineName,
+				NoW:         6,
+				JobsChOut:   make(nav.TraverseItemJobStream, DefaultJobsChSize),
+				Throttle:    throttle,
+			},
+		})
+
+		start := time.Now()
+		_, err := runner.Run(ctx, cancel)
+		Expect(err).To(Succeed())
+
+		wgan.Wait("👾 test-main")
+
+		return time.Since(start)
+	}
+
+	noop := func(_ *nav.TraverseItem) error {
+		return nil
+	}
+
+	When("items per second limited", func() {
+		It("🧪 should: restrict rate of callback invocation", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			// the first second's worth of items are not delayed, so the
+			// remaining 10 items take 0.5 seconds
+			//
+			elapsed := run(ctx, nav.NewThrottle(&nav.ThrottleOptions{
+				ItemsPerSecond: 20,
+			}), noop)
+
+			Expect(elapsed).To(BeNumerically(">=", time.Millisecond*400))
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("bytes per second limited", func() {
+		It("🧪 should: restrict rate of content processed", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			elapsed := run(ctx, nav.NewThrottle(&nav.ThrottleOptions{
+				BytesPerSecond: 20 * fileSize,
+			}), noop)
+
+			Expect(elapsed).To(BeNumerically(">=", time.Millisecond*400))
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("per top limited", func() {
+		It("🧪 should: not exceed concurrency limit for each top directory", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			var (
+				mutex        sync.Mutex
+				active, peak = map[string]int{}, map[string]int{}
+			)
+
+			run(ctx, nav.NewThrottle(&nav.ThrottleOptions{
+				PerTopLimit: 2,
+			}), func(item *nav.TraverseItem) error {
+				top := filepath.Base(filepath.Dir(item.Path))
+
+				mutex.Lock()
+				active[top]++
+				peak[top] = max(peak[top], active[top])
+				mutex.Unlock()
+
+				time.Sleep(time.Millisecond * 5)
+
+				mutex.Lock()
+				active[top]--
+				mutex.Unlock()
+
+				return nil
+			})
+
+			Expect(peak).To(HaveLen(noOfTops))
+
+			for top, n := range peak {
+				Expect(n).To(BeNumerically("<=", 2), top)
+			}
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("limit adjusted while running", func() {
+		It("🧪 should: apply new limit to waiting items", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			throttle := nav.NewThrottle(&nav.ThrottleOptions{
+				ItemsPerSecond: 1,
+			})
+			once := sync.Once{}
+
+			// at 1 item per second, the traversal would take 30 seconds
+			//
+			elapsed := run(ctx, throttle, func(_ *nav.TraverseItem) error {
+				once.Do(func() {
+					throttle.SetItemsPerSecond(0)
+				})
+
+				return nil
+			})
+
+			Expect(elapsed).To(BeNumerically("<", time.Second))
+		}, SpecTimeout(time.Second*10))
+	})
+})
@@ -0,0 +1,323 @@
+package nav
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/snivilised/lorax/boost"
+)
+
+const (
+	transportRoutineName = boost.GoRoutineName("📮 transport")
+)
+
+// JobTransport conveys the jobs of an accelerated traversal to executors
+// outside of the process and returns their results, so that the client
+// callback can be isolated from the navigator (eg to contain a crash prone
+// media decoder). The pool workers submit jobs concurrently, so Send must
+// be safe for concurrent use. The results may be returned in any order.
+type JobTransport interface {
+	// Send submits the job to the executors.
+	Send(job *JobEnvelope) error
+
+	// Receive returns the next result from the executors; an error denotes
+	// that no more results are available.
+	Receive() (*ResultEnvelope, error)
+
+	// Close signifies that no more jobs will be sent; once the executors have
+	// returned all their results, Receive should return an error.
+	Close() error
+}
+
+// JobEnvelope is the serialisable form of a job, sent by a JobTransport.
+type JobEnvelope struct {
+	ID    string        `json:"id"`
+	Label string        `json:"label,omitempty"`
+	Item  *ItemEnvelope `json:"item"`
+}
+
+// ResultEnvelope is the serialisable form of the result of a job, returned
+// by a JobTransport.
+type ResultEnvelope struct {
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+// ItemEnvelope is the serialisable form of a TraverseItem. It contains the
+// path, the file info and the extension of the item; the Children and the
+// Custom extension are not conveyed.
+type ItemEnvelope struct {
+	Path      string            `json:"path"`
+	Name      string            `json:"name"`
+	IsDir     bool              `json:"dir,omitempty"`
+	Size      int64             `json:"size"`
+	Mode      fs.FileMode       `json:"mode"`
+	ModTime   time.Time         `json:"modTime"`
+	Depth     int               `json:"depth"`
+	IsLeaf    bool              `json:"leaf,omitempty"`
+	Parent    string            `json:"parent"`
+	SubPath   string            `json:"subPath"`
+	NodeScope FilterScopeBiEnum `json:"scope"`
+	Error     string            `json:"error,omitempty"`
+}
+
+func newItemEnvelope(item *TraverseItem) *ItemEnvelope {
+	envelope := &ItemEnvelope{
+		Path:      item.Path,
+		IsDir:     item.IsDirectory(),
+		Depth:     item.Extension.Depth,
+		IsLeaf:    item.Extension.IsLeaf,
+		Name:      item.Extension.Name,
+		Parent:    item.Extension.Parent,
+		SubPath:   item.Extension.SubPath,
+		NodeScope: item.Extension.NodeScope,
+	}
+
+	info := item.Info
+	if info == nil && item.Entry != nil {
+		info, _ = item.Entry.Info()
+	}
+
+	if info != nil {
+		envelope.Name = info.Name()
+		envelope.Size = info.Size()
+		envelope.Mode = info.Mode()
+		envelope.ModTime = info.ModTime()
+	}
+
+	if item.Error != nil {
+		envelope.Error = item.Error.Error()
+	}
+
+	return envelope
+}
+
+// Item re-creates the TraverseItem, for the executor.
+func (e *ItemEnvelope) Item() *TraverseItem {
+	info := &envelopeInfo{envelope: e}
+	item := &TraverseItem{
+		Path:     e.Path,
+		Entry:    fs.FileInfoToDirEntry(info),
+		Info:     info,
+		Children: []fs.DirEntry{},
+		Extension: ExtendedItem{
+			Depth:     e.Depth,
+			IsLeaf:    e.IsLeaf,
+			Name:      e.Name,
+			Parent:    e.Parent,
+			SubPath:   e.SubPath,
+			NodeScope: e.NodeScope,
+		},
+		dir: e.IsDir,
+	}
+
+	if e.Error != "" {
+		item.Error = errors.New(e.Error)
+	}
+
+	return item
+}
+
+// envelopeInfo is the file info of an item re-created from an envelope.
+type envelopeInfo struct {
+	envelope *ItemEnvelope
+}
+
+func (i *envelopeInfo) Name() string       { return i.envelope.Name }
+func (i *envelopeInfo) Size() int64        { return i.envelope.Size }
+func (i *envelopeInfo) Mode() fs.FileMode  { return i.envelope.Mode }
+func (i *envelopeInfo) ModTime() time.Time { return i.envelope.ModTime }
+func (i *envelopeInfo) IsDir() bool        { return i.envelope.IsDir }
+func (i *envelopeInfo) Sys() any           { return nil }
+
+// remoteExecutor executes the jobs of the pool workers via the transport.
+// Each worker waits for the result of its job, which is routed back to
+// it by the receiver.
+type remoteExecutor struct {
+	transport JobTransport
+	mutex     sync.Mutex
+	waiting   map[string]chan *ResultEnvelope
+	err       error // set once the transport can no longer return results
+}
+
+func newRemoteExecutor(transport JobTransport) *remoteExecutor {
+	return &remoteExecutor{
+		transport: transport,
+		waiting:   make(map[string]chan *ResultEnvelope),
+	}
+}
+
+// execute invokes the decorated callback of the job, whose terminal
+// forwards the item to the executors, so only the items that get through
+// the decorators are sent over the transport.
+func (e *remoteExecutor) execute(job boost.Job[TraverseItemInput]) (boost.JobOutput[TraverseOutput], error) {
+	job.Input.Item.forward = func(_ *TraverseItem) error {
+		return e.submit(job)
+	}
+	err := job.Input.Fn(job.Input.Item)
+	job.Input.Item.forward = nil
+
+	return boost.JobOutput[TraverseOutput]{
+		Payload: TraverseOutput{
+			Item:       job.Input.Item,
+			Error:      err,
+			SequenceNo: job.SequenceNo,
+		},
+	}, err
+}
+
+func (e *remoteExecutor) submit(job boost.Job[TraverseItemInput]) error {
+	result := make(chan *ResultEnvelope, 1)
+
+	e.mutex.Lock()
+	if e.err != nil {
+		e.mutex.Unlock()
+
+		return e.err
+	}
+	e.waiting[job.ID] = result
+	e.mutex.Unlock()
+
+	if err := e.transport.Send(&JobEnvelope{
+		ID:    job.ID,
+		Label: job.Input.Label,
+		Item:  newItemEnvelope(job.Input.Item),
+	}); err != nil {
+		e.mutex.Lock()
+		delete(e.waiting, job.ID)
+		e.mutex.Unlock()
+
+		return fmt.Errorf("%w (%w)", ErrJobTransportFailed, err)
+	}
+
+	envelope, ok := <-result
+	if !ok {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+
+		return e.err
+	}
+
+	if envelope.Error != "" {
+		return errors.New(envelope.Error)
+	}
+
+	return nil
+}
+
+// receive routes the results to the workers waiting for them, until the
+// transport has no more results.
+func (e *remoteExecutor) receive() {
+	for {
+		envelope, err := e.transport.Receive()
+		if err != nil {
+			e.mutex.Lock()
+			defer e.mutex.Unlock()
+
+			e.err = fmt.Errorf("%w (%w)", ErrJobTransportFailed, err)
+
+			for id, result := range e.waiting {
+				close(result)
+				delete(e.waiting, id)
+			}
+
+			return
+		}
+
+		e.mutex.Lock()
+		result, found := e.waiting[envelope.ID]
+		delete(e.waiting, envelope.ID)
+		e.mutex.Unlock()
+
+		if found {
+			result <- envelope
+		}
+	}
+}
+
+// NewPipeJobTransport creates a JobTransport that exchanges jobs and results
+// as JSON over the connection, whose other end is served by ServeJobs.
+func NewPipeJobTransport(conn io.ReadWriteCloser) JobTransport {
+	return &pipeTransport{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}
+}
+
+// CommandJobTransport runs the command as the executor process, which
+// serves the jobs over its stdin and stdout, eg by invoking
+// ServeJobs(nav.StdioConn(), fn).
+func CommandJobTransport(ctx context.Context, name string, args ...string) (JobTransport, error) {
+	conn, err := startCommand(ctx, name, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPipeJobTransport(conn), nil
+}
+
+type pipeTransport struct {
+	conn    io.ReadWriteCloser
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	decoder *json.Decoder
+}
+
+func (t *pipeTransport) Send(job *JobEnvelope) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.encoder.Encode(job)
+}
+
+func (t *pipeTransport) Receive() (*ResultEnvelope, error) {
+	envelope := &ResultEnvelope{}
+	if err := t.decoder.Decode(envelope); err != nil {
+		return nil, err
+	}
+
+	return envelope, nil
+}
+
+func (t *pipeTransport) Close() error {
+	return t.conn.Close()
+}
+
+// ServeJobs executes the jobs received over the connection from a pipe
+// transport with the callback, until the connection is closed. It is
+// invoked by the executor process. When the connection is the process's
+// stdout, the callback must not write to it.
+func ServeJobs(conn io.ReadWriter, fn TraverseCallback) error {
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+
+	for {
+		job := &JobEnvelope{}
+		if err := decoder.Decode(job); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		result := &ResultEnvelope{
+			ID: job.ID,
+		}
+
+		if err := fn(job.Item.Item()); err != nil {
+			result.Error = err.Error()
+		}
+
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package nav_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/lorax/boost"
+)
+
+const jobExecutorEnv = "NAV_JOB_EXECUTOR"
+
+// TestJobExecutor is not a test, but the executor process launched by the
+// transport specs, which re-run the test binary.
+func TestJobExecutor(t *testing.T) {
+	if _, found := os.LookupEnv(jobExecutorEnv); !found {
+		t.Skip("only runs as a job executor")
+	}
+
+	if err := nav.ServeJobs(nav.StdioConn(), func(item *nav.TraverseItem) error {
+		if item.Info == nil || item.Info.Name() != item.Extension.Name {
+			return fmt.Errorf("incomplete item: '%v'", item.Path)
+		}
+
+		return nil
+	}); err != nil {
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+var _ = Describe("Job Transport", Ordered, func() {
+	var (
+		root string
+		path string
+	)
+
+	BeforeAll(func() {
+		root = musico()
+		path = helpers.Path(root, "RETRO-WAVE")
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	// run performs a pooled traversal, whose jobs are executed via the
+	// transport and returns the outputs indexed by path.
+	run := func(ctxSpec SpecContext, transport nav.JobTransport,
+		optionsFn ...nav.TraverseOptionFn,
+	) map[string]nav.TraverseOutput {
+		ctx, cancel := context.WithCancel(ctxSpec)
+		defer cancel()
+
+		wgan := boost.NewAnnotatedWaitGroup("🍂 transported traversal")
+		wgan.Add(1, navigatorRoutineName)
+		outputCh := nav.CreateTraverseOutputCh(DefaultOutputsChSize)
+
+		runner := nav.New().With(nav.RunnerWithPool, &nav.RunnerInfo{
+			PrimeInfo: &nav.Prime{
+				Path: path,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.Subscription = nav.SubscribeAny
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "local callback",
+						Fn: func(item *nav.TraverseItem) error {
+							return fmt.Errorf("invoked locally: '%v'", item.Path)
+						},
+					}
+
+					for _, fn := range optionsFn {
+						fn(o)
+					}
+				},
+			},
+			AccelerationInfo: &nav.Acceleration{
+				WgAn:            wgan,
+				RoutineName:     navigatorRoutineName,
+				NoW:             4,
+				JobsChOut:       make(nav.TraverseItemJobStream, DefaultJobsChSize),
+				JobResultsCh:    outputCh,
+				OutputChTimeout: outputChTimeout,
+				Transport:       transport,
+			},
+		})
+
+		outputs := map[string]nav.TraverseOutput{}
+		consumed := make(chan struct{})
+
+		go func() {
+			defer close(consumed)
+
+			for output := range outputCh {
+				outputs[output.Payload.Item.Path] = output.Payload
+			}
+		}()
+
+		_, err := runner.Run(ctx, cancel)
+		Expect(err).To(Succeed())
+
+		wgan.Wait("👾 test-main")
+		<-consumed
+
+		return outputs
+	}
+
+	When("executor in process", func() {
+		It("🧪 should: execute the jobs remotely", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			var (
+				mutex    sync.Mutex
+				executed = map[string]*nav.TraverseItem{}
+				failing  = helpers.Path(root, ResumeAtTeenageColor)
+			)
+
+			conn, executor := net.Pipe()
+			served := make(chan struct{})
+
+			go func() {
+				defer close(served)
+				defer executor.Close()
+
+				_ = nav.ServeJobs(executor, func(item *nav.TraverseItem) error {
+					mutex.Lock()
+					executed[item.Path] = item
+					mutex.Unlock()
+
+					if item.Path == failing {
+						return errors.New("decoder crashed")
+					}
+
+					return nil
+				})
+			}()
+
+			outputs := run(ctx, nav.NewPipeJobTransport(conn))
+			<-served
+
+			Expect(outputs).To(HaveLen(len(executed)))
+			Expect(outputs[failing].Error).To(MatchError("decoder crashed"))
+
+			for path, output := range outputs {
+				item := executed[path]
+				Expect(item).NotTo(BeNil(), path)
+				Expect(item.IsDirectory()).To(Equal(output.Item.IsDirectory()), path)
+				Expect(item.Extension.SubPath).To(Equal(output.Item.Extension.SubPath), path)
+				Expect(item.Info.Size()).To(Equal(output.Item.Info.Size()), path)
+
+				if path != failing {
+					Expect(output.Error).To(Succeed(), path)
+				}
+			}
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("filtered", func() {
+		It("🧪 should: only send the items admitted by the filter", func(ctx SpecContext) {
+			defer leaktest.Check(GinkgoT())()
+
+			var (
+				mutex    sync.Mutex
+				executed = map[string]*nav.TraverseItem{}
+			)
+
+			conn, executor := net.Pipe()
+			served := make(chan struct{})
+
+			go func() {
+				defer close(served)
+				defer executor.Close()
+
+				_ = nav.ServeJobs(executor, func(item *nav.TraverseItem) error {
+					mutex.Lock()
+					executed[item.Path] = item
+					mutex.Unlock()
+
+					return nil
+				})
+			}()
+
+			outputs := run(ctx, nav.NewPipeJobTransport(conn), func(o *nav.TraverseOptions) {
+				o.Store.Subscription = nav.SubscribeFiles
+				o.Store.FilterDefs = &nav.FilterDefinitions{
+					Node: nav.FilterDef{
+						Type:        nav.FilterTypeGlobEn,
+						Description: "flac files",
+						Pattern:     "*.flac",
+						Scope:       nav.ScopeFileEn,
+					},
+				}
+			})
+			<-served
+
+			Expect(executed).To(HaveLen(8))
+			Expect(outputs).To(HaveLen(14))
+
+			for path := range executed {
+				Expect(path).To(HaveSuffix(".flac"))
+			}
+
+			for path, output := range outputs {
+				Expect(output.Error).To(Succeed(), path)
+			}
+		}, SpecTimeout(time.Second*5))
+	})
+
+	When("executor process", func() {
+		It("🧪 should: execute the jobs in the process", func(ctx SpecContext) {
+			Expect(os.Setenv(jobExecutorEnv, "1")).To(Succeed())
+			DeferCleanup(os.Unsetenv, jobExecutorEnv)
+
+			transport, err := nav.CommandJobTransport(ctx, os.Args[0], "-test.run=^TestJobExecutor$")
+			Expect(err).To(Succeed())
+
+			outputs := run(ctx, transport)
+			Expect(outputs).NotTo(BeEmpty())
+
+			for path, output := range outputs {
+				Expect(output.Error).To(Succeed(), path)
+			}
+		}, SpecTimeout(time.Second*20))
+	})
+})
@@ -0,0 +1,247 @@
+package nav
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// watcher creates the watcher for the root, if watch mode is enabled. The
+// watcher is created before the traversal starts, so that changes made
+// during the traversal are not missed. If the native watcher can't be
+// created (eg because the inotify watch limit has been reached), the
+// polling watcher is used instead.
+func (nc *navigationController) watcher(root string) watcher {
+	o := nc.impl.options()
+
+	if !o.Watch.enabled() {
+		return nil
+	}
+
+	if o.Watch.FS == nil && !o.Watch.Poll {
+		w, err := newNativeWatcher(root)
+		if err == nil {
+			return w
+		}
+
+		if !errors.Is(err, errors.ErrUnsupported) {
+			nc.logger().Warn("native watcher not available, polling instead",
+				slog.String("root", root),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	vfs := o.Watch.FS
+	if vfs == nil {
+		vfs = storage.UseNativeFS()
+	}
+
+	interval := lo.Ternary(o.Watch.Interval > 0, o.Watch.Interval, DefaultWatchPollInterval)
+
+	return newPollingWatcher(vfs, root, interval)
+}
+
+// watch delivers the changes detected by the watcher to the client, until
+// the context is done, or the callback returns fs.SkipAll, or another error,
+// which is returned. As in the traversal, fs.SkipDir is ignored.
+func (nc *navigationController) watch(w watcher) error {
+	ctx := nc.impl.options().Watch.Context
+
+	for {
+		events, err := w.next(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 && ctx.Err() != nil {
+			return nil
+		}
+
+		for i := range events {
+			event := &events[i]
+
+			if nc.frame.incremental != nil {
+				switch event.kind {
+				case WatchEventDeleteEn:
+					nc.frame.incremental.forget(event.path)
+				case WatchEventRenameEn:
+					nc.frame.incremental.forget(event.from)
+				case WatchEventNoneEn, WatchEventCreateEn, WatchEventModifyEn, WatchEventOverflowEn:
+				}
+			}
+
+			item, ok := nc.watched(event)
+			if !ok {
+				continue
+			}
+
+			if err := nc.frame.proxy(item, nil); err != nil {
+				if errors.Is(err, fs.SkipDir) {
+					continue
+				}
+
+				if errors.Is(err, fs.SkipAll) {
+					return nil
+				}
+
+				return err
+			}
+		}
+	}
+}
+
+// watched creates the item that represents the event, if it should be
+// delivered, according to the subscription and depth limit.
+func (nc *navigationController) watched(event *watchEvent) (*TraverseItem, bool) {
+	o := nc.impl.options()
+	root := nc.frame.root.Get()
+
+	if event.kind == WatchEventOverflowEn {
+		return nc.overflowed(event), true
+	}
+
+	relative, err := filepath.Rel(root, event.path)
+	if err != nil || relative == "." || relative == ".." ||
+		strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return nil, false
+	}
+
+	depth := len(strings.Split(relative, string(filepath.Separator)))
+
+	if limit := o.Store.Behaviours.Cascade.Depth; limit > 0 && depth > int(limit) {
+		return nil, false
+	}
+
+	var info fs.FileInfo
+
+	if event.kind != WatchEventDeleteEn {
+		if info, err = o.Hooks.QueryStatus(event.path); err != nil {
+			// the item has already gone again
+			//
+			return nil, false
+		}
+	}
+
+	item := newTraverseItem(event.path, nil, info, nil, nil)
+
+	if info == nil {
+		item.dir = event.isDir
+	}
+
+	switch o.Store.Subscription {
+	case SubscribeFiles:
+		if item.IsDirectory() {
+			return nil, false
+		}
+	case SubscribeFolders, SubscribeFoldersWithFiles:
+		if !item.IsDirectory() {
+			return nil, false
+		}
+	case SubscribeAny:
+	}
+
+	scope := ScopeLeafEn | ScopeFileEn
+	isLeaf := true
+
+	if item.IsDirectory() {
+		if info != nil {
+			contents := nc.contents(event.path)
+			isLeaf = len(contents.Folders) == 0
+
+			if o.Store.Subscription == SubscribeFoldersWithFiles {
+				item.Children = nc.children(contents)
+			}
+		}
+
+		scope = scopeAt(depth, isLeaf) | ScopeFolderEn
+	}
+
+	parent, name := filepath.Split(event.path)
+	item.Extension = ExtendedItem{
+		Depth:       depth,
+		IsLeaf:      isLeaf,
+		Name:        name,
+		Parent:      parent,
+		NodeScope:   scope,
+		Event:       event.kind,
+		RenamedFrom: event.from,
+		digests:     nc.frame.digests(item),
+	}
+	item.Extension.SubPath = subPathOf(o, root, item)
+
+	return item, true
+}
+
+// overflowed creates the item that reports the loss of events, which
+// represents the root. It bypasses the filters, as the client could not
+// otherwise know that the tree needs to be re-scanned.
+func (nc *navigationController) overflowed(event *watchEvent) *TraverseItem {
+	o := nc.impl.options()
+	info, _ := o.Hooks.QueryStatus(event.path)
+	item := newTraverseItem(event.path, nil, info, nil, nil)
+	item.dir = true
+	item.admit = true
+
+	parent, name := filepath.Split(event.path)
+	item.Extension = ExtendedItem{
+		Name:      name,
+		Parent:    parent,
+		NodeScope: ScopeRootEn | ScopeFolderEn,
+		Event:     event.kind,
+	}
+	item.Extension.SubPath = subPathOf(o, nc.frame.root.Get(), item)
+
+	return item
+}
+
+func (nc *navigationController) contents(path string) *DirectoryContents {
+	o := nc.impl.options()
+	entries, _ := o.Hooks.ReadDirectory(path)
+
+	return newDirectoryContents(&newDirectoryContentsParams{
+		o:       o,
+		entries: entries,
+	})
+}
+
+func (nc *navigationController) children(contents *DirectoryContents) []fs.DirEntry {
+	files := contents.Files
+
+	if nc.frame.filters != nil && nc.frame.filters.Children != nil {
+		files = nc.frame.filters.Children.Matching(files)
+	}
+
+	contents.sort(files)
+
+	return files
+}
+
+// dedupeWatchEvents removes the repeated events for the same item, that
+// occur, for example, when a file is written in multiple chunks. An item
+// created in the same batch is not also reported as modified.
+func dedupeWatchEvents(events []watchEvent) []watchEvent {
+	type key struct {
+		kind WatchEventEnum
+		path string
+	}
+
+	seen := make(map[key]bool, len(events))
+
+	return lo.Filter(events, func(event watchEvent, _ int) bool {
+		if event.kind == WatchEventModifyEn && seen[key{WatchEventCreateEn, event.path}] {
+			return false
+		}
+
+		k := key{event.kind, event.path}
+		duplicate := seen[k]
+		seen[k] = true
+
+		return !duplicate
+	})
+}
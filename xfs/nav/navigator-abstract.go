@@ -47,6 +47,11 @@ func (n *navigator) ensync(
 	ai *AsyncInfo,
 ) {
 	decorated := frame.client
+	frame.inflight = ai.inflight
+
+	// the items are handed over to the pool workers, so can't be recycled
+	//
+	n.agent.recycler = nil
 	decorator := &LabelledTraverseCallback{
 		Label: "boost decorator",
 		Fn: func(item *TraverseItem) error {
@@ -63,7 +68,33 @@ func (n *navigator) ensync(
 				}
 			}()
 
+			// the fast forward decision has to be made here, on the navigator, because
+			// the resume point is the earliest job that did not complete, so the items
+			// before it must not become jobs, otherwise a worker could detach while
+			// other workers are still handling those items.
+			//
+			if frame.listener.state == ListenFastward && frame.listener.fastward(frame, item) {
+				return nil
+			}
+
 			var err error
+
+			sequenceNo := -999
+
+			if ai.sequencer != nil {
+				no, stalled, ok := ai.sequencer.admit(ctx)
+
+				if stalled {
+					frame.metrics.tick(MetricNoReorderStallsEn)
+				}
+
+				if !ok {
+					return fs.SkipDir
+				}
+
+				sequenceNo = no
+			}
+
 			select {
 			case <-ctx.Done():
 				err = fs.SkipDir
@@ -75,7 +106,19 @@ func (n *navigator) ensync(
 						Fn:    decorated.Fn,
 						Label: decorated.Label,
 					},
-					SequenceNo: -999,
+					SequenceNo: sequenceNo,
+				}
+
+				if ai.throttle != nil {
+					job.Input.top = topOf(frame.root.Get(), item)
+				}
+
+				if ai.inflight != nil {
+					job.Input.ticket = ai.inflight.dispatch(item)
+				}
+
+				if ai.launch != nil {
+					ai.launch()
 				}
 
 				select {
@@ -101,6 +144,10 @@ func (n *navigator) logger() *slog.Logger {
 }
 
 func (n *navigator) descend(navi *NavigationInfo) bool {
+	if navi.frame.incremental != nil && navi.frame.incremental.skip(navi.Item) {
+		return false
+	}
+
 	if !navi.frame.periscope.descend(n.o.Store.Behaviours.Cascade.Depth) {
 		return false
 	}
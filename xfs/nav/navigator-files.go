@@ -21,17 +21,14 @@ func (n *filesNavigator) top(frame *navigationFrame, root string) (*TraverseResu
 }
 
 func (n *filesNavigator) inspect(params *traverseParams) *inspection {
-	stash := &inspection{
-		current: params.current,
-		isDir:   params.current.IsDirectory(),
-	}
+	stash := n.agent.inspection(params.current)
 
 	if stash.isDir {
-		stash.contents, stash.readErr = n.agent.read(params.current.Path)
+		stash.contents, stash.readErr = n.agent.read(params.current.Path, params.frame)
 		stash.contents.sort(stash.contents.Files)
 		stash.contents.sort(stash.contents.Folders)
 	} else {
-		stash.clearContents(n.o)
+		stash.contents = n.agent.contents(nil)
 	}
 
 	n.o.Hooks.Extend(params.navi, stash.contents)
@@ -66,6 +63,7 @@ func (n *filesNavigator) traverse(params *traverseParams) (*TraverseItem, error)
 	}
 
 	stash := n.inspect(params)
+	defer n.agent.releaseInspection(stash)
 
 	if !stash.isDir {
 		// Effectively, this is the file only filter
@@ -91,9 +89,10 @@ func (n *filesNavigator) traverse(params *traverseParams) (*TraverseItem, error)
 	}
 
 	return n.agent.traverse(&agentTraverseParams{
-		impl:    n,
-		entries: entries,
-		parent:  params.current,
-		frame:   params.frame,
+		impl:     n,
+		entries:  entries,
+		parent:   params.current,
+		frame:    params.frame,
+		streamed: stash.streamed(),
 	})
 }
@@ -27,16 +27,13 @@ func (n *foldersNavigator) top(frame *navigationFrame, root string) (*TraverseRe
 }
 
 func (n *foldersNavigator) inspect(params *traverseParams) *inspection {
-	stash := &inspection{
-		current: params.current,
-		isDir:   params.current.IsDirectory(),
-	}
+	stash := n.agent.inspection(params.current)
 
 	// for the folders navigator, we ignore the user defined setting in
 	// n.o.Store.Behaviours.Sort.DirectoryEntryOrder, as we're only interested in
 	// folders and therefore force to use DirectoryEntryOrderFoldersFirstEn instead
 	//
-	stash.contents, stash.readErr = n.agent.read(params.current.Path)
+	stash.contents, stash.readErr = n.agent.read(params.current.Path, params.frame)
 	stash.entries = stash.contents.Folders
 	stash.contents.sort(stash.entries)
 
@@ -93,6 +90,7 @@ func (n *foldersNavigator) traverse(params *traverseParams) (*TraverseItem, erro
 	}
 
 	stash := n.inspect(params)
+	defer n.agent.releaseInspection(stash)
 	entries := stash.entries
 
 	if n.samplingActive {
@@ -114,9 +112,10 @@ func (n *foldersNavigator) traverse(params *traverseParams) (*TraverseItem, erro
 	}
 
 	return n.agent.traverse(&agentTraverseParams{
-		impl:    n,
-		entries: entries,
-		parent:  params.current,
-		frame:   params.frame,
+		impl:     n,
+		entries:  entries,
+		parent:   params.current,
+		frame:    params.frame,
+		streamed: stash.streamed(),
 	})
 }
@@ -21,20 +21,17 @@ func (n *universalNavigator) top(frame *navigationFrame, root string) (*Traverse
 }
 
 func (n *universalNavigator) inspect(params *traverseParams) *inspection {
-	stash := &inspection{
-		current: params.current,
-		isDir:   params.current.IsDirectory(),
-	}
+	stash := n.agent.inspection(params.current)
 
 	if stash.isDir {
-		stash.contents, stash.readErr = n.agent.read(params.current.Path)
+		stash.contents, stash.readErr = n.agent.read(params.current.Path, params.frame)
 
 		stash.contents.sort(stash.contents.Files)
 		stash.contents.sort(stash.contents.Folders)
 
 		stash.entries = stash.contents.All()
 	} else {
-		stash.clearContents(n.o)
+		stash.contents = n.agent.contents(nil)
 	}
 
 	n.o.Hooks.Extend(params.navi, stash.contents)
@@ -65,6 +62,7 @@ func (n *universalNavigator) traverse(params *traverseParams) (*TraverseItem, er
 	}
 
 	stash := n.inspect(params)
+	defer n.agent.releaseInspection(stash)
 	entries := stash.entries
 
 	if stash.isDir {
@@ -90,9 +88,10 @@ func (n *universalNavigator) traverse(params *traverseParams) (*TraverseItem, er
 	}
 
 	return n.agent.traverse(&agentTraverseParams{
-		impl:    n,
-		entries: entries,
-		parent:  params.current,
-		frame:   params.frame,
+		impl:     n,
+		entries:  entries,
+		parent:   params.current,
+		frame:    params.frame,
+		streamed: stash.streamed(),
 	})
 }
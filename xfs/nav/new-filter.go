@@ -27,6 +27,13 @@ func fromExtendedGlobPattern(pattern string) (segments, suffixes []string, err e
 	return segments, suffixes, nil
 }
 
+// NewNodeFilter creates the node filter described by the definition provided.
+// This allows the same FilterDefs used by the navigator, to be applied by
+// components that visit the file system without a navigator.
+func NewNodeFilter(def *FilterDef) TraverseFilter {
+	return newNodeFilter(def)
+}
+
 func newNodeFilter(def *FilterDef) TraverseFilter {
 	var (
 		filter             TraverseFilter
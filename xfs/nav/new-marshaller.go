@@ -11,11 +11,7 @@ type marshallerFactory struct{}
 func (m *marshallerFactory) new(o *TraverseOptions, state *persistState) stateMarshaller {
 	var marshaller stateMarshaller
 
-	if o.Persist.Format != PersistInJSONEn {
-		panic(i18n.NewUnknownMarshalFormatError(
-			fmt.Sprintf("%v", o.Persist.Format), "Options/Persist/Format",
-		))
-	}
+	m.validate(o)
 
 	marshaller = &stateMarshallerJSON{
 		o:  o,
@@ -24,3 +20,21 @@ func (m *marshallerFactory) new(o *TraverseOptions, state *persistState) stateMa
 
 	return marshaller
 }
+
+// index creates the marshaller of the index of an incremental traversal,
+// which is persisted in the same format as the resume state.
+func (m *marshallerFactory) index(o *TraverseOptions, index *persistIndex) stateMarshaller {
+	m.validate(o)
+
+	return &indexMarshallerJSON{
+		index: index,
+	}
+}
+
+func (m *marshallerFactory) validate(o *TraverseOptions) {
+	if o.Persist.Format != PersistInJSONEn {
+		panic(i18n.NewUnknownMarshalFormatError(
+			fmt.Sprintf("%v", o.Persist.Format), "Options/Persist/Format",
+		))
+	}
+}
@@ -53,6 +53,8 @@ func (f navigatorImplFactory) new(o *TraverseOptions) navigatorImpl {
 			doInvoke:             doInvoke,
 			handler:              &notifyCallbackErrorHandler{},
 			samplingFilterActive: samplingFilterActive,
+			streaming:            o.isStreaming(),
+			recycling:            o.isRecycling(),
 		})
 		n = navigator{
 			o:                    o,
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/storage"
 )
 
 // ReadEntriesHookFn reads the contents of a directory. The resulting
@@ -21,7 +22,39 @@ func ReadEntriesHookFn(dirname string) ([]fs.DirEntry, error) {
 		return nil, err
 	}
 
+	return withoutSystemEntries(contents), nil
+}
+
+// OpenEntriesHookFn opens a directory, for its entries to be streamed.
+func OpenEntriesHookFn(dirname string) (DirectoryStream, error) {
+	return os.Open(dirname)
+}
+
+// VirtualReadEntriesHookFn creates a Read Directory hook function that reads
+// the contents of a directory from the virtual file system provided. As with
+// ReadEntriesHookFn, the resulting slice is left un-sorted.
+func VirtualReadEntriesHookFn(vfs storage.ReadOnlyVirtualFS) ReadDirectoryHookFn {
+	return func(dirname string) ([]fs.DirEntry, error) {
+		contents, err := vfs.ReadDir(dirname)
+		if err != nil {
+			return nil, err
+		}
+
+		return withoutSystemEntries(contents), nil
+	}
+}
+
+// VirtualOpenEntriesHookFn creates an Open Directory hook function that opens
+// a directory of the virtual file system provided, for its entries to be
+// streamed.
+func VirtualOpenEntriesHookFn(vfs storage.ReadOnlyVirtualFS) OpenDirectoryHookFn {
+	return func(dirname string) (DirectoryStream, error) {
+		return vfs.Open(dirname)
+	}
+}
+
+func withoutSystemEntries(contents []fs.DirEntry) []fs.DirEntry {
 	return lo.Filter(contents, func(item fs.DirEntry, _ int) bool {
 		return item.Name() != ".DS_Store"
-	}), nil
+	})
 }
@@ -17,6 +17,7 @@ const (
 	notificationAscendEn
 	notificationStartEn
 	notificationStopEn
+	notificationRemovedEn
 	notificationAllEn = math.MaxUint32
 )
 
@@ -72,6 +73,17 @@ func (s *switchableListen) invoke(description string) {
 	}
 }
 
+type switchableRemoved struct {
+	switchableBase
+	handler RemovedHandler
+}
+
+func (s *switchableRemoved) invoke(item *RemovedItem) {
+	if !s.muted {
+		s.handler(item)
+	}
+}
+
 type switchable map[notificationBiEnum]*switchableBase
 
 type notificationsSink struct {
@@ -81,6 +93,7 @@ type notificationsSink struct {
 	ascend  switchableAscendancy
 	start   switchableListen
 	stop    switchableListen
+	removed switchableRemoved
 	all     switchable
 }
 
@@ -103,6 +116,9 @@ func (n *notificationsSink) init(notifications *Notifications) {
 	n.stop = switchableListen{
 		handler: notifications.OnStop,
 	}
+	n.removed = switchableRemoved{
+		handler: notifications.OnRemoved,
+	}
 	n.all = switchable{
 		notificationBeginEn:   &n.begin.switchableBase,
 		notificationEndEn:     &n.end.switchableBase,
@@ -110,6 +126,7 @@ func (n *notificationsSink) init(notifications *Notifications) {
 		notificationAscendEn:  &n.ascend.switchableBase,
 		notificationStartEn:   &n.start.switchableBase,
 		notificationStopEn:    &n.stop.switchableBase,
+		notificationRemovedEn: &n.removed.switchableBase,
 	}
 }
 
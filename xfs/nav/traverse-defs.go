@@ -37,6 +37,18 @@ type BeginHandler func(ns *NavigationState)
 // EndHandler life cycle event handler, invoked at end of traversal
 type EndHandler func(result *TraverseResult)
 
+// RemovedItem describes an item, recorded in the index of a previous
+// incremental traversal, which no longer exists.
+type RemovedItem struct {
+	Path        string
+	SubPath     string // the path relative to the root
+	IsDirectory bool
+}
+
+// RemovedHandler invoked for each item found to have been removed since
+// the previous incremental traversal
+type RemovedHandler func(item *RemovedItem)
+
 // TraverseResult the result of the traversal process.
 type TraverseResult struct {
 	Session Session
@@ -44,6 +56,14 @@ type TraverseResult struct {
 	err     error
 }
 
+// Err returns the error reported through the result after it was returned,
+// eg failing to persist the hash cache once the worker pool of an
+// accelerated traversal has drained; so it should only be checked after the
+// wait group has been waited on.
+func (r *TraverseResult) Err() error {
+	return r.err
+}
+
 func (r *TraverseResult) merge(other *TraverseResult) (*TraverseResult, error) {
 	if !utils.IsNil(other.err) {
 		r.err = other.err
@@ -77,6 +97,7 @@ type Resumption struct {
 
 type syncable interface {
 	ensync(ctx context.Context, cancel context.CancelFunc, ai *AsyncInfo)
+	drained()
 }
 
 // TraverseNavigator interface to the main traverse instance.
@@ -148,12 +169,10 @@ type inspection struct {
 	compoundCounts *compoundCounters
 }
 
-func (i *inspection) clearContents(o *TraverseOptions) {
-	if i.contents != nil {
-		i.contents.clear()
-	} else {
-		i.contents = newEmptyDirectoryEntries(o)
-	}
+// streamed denotes that the entries of the directory are to be read as
+// they are navigated.
+func (i *inspection) streamed() bool {
+	return i.contents != nil && i.contents.streamed
 }
 
 type itemSubPath = string
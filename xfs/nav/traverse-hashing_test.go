@@ -0,0 +1,265 @@
+package nav_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/hashing"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+	"github.com/snivilised/lorax/boost"
+)
+
+var _ = Describe("Traverse With Hashing", func() {
+	var (
+		root, cache string
+		contents    map[string]string
+	)
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+
+		root = GinkgoT().TempDir()
+		cache = filepath.Join(GinkgoT().TempDir(), "digests.json")
+		contents = map[string]string{
+			filepath.Join(root, "a.flac"):          "alpha",
+			filepath.Join(root, "rock", "b.flac"):  "bravo",
+			filepath.Join(root, "rock", "c.flac"):  "charlie",
+			filepath.Join(root, "jazz", "d.flac"):  "delta",
+			filepath.Join(root, "jazz", "e.cover"): "echo",
+		}
+
+		for path, content := range contents {
+			Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+		}
+	})
+
+	expected := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+
+		return hex.EncodeToString(sum[:])
+	}
+
+	hashingOptions := func(digests *sync.Map) nav.TraverseOptionFn {
+		return func(o *nav.TraverseOptions) {
+			o.Store.Subscription = nav.SubscribeAny
+			o.Store.Hashing = hashing.Options{
+				Algorithms: []hashing.AlgorithmEnum{hashing.AlgorithmSHA256En},
+				CachePath:  cache,
+			}
+			o.Callback = &nav.LabelledTraverseCallback{
+				Label: "hashing callback",
+				Fn: func(item *nav.TraverseItem) error {
+					digest, err := item.Extension.Hash(hashing.AlgorithmSHA256En)
+
+					if item.IsDirectory() {
+						Expect(QueryHashNotAvailableError(err)).To(BeTrue())
+
+						return nil
+					}
+
+					if err != nil {
+						return err
+					}
+
+					digests.Store(item.Path, digest)
+
+					return nil
+				},
+			}
+		}
+	}
+
+	cached := func() map[string]any {
+		data, err := os.ReadFile(cache)
+		Expect(err).To(Succeed())
+
+		var persisted struct {
+			Entries map[string]any `json:"entries"`
+		}
+
+		Expect(json.Unmarshal(data, &persisted)).To(Succeed())
+
+		return persisted.Entries
+	}
+
+	When("hashing enabled", func() {
+		It("🧪 should: provide digest of each file and persist cache", func() {
+			digests := &sync.Map{}
+
+			_, err := nav.New().Primary(&nav.Prime{
+				Path:      root,
+				OptionsFn: hashingOptions(digests),
+			}).Run()
+			Expect(err).To(Succeed())
+
+			for path, content := range contents {
+				digest, found := digests.Load(path)
+				Expect(found).To(BeTrue(), path)
+				Expect(digest).To(Equal(expected(content)), path)
+			}
+
+			Expect(cached()).To(HaveLen(len(contents)))
+		})
+	})
+
+	When("hashing not enabled", func() {
+		It("🧪 should: return hash not available error", func() {
+			_, err := nav.New().Primary(&nav.Prime{
+				Path: root,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.Subscription = nav.SubscribeFiles
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "no hashing callback",
+						Fn: func(item *nav.TraverseItem) error {
+							_, err := item.Extension.Hash(hashing.AlgorithmSHA256En)
+							Expect(QueryHashNotAvailableError(err)).To(BeTrue())
+
+							return nil
+						},
+					}
+				},
+			}).Run()
+			Expect(err).To(Succeed())
+		})
+	})
+
+	When("accelerated", func() {
+		It("🧪 should: hash on worker pool and persist cache once drained",
+			SpecTimeout(time.Second*5),
+			func(ctxSpec SpecContext) {
+				defer leaktest.Check(GinkgoT())()
+
+				ctx, cancel := context.WithCancel(ctxSpec)
+				defer cancel()
+
+				digests := &sync.Map{}
+				wgan := boost.NewAnnotatedWaitGroup("🍂 traversal")
+				wgan.Add(1, navigatorRoutineName)
+
+				_, err := nav.New().Primary(&nav.Prime{
+					Path:      root,
+					OptionsFn: hashingOptions(digests),
+				}).WithPool(
+					&nav.AsyncInfo{
+						NavigatorRoutineName: navigatorRoutineName,
+						WaitAQ:               wgan,
+						JobsChanOut:          make(boost.JobStream[nav.TraverseItemInput], DefaultJobsChSize),
+					},
+				).NoW(3).Run(ctx, cancel)
+
+				wgan.Wait("👾 test-main")
+				Expect(err).To(Succeed())
+
+				for path, content := range contents {
+					digest, found := digests.Load(path)
+					Expect(found).To(BeTrue(), path)
+					Expect(digest).To(Equal(expected(content)), path)
+				}
+
+				Expect(cached()).To(HaveLen(len(contents)))
+			},
+		)
+	})
+
+	When("virtual file system", func() {
+		var (
+			vfs     storage.VirtualFS
+			virtual string
+		)
+
+		BeforeEach(func() {
+			vfs = storage.UseMemFS()
+			virtual = filepath.Join(string(filepath.Separator), "virtual")
+
+			for path, content := range contents {
+				relative, _ := filepath.Rel(root, path)
+				path = filepath.Join(virtual, relative)
+
+				Expect(vfs.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+				Expect(vfs.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+			}
+		})
+
+		virtualOptions := func(digests *sync.Map, hfs storage.VirtualFS) nav.TraverseOptionFn {
+			return func(o *nav.TraverseOptions) {
+				hashingOptions(digests)(o)
+				o.Store.Hashing.CachePath = filepath.Join(virtual, "digests.json")
+				o.Store.Hashing.VirtualFS = hfs
+				o.Hooks.QueryStatus = vfs.Lstat
+				o.Hooks.ReadDirectory = nav.VirtualReadEntriesHookFn(vfs)
+			}
+		}
+
+		It("🧪 should: hash files and persist cache in virtual file system", func() {
+			digests := &sync.Map{}
+
+			_, err := nav.New().Primary(&nav.Prime{
+				Path:      virtual,
+				OptionsFn: virtualOptions(digests, vfs),
+			}).Run()
+			Expect(err).To(Succeed())
+
+			for path, content := range contents {
+				relative, _ := filepath.Rel(root, path)
+				digest, found := digests.Load(filepath.Join(virtual, relative))
+				Expect(found).To(BeTrue(), path)
+				Expect(digest).To(Equal(expected(content)), path)
+			}
+
+			Expect(vfs.FileExists(filepath.Join(virtual, "digests.json"))).To(BeTrue())
+			Expect(cache).NotTo(BeAnExistingFile())
+		})
+
+		When("accelerated and cache can not be saved", func() {
+			It("🧪 should: report error through result once drained",
+				SpecTimeout(time.Second*5),
+				func(ctxSpec SpecContext) {
+					defer leaktest.Check(GinkgoT())()
+
+					ctx, cancel := context.WithCancel(ctxSpec)
+					defer cancel()
+
+					wgan := boost.NewAnnotatedWaitGroup("🍂 traversal")
+					wgan.Add(1, navigatorRoutineName)
+					faulty, err := storage.UseFaultyFS(vfs, storage.Fault{
+						Op:  "WriteFile",
+						Err: fs.ErrPermission,
+					})
+					Expect(err).To(Succeed())
+
+					result, _ := nav.New().Primary(&nav.Prime{
+						Path:      virtual,
+						OptionsFn: virtualOptions(&sync.Map{}, faulty),
+					}).WithPool(
+						&nav.AsyncInfo{
+							NavigatorRoutineName: navigatorRoutineName,
+							WaitAQ:               wgan,
+							JobsChanOut:          make(boost.JobStream[nav.TraverseItemInput], DefaultJobsChSize),
+						},
+					).NoW(3).Run(ctx, cancel)
+
+					wgan.Wait("👾 test-main")
+					Expect(result.Err()).To(MatchError(fs.ErrPermission))
+				},
+			)
+		})
+	})
+})
@@ -0,0 +1,299 @@
+package nav_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/lorax/boost"
+)
+
+var _ = Describe("Traverse Incrementally", func() {
+	var (
+		root, index string
+		failing     string
+	)
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+
+		failing = ""
+		root = GinkgoT().TempDir()
+		index = filepath.Join(GinkgoT().TempDir(), "index.json")
+
+		for _, path := range []string{
+			filepath.Join("rock", "a.flac"),
+			filepath.Join("rock", "b.flac"),
+			filepath.Join("jazz", "c.flac"),
+			filepath.Join("jazz", "bebop", "d.flac"),
+			filepath.Join("jazz", "bebop", "e.flac"),
+		} {
+			Expect(os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(root, path), []byte(path), 0o644)).To(Succeed())
+		}
+	})
+
+	// run traverses the root incrementally and returns the sub-paths of the
+	// items the callback was invoked for and of those reported as removed.
+	// The callback fails for the failing item, which ends the traversal.
+	run := func(skip bool) (visited, removed []string) {
+		visited = []string{}
+		removed = []string{}
+
+		_, err := nav.New().Primary(&nav.Prime{
+			Path: root,
+			OptionsFn: func(o *nav.TraverseOptions) {
+				o.Store.Subscription = nav.SubscribeAny
+				o.Store.Incremental = nav.IncrementalOptions{
+					IndexPath:            index,
+					SkipUnchangedFolders: skip,
+				}
+				o.Notify.OnRemoved = func(item *nav.RemovedItem) {
+					removed = append(removed, item.SubPath)
+				}
+				o.Callback = &nav.LabelledTraverseCallback{
+					Label: "incremental callback",
+					Fn: func(item *nav.TraverseItem) error {
+						relative, _ := filepath.Rel(root, item.Path)
+						visited = append(visited, relative)
+
+						if relative == failing {
+							return errors.New("callback failed")
+						}
+
+						return nil
+					},
+				}
+			},
+		}).Run()
+
+		if failing == "" {
+			Expect(err).To(Succeed())
+		} else {
+			Expect(err).To(MatchError("callback failed"))
+		}
+
+		return visited, removed
+	}
+
+	// runAsync is the accelerated equivalent of run, whose failing job does
+	// not end the traversal.
+	runAsync := func(ctxSpec SpecContext) (visited []string) {
+		var mutex sync.Mutex
+
+		ctx, cancel := context.WithCancel(ctxSpec)
+		defer cancel()
+
+		visited = []string{}
+		wgan := boost.NewAnnotatedWaitGroup("🍂 incremental traversal")
+		wgan.Add(1, navigatorRoutineName)
+		outputCh := nav.CreateTraverseOutputCh(DefaultOutputsChSize)
+
+		runner := nav.New().With(nav.RunnerWithPool, &nav.RunnerInfo{
+			PrimeInfo: &nav.Prime{
+				Path: root,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.Subscription = nav.SubscribeAny
+					o.Store.Incremental = nav.IncrementalOptions{
+						IndexPath: index,
+					}
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "incremental callback",
+						Fn: func(item *nav.TraverseItem) error {
+							relative, _ := filepath.Rel(root, item.Path)
+
+							mutex.Lock()
+							visited = append(visited, relative)
+							mutex.Unlock()
+
+							if relative == failing {
+								return errors.New("callback failed")
+							}
+
+							return nil
+						},
+					}
+				},
+			},
+			AccelerationInfo: &nav.Acceleration{
+				WgAn:            wgan,
+				RoutineName:     navigatorRoutineName,
+				NoW:             4,
+				JobsChOut:       make(nav.TraverseItemJobStream, DefaultJobsChSize),
+				JobResultsCh:    outputCh,
+				OutputChTimeout: outputChTimeout,
+			},
+		})
+
+		consumed := make(chan struct{})
+
+		go func() {
+			defer close(consumed)
+
+			for range outputCh {
+			}
+		}()
+
+		_, err := runner.Run(ctx, cancel)
+		Expect(err).To(Succeed())
+
+		wgan.Wait("👾 test-main")
+		<-consumed
+
+		return visited
+	}
+
+	touch := func(path, content string) {
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+
+		later := time.Now().Add(time.Hour)
+		Expect(os.Chtimes(path, later, later)).To(Succeed())
+	}
+
+	When("first run", func() {
+		It("🧪 should: visit all items and persist index", func() {
+			visited, removed := run(false)
+			Expect(visited).To(HaveLen(9))
+			Expect(removed).To(BeEmpty())
+			Expect(index).To(BeAnExistingFile())
+		})
+	})
+
+	When("nothing changed", func() {
+		It("🧪 should: not visit any items", func() {
+			_, _ = run(false)
+
+			visited, removed := run(false)
+			Expect(visited).To(BeEmpty())
+			Expect(removed).To(BeEmpty())
+		})
+	})
+
+	When("items added, modified and removed", func() {
+		It("🧪 should: only visit new and modified items and report removals", func() {
+			_, _ = run(false)
+
+			touch(filepath.Join(root, "rock", "a.flac"), "modified")
+			Expect(os.WriteFile(filepath.Join(root, "rock", "f.flac"), []byte("new"), 0o644)).To(Succeed())
+			Expect(os.Remove(filepath.Join(root, "rock", "b.flac"))).To(Succeed())
+			Expect(os.RemoveAll(filepath.Join(root, "jazz", "bebop"))).To(Succeed())
+
+			visited, removed := run(false)
+
+			// the folders are visited because the addition and removal of their
+			// children changed their modification times
+			//
+			Expect(visited).To(ConsistOf(
+				"jazz",
+				"rock",
+				filepath.Join("rock", "a.flac"),
+				filepath.Join("rock", "f.flac"),
+			))
+			Expect(removed).To(Equal([]string{
+				filepath.Join("jazz", "bebop"),
+				filepath.Join("jazz", "bebop", "d.flac"),
+				filepath.Join("jazz", "bebop", "e.flac"),
+				filepath.Join("rock", "b.flac"),
+			}))
+
+			visited, removed = run(false)
+			Expect(visited).To(BeEmpty())
+			Expect(removed).To(BeEmpty())
+		})
+	})
+
+	When("index was created for a different root", func() {
+		It("🧪 should: visit all items", func() {
+			_, _ = run(false)
+
+			other := root
+			root = filepath.Join(root, "jazz")
+			visited, _ := run(false)
+			Expect(visited).To(HaveLen(5))
+
+			root = other
+		})
+	})
+
+	When("skipping unchanged folders", func() {
+		It("🧪 should: not descend into folder whose modification time is unchanged", func() {
+			_, _ = run(true)
+
+			// modifying the content of a file does not change the modification
+			// time of its folder
+			//
+			touch(filepath.Join(root, "jazz", "bebop", "d.flac"), "modified")
+			Expect(os.WriteFile(filepath.Join(root, "rock", "f.flac"), []byte("new"), 0o644)).To(Succeed())
+
+			visited, _ := run(true)
+			Expect(visited).To(ConsistOf(
+				"rock",
+				filepath.Join("rock", "f.flac"),
+			))
+
+			visited, _ = run(false)
+			Expect(visited).To(ConsistOf(
+				filepath.Join("jazz", "bebop", "d.flac"),
+			))
+		})
+	})
+
+	When("callback fails", func() {
+		It("🧪 should: not persist index, so that items are visited again", func() {
+			_, _ = run(false)
+
+			touch(filepath.Join(root, "jazz", "c.flac"), "modified")
+			touch(filepath.Join(root, "rock", "a.flac"), "modified")
+			failing = filepath.Join("jazz", "c.flac")
+
+			visited, _ := run(false)
+			Expect(visited).To(ConsistOf(
+				filepath.Join("jazz", "c.flac"),
+			))
+
+			failing = ""
+			visited, _ = run(false)
+			Expect(visited).To(ConsistOf(
+				filepath.Join("jazz", "c.flac"),
+				filepath.Join("rock", "a.flac"),
+			))
+
+			visited, _ = run(false)
+			Expect(visited).To(BeEmpty())
+		})
+	})
+
+	When("callback fails when accelerated", func() {
+		It("🧪 should: only record items whose callback succeeded", func(ctx SpecContext) {
+			_ = runAsync(ctx)
+
+			touch(filepath.Join(root, "jazz", "c.flac"), "modified")
+			touch(filepath.Join(root, "rock", "a.flac"), "modified")
+			failing = filepath.Join("jazz", "c.flac")
+
+			visited := runAsync(ctx)
+			Expect(visited).To(ConsistOf(
+				filepath.Join("jazz", "c.flac"),
+				filepath.Join("rock", "a.flac"),
+			))
+
+			failing = ""
+			visited = runAsync(ctx)
+			Expect(visited).To(ConsistOf(
+				filepath.Join("jazz", "c.flac"),
+			))
+		}, SpecTimeout(time.Second*5))
+	})
+})
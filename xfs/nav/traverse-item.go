@@ -2,7 +2,10 @@ package nav
 
 import (
 	"io/fs"
+	"path/filepath"
 
+	"github.com/snivilised/extendio/i18n"
+	"github.com/snivilised/extendio/xfs/hashing"
 	"github.com/snivilised/extendio/xfs/utils"
 )
 
@@ -16,6 +19,42 @@ type ExtendedItem struct {
 	SubPath   string            // represents the path between the root and the current item
 	NodeScope FilterScopeBiEnum // type of folder corresponding to the Filter Scope
 	Custom    any               // to be set and used by the client
+
+	Event       WatchEventEnum // the change that caused the item to be delivered in watch mode
+	RenamedFrom string         // the previous path of an item delivered for a rename event
+	digests     *itemDigests
+}
+
+// itemDigests binds a file item to the hashing service, so that its
+// digests can be computed on demand.
+type itemDigests struct {
+	hasher *hashing.Service
+	path   string
+	entry  fs.DirEntry
+	info   fs.FileInfo
+}
+
+// Hash returns the hex encoded digest of the content of the file, using the
+// algorithm, which must be one of those selected in Store.Hashing. The digest
+// is only computed when first requested, so the cost of hashing is only
+// incurred for the files the client is interested in. When the navigator is
+// accelerated, the callback runs on the worker pool, so the hashing does too.
+// A HashNotAvailableError is returned for a folder, or when the algorithm
+// was not selected.
+func (e *ExtendedItem) Hash(algorithm hashing.AlgorithmEnum) (string, error) {
+	if e.digests == nil {
+		return "", i18n.NewHashNotAvailableError(
+			algorithm.String(), filepath.Join(e.Parent, e.Name),
+		)
+	}
+
+	info := e.digests.info
+
+	if utils.IsNil(info) && !utils.IsNil(e.digests.entry) {
+		info, _ = e.digests.entry.Info()
+	}
+
+	return e.digests.hasher.Digest(e.digests.path, info, algorithm)
 }
 
 // TraverseItem info provided for each file system entity encountered
@@ -34,6 +73,7 @@ type TraverseItem struct {
 	Parent      *TraverseItem
 	admit       bool
 	dir         bool
+	forward     TraverseCallback // invokes the client callback via a transport
 }
 
 func isDir(item *TraverseItem) bool {
@@ -47,6 +87,16 @@ func isDir(item *TraverseItem) bool {
 	return false
 }
 
+// NewTraverseItem creates a traverse item for the file system entry at the path
+// specified. Either the entry or the info should be set, so that it is known
+// whether the item is a directory. This allows components that visit the
+// file system without a navigator, to create items that can be filtered.
+func NewTraverseItem(
+	path string, entry fs.DirEntry, info fs.FileInfo, parent *TraverseItem,
+) *TraverseItem {
+	return newTraverseItem(path, entry, info, parent, nil)
+}
+
 func newTraverseItem(
 	path string, entry fs.DirEntry, info fs.FileInfo, parent *TraverseItem, err error,
 ) *TraverseItem {
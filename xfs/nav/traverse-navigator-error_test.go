@@ -4,13 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"syscall"
 
 	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
 	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
 	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/internal/lo"
 
 	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
 	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
 )
 
 var _ = Describe("TraverseNavigator errors", Ordered, func() {
@@ -127,6 +130,77 @@ var _ = Describe("TraverseNavigator errors", Ordered, func() {
 		})
 	})
 
+	Context("faulty storage", func() {
+		var vfs storage.FaultyVirtualFS
+
+		BeforeEach(func() {
+			var err error
+
+			vfs, err = storage.UseFaultyFS(storage.UseNativeFS())
+			Expect(err).To(Succeed())
+		})
+
+		run := func(recording *[]*nav.TraverseItem) {
+			optionFn := func(o *nav.TraverseOptions) {
+				o.Notify.OnBegin = begin("🧲")
+				o.Store.Subscription = nav.SubscribeFolders
+				o.Hooks.QueryStatus = nav.VirtualQueryStatusHookFn(vfs)
+				o.Hooks.ReadDirectory = nav.VirtualReadEntriesHookFn(vfs)
+				o.Callback = &nav.LabelledTraverseCallback{
+					Label: "test faulty storage callback",
+					Fn: func(item *nav.TraverseItem) error {
+						*recording = append(*recording, item)
+
+						return nil
+					},
+				}
+			}
+
+			_, _ = nav.New().Primary(&nav.Prime{
+				Path:      helpers.Path(root, "RETRO-WAVE"),
+				OptionsFn: optionFn,
+			}).Run()
+		}
+
+		When("root is not found", func() {
+			It("🧪 should: invoke callback with path not found error", func() {
+				Expect(vfs.Inject(storage.Fault{Op: "Lstat", Glob: "RETRO-WAVE", Err: fs.ErrNotExist})).To(Succeed())
+				recording := []*nav.TraverseItem{}
+				run(&recording)
+
+				Expect(recording).To(HaveLen(1))
+				Expect(QueryPathNotFoundError(recording[0].Error)).To(BeTrue())
+			})
+		})
+
+		When("root is not accessible", func() {
+			It("🧪 should: invoke callback with third party error", func() {
+				Expect(vfs.Inject(storage.Fault{Op: "Lstat", Glob: "RETRO-WAVE", Err: syscall.EACCES})).To(Succeed())
+				recording := []*nav.TraverseItem{}
+				run(&recording)
+
+				Expect(recording).To(HaveLen(1))
+				Expect(recording[0].Error).To(BeAssignableToTypeOf(ThirdPartyError{}))
+				Expect(recording[0].Error.Error()).To(ContainSubstring(syscall.EACCES.Error()))
+			})
+		})
+
+		When("directory is not readable", func() {
+			It("🧪 should: invoke callback again with error", func() {
+				Expect(vfs.Inject(storage.Fault{Op: "ReadDir", Glob: "Chromatics", Err: syscall.EACCES, Times: 1})).To(Succeed())
+				recording := []*nav.TraverseItem{}
+				run(&recording)
+
+				failed := lo.Filter(recording, func(item *nav.TraverseItem, _ int) bool {
+					return item.Error != nil
+				})
+				Expect(failed).To(HaveLen(1))
+				Expect(failed[0].Extension.Name).To(Equal("Chromatics"))
+				Expect(vfs.Injections()).To(HaveLen(1))
+			})
+		})
+	})
+
 	DescribeTable("given: sort generates an error",
 		func(entry *errorTE) {
 			defer func() {
@@ -0,0 +1,199 @@
+package nav_test
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+)
+
+type orderTE struct {
+	naviTE
+	golden string
+	order  nav.DirectoryContentsOrderEnum
+	depth  uint
+}
+
+var _ = Describe("TraverseNavigatorOrder", Ordered, func() {
+	var (
+		root     string
+		backends map[string]storage.VirtualFS
+	)
+
+	BeforeAll(func() {
+		root = musico()
+		backends = map[string]storage.VirtualFS{
+			"native": storage.UseNativeFS(),
+			"mem":    storage.UseMemFS(),
+		}
+
+		Expect(helpers.EnsureIn(root, backends["mem"])).To(Succeed())
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	DescribeTable("golden",
+		func(entry *orderTE) {
+			for _, backend := range []string{"native", "mem"} {
+				vfs := backends[backend]
+				path := helpers.Path(root, entry.relative)
+				recording := []string{}
+
+				optionFn := func(o *nav.TraverseOptions) {
+					o.Store.Subscription = entry.subscription
+					o.Store.Behaviours.Sort.DirectoryEntryOrder = entry.order
+					o.Store.Behaviours.Cascade.Depth = entry.depth
+					o.Hooks.QueryStatus = nav.VirtualQueryStatusHookFn(vfs)
+					o.Hooks.ReadDirectory = nav.VirtualReadEntriesHookFn(vfs)
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "test golden recorder callback",
+						Fn: func(item *nav.TraverseItem) error {
+							relative := strings.TrimPrefix(item.Path, root)
+							if relative == "" {
+								relative = "."
+							}
+							recording = append(recording, filepath.ToSlash(relative))
+
+							return nil
+						},
+					}
+				}
+
+				_, err := nav.New().Primary(&nav.Prime{
+					Path:      path,
+					OptionsFn: optionFn,
+				}).Run()
+
+				Expect(err).To(BeNil())
+
+				expected, err := helpers.Golden(entry.golden, recording)
+				Expect(err).To(BeNil())
+				Expect(recording).To(Equal(expected),
+					fmt.Sprintf("❌ backend: '%v' deviates from golden: '%v'", backend, entry.golden),
+				)
+			}
+		},
+		func(entry *orderTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.message, entry.should)
+		},
+
+		// === universal =====================================================
+
+		Entry(nil, &orderTE{
+			naviTE: naviTE{
+				message:      "universal: folders first",
+				should:       "visit folders before files, consistently across backends",
+				subscription: nav.SubscribeAny,
+			},
+			golden: "universal-folders-first",
+			order:  nav.DirectoryContentsOrderFoldersFirstEn,
+			depth:  2,
+		}),
+
+		Entry(nil, &orderTE{
+			naviTE: naviTE{
+				message:      "universal: files first",
+				should:       "visit files before folders, consistently across backends",
+				subscription: nav.SubscribeAny,
+			},
+			golden: "universal-files-first",
+			order:  nav.DirectoryContentsOrderFilesFirstEn,
+			depth:  2,
+		}),
+
+		Entry(nil, &orderTE{
+			naviTE: naviTE{
+				message:      "universal: interleaved",
+				should:       "merge files and folders by sort order, consistently across backends",
+				subscription: nav.SubscribeAny,
+			},
+			golden: "universal-interleaved",
+			order:  nav.DirectoryContentsOrderInterleavedEn,
+			depth:  2,
+		}),
+
+		// === files =========================================================
+
+		Entry(nil, &orderTE{
+			naviTE: naviTE{
+				message:      "files: folders first",
+				should:       "visit files of sub-folders before files, consistently across backends",
+				relative:     "bass",
+				subscription: nav.SubscribeFiles,
+			},
+			golden: "files-folders-first",
+			order:  nav.DirectoryContentsOrderFoldersFirstEn,
+		}),
+
+		Entry(nil, &orderTE{
+			naviTE: naviTE{
+				message:      "files: files first",
+				should:       "visit files before files of sub-folders, consistently across backends",
+				relative:     "bass",
+				subscription: nav.SubscribeFiles,
+			},
+			golden: "files-files-first",
+			order:  nav.DirectoryContentsOrderFilesFirstEn,
+		}),
+
+		Entry(nil, &orderTE{
+			naviTE: naviTE{
+				message:      "files: interleaved",
+				should:       "merge files and folders by sort order, consistently across backends",
+				relative:     "bass",
+				subscription: nav.SubscribeFiles,
+			},
+			golden: "files-interleaved",
+			order:  nav.DirectoryContentsOrderInterleavedEn,
+		}),
+	)
+
+	DescribeTable("all contents",
+		func(_ string, order nav.DirectoryContentsOrderEnum) {
+			entries, err := storage.UseNativeFS().ReadDir(helpers.Path(root, "RETRO-WAVE/Chromatics/Night Drive"))
+			Expect(err).To(Succeed())
+
+			o := nav.GetDefaultOptions()
+			o.Store.Behaviours.Sort.DirectoryEntryOrder = order
+			o.Hooks.Sort = nav.CaseInSensitiveSortHookFn
+
+			// the spare capacity emulates recycled contents
+			//
+			folders := make([]fs.DirEntry, 0, len(entries)*2)
+			files := make([]fs.DirEntry, 0, len(entries)*2)
+			contents := &nav.DirectoryContents{
+				Options: o,
+				Folders: folders,
+				Files:   append(files, entries...),
+			}
+
+			all := contents.All()
+			Expect(all).To(HaveLen(len(entries)))
+
+			last := entries[len(entries)-1]
+			contents.Folders = append(contents.Folders[:0], last)
+			contents.Files = append(contents.Files[:0], last)
+			Expect(all).To(ConsistOf(entries))
+		},
+		func(given string, _ nav.DirectoryContentsOrderEnum) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", given, "not share backing array with contents")
+		},
+		Entry(nil, "folders first", nav.DirectoryContentsOrderFoldersFirstEn),
+		Entry(nil, "files first", nav.DirectoryContentsOrderFilesFirstEn),
+		Entry(nil, "interleaved", nav.DirectoryContentsOrderInterleavedEn),
+	)
+})
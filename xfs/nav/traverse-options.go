@@ -2,9 +2,11 @@ package nav
 
 import (
 	"log/slog"
+	"reflect"
 
 	"github.com/mohae/deepcopy"
 	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/hashing"
 	"github.com/snivilised/extendio/xfs/utils"
 	"go.uber.org/zap/exp/zapslog"
 	"go.uber.org/zap/zapcore"
@@ -85,6 +87,11 @@ type Notifications struct {
 	// OnStop handler invoked when finish listening condition met if enabled
 	//
 	OnStop ListenHandler
+
+	// OnRemoved handler invoked for each item recorded in the index of the
+	// previous run that no longer exists, when traversing incrementally
+	//
+	OnRemoved RemovedHandler
 }
 
 type FilterDefinitions struct {
@@ -145,6 +152,98 @@ type LogRotationOptions struct {
 	MaxAgeInDays int
 }
 
+// IncrementalOptions
+type IncrementalOptions struct {
+	// IndexPath is the path of the file in which the index of visited items
+	// is persisted at the end of a run. The index records the size,
+	// modification time and inode of each item, keyed by its path relative
+	// to the root. Incremental traversal is only active when the path is set.
+	// On the first run (or if the index was created for a different root),
+	// every item is considered new.
+	//
+	IndexPath string
+
+	// SkipUnchangedFolders causes a folder whose modification time is
+	// unchanged to be skipped wholesale, without descending into it. Since
+	// a folder's modification time only reflects the addition, removal or
+	// renaming of its direct children, modifications made deeper in the tree
+	// go unnoticed. Therefore, this is only suitable for a tree whose items,
+	// once added, are not subsequently modified.
+	//
+	SkipUnchangedFolders bool
+}
+
+// ReadAheadOptions
+type ReadAheadOptions struct {
+	// NoReaders is the maximum number of directories that are read
+	// concurrently. When a directory is read, the contents of its
+	// sub-directories are read ahead of the traversal reaching them, which
+	// helps when reading a directory is slow, eg on a network file system.
+	// The file info of the entries read ahead is also retrieved up front.
+	// Read ahead is only active when NoReaders is greater than 1. The
+	// ReadDirectory hook must be safe to invoke concurrently.
+	//
+	NoReaders uint
+
+	// Unordered relaxes the traversal order, so that, of the sub-directories
+	// of a directory, the one whose contents are read first is navigated
+	// first, rather than in sort order. The files of a directory remain in
+	// sort order.
+	//
+	Unordered bool
+}
+
+// StreamingOptions
+type StreamingOptions struct {
+	// BatchSize is the maximum number of entries of a directory held in
+	// memory at any one time. When set, a directory is read in batches of
+	// this size, which are navigated as they are read, rather than all of
+	// its entries being read up front; this bounds the memory used by
+	// directories with a huge number of entries. Streaming is disabled when
+	// BatchSize is 0.
+	//
+	// The following features degrade when streaming:
+	//
+	// - sorting: each batch is sorted on its own, so the entries of a
+	// directory are only in sort order when they fit in a single batch,
+	// otherwise they follow the order of the file system. The directory
+	// entry order (folders or files first) also only applies within a batch.
+	//
+	// - IsLeaf: since the contents of a directory are not known when it is
+	// visited, a directory is never considered to be a leaf, which also
+	// affects the leaf scope of filters.
+	//
+	// - Sampling, SubscribeFoldersWithFiles, incremental traversal and read
+	// ahead all require the complete contents of a directory, so when any of
+	// these are active, directories are read up front as normal.
+	//
+	// - a custom ReadDirectory hook (eg VirtualReadEntriesHookFn) requires a
+	// matching OpenDirectory hook (eg VirtualOpenEntriesHookFn), because the
+	// default opens directories of the native file system; when it is
+	// missing, directories are read up front with ReadDirectory as normal.
+	//
+	BatchSize uint
+}
+
+// PoolingOptions
+type PoolingOptions struct {
+	// Recycle enables the recycling of the objects allocated for each item
+	// navigated, ie the TraverseItem and the contents of its directory,
+	// which relieves the garbage collector on massive traversals.
+	//
+	// When recycling, the item passed to the callback (and to the hooks and
+	// notifications) is only valid for the duration of that invocation and
+	// is subsequently re-used for another item. Therefore, the client must
+	// not retain the item, its Children or its Parent beyond the invocation;
+	// any data required afterwards must be copied out of the item.
+	//
+	// Recycling does not apply to accelerated traversals (where items are
+	// handed to the worker pool), sampling or read ahead, all of which
+	// retain items or contents beyond their navigation.
+	//
+	Recycle bool
+}
+
 type MonitorOptions struct {
 	Log *slog.Logger
 }
@@ -231,6 +330,30 @@ type OptionsStore struct {
 	// Sampling options
 	//
 	Sampling SamplingOptions
+
+	// Hashing selects the algorithms with which file content can be hashed
+	// (see ExtendedItem.Hash) and the location of the persistent cache of
+	// digests. Hashing is disabled when no algorithms are selected.
+	//
+	Hashing hashing.Options
+
+	// Incremental enables incremental traversal, where the callback is only
+	// invoked for items that are new or have been modified since the
+	// previous run.
+	//
+	Incremental IncrementalOptions
+
+	// ReadAhead enables the concurrent reading of directories
+	//
+	ReadAhead ReadAheadOptions
+
+	// Streaming bounds the memory used by reading directories
+	//
+	Streaming StreamingOptions
+
+	// Pooling reduces the allocations made for each item navigated
+	//
+	Pooling PoolingOptions
 }
 
 // TraverseOptions customise the way a directory tree is traversed
@@ -263,6 +386,12 @@ type TraverseOptions struct {
 	// Monitor contains externally provided logger
 	//
 	Monitor MonitorOptions `json:"-"`
+
+	// Watch enables watch mode, where the session continues to deliver
+	// changes made to the tree, once the initial traversal is complete. Only
+	// applies to a primary session.
+	//
+	Watch WatchOptions `json:"-"`
 }
 
 // TraverseOptionFn functional traverse options
@@ -292,6 +421,44 @@ func (o *TraverseOptions) isFilteringActive() bool {
 	return false
 }
 
+// isStreaming denotes whether directories are read in batches, which is
+// not possible for the features that require the complete contents of a
+// directory.
+func (o *TraverseOptions) isStreaming() bool {
+	return o.Store.Streaming.BatchSize > 0 &&
+		o.Store.Sampling.SampleType == SampleTypeUnsetEn &&
+		o.Store.Subscription != SubscribeFoldersWithFiles &&
+		o.Store.Incremental.IndexPath == "" &&
+		o.Store.ReadAhead.NoReaders <= 1 &&
+		o.isStreamable()
+}
+
+// isStreamable denotes whether the Open Directory hook opens the same
+// directories as the Read Directory hook reads. The default Open Directory
+// hook opens directories of the native file system, so it can't be used
+// when the Read Directory hook has been customised (eg to read from a
+// virtual file system), unless the Open Directory hook has been too.
+func (o *TraverseOptions) isStreamable() bool {
+	if o.Hooks.OpenDirectory == nil {
+		return false
+	}
+
+	isDefault := func(hook, fn any) bool {
+		return reflect.ValueOf(hook).Pointer() == reflect.ValueOf(fn).Pointer()
+	}
+
+	return !isDefault(o.Hooks.OpenDirectory, OpenEntriesHookFn) ||
+		isDefault(o.Hooks.ReadDirectory, ReadEntriesHookFn)
+}
+
+// isRecycling denotes whether the objects allocated for each item are
+// recycled, which is not possible for the features that retain them.
+func (o *TraverseOptions) isRecycling() bool {
+	return o.Store.Pooling.Recycle &&
+		o.Store.Sampling.SampleType == SampleTypeUnsetEn &&
+		o.Store.ReadAhead.NoReaders <= 1
+}
+
 func (o *TraverseOptions) afterUserOptions() {
 	if o.Hooks.Sort == nil {
 		o.Hooks.Sort = lo.Ternary(o.Store.Behaviours.Sort.IsCaseSensitive,
@@ -344,10 +511,12 @@ func GetDefaultOptions() *TraverseOptions {
 			OnEnd:     func(_ *TraverseResult) {},
 			OnDescend: func(_ *TraverseItem) {},
 			OnAscend:  func(_ *TraverseItem) {},
+			OnRemoved: func(_ *RemovedItem) {},
 		},
 		Hooks: TraverseHooks{
 			QueryStatus:   LstatHookFn,
 			ReadDirectory: ReadEntriesHookFn,
+			OpenDirectory: OpenEntriesHookFn,
 			FolderSubPath: RootParentSubPathHookFn,
 			FileSubPath:   RootParentSubPathHookFn,
 			InitFilters:   InitFiltersHookFn,
@@ -0,0 +1,141 @@
+package nav_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/xfs/nav"
+)
+
+type poolingTE struct {
+	message      string
+	relative     string
+	subscription nav.TraverseSubscription
+	streaming    bool
+}
+
+// visitedItem is the data copied out of an item, which can't be retained
+// when recycling.
+type visitedItem struct {
+	path     string
+	isDir    bool
+	depth    int
+	isLeaf   bool
+	subPath  string
+	children int
+}
+
+// poolingOptions defines a traversal that counts the items visited
+func poolingOptions(subscription nav.TraverseSubscription, recycle bool,
+	fn func(item *nav.TraverseItem),
+) nav.TraverseOptionFn {
+	return func(o *nav.TraverseOptions) {
+		o.Store.Subscription = subscription
+		o.Store.Pooling.Recycle = recycle
+		o.Callback = &nav.LabelledTraverseCallback{
+			Label: "pooling callback",
+			Fn: func(item *nav.TraverseItem) error {
+				fn(item)
+
+				return nil
+			},
+		}
+	}
+}
+
+var _ = Describe("Traverse With Pooling", Ordered, func() {
+	var root string
+
+	BeforeAll(func() {
+		root = musico()
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	// run traverses the path and returns the items visited, along with the
+	// number of distinct item instances passed to the callback.
+	run := func(entry *poolingTE, recycle bool) (visited []visitedItem, instances int) {
+		visited = []visitedItem{}
+		distinct := map[*nav.TraverseItem]bool{}
+
+		_, err := nav.New().Primary(&nav.Prime{
+			Path: helpers.Path(root, entry.relative),
+			OptionsFn: func(o *nav.TraverseOptions) {
+				poolingOptions(entry.subscription, recycle, func(item *nav.TraverseItem) {
+					distinct[item] = true
+					visited = append(visited, visitedItem{
+						path:     item.Path,
+						isDir:    item.IsDirectory(),
+						depth:    item.Extension.Depth,
+						isLeaf:   item.Extension.IsLeaf,
+						subPath:  item.Extension.SubPath,
+						children: len(item.Children),
+					})
+				})(o)
+
+				if entry.streaming {
+					o.Store.Streaming.BatchSize = 2
+				}
+			},
+		}).Run()
+		Expect(err).To(Succeed())
+
+		return visited, len(distinct)
+	}
+
+	DescribeTable("recycling",
+		func(entry *poolingTE) {
+			expected, _ := run(entry, false)
+			visited, instances := run(entry, true)
+
+			Expect(visited).To(Equal(expected))
+			Expect(instances).To(BeNumerically("<", len(visited)),
+				"items should be re-used",
+			)
+		},
+		func(entry *poolingTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.message, "visit same items")
+		},
+
+		Entry(nil, &poolingTE{
+			message:      "universal",
+			relative:     "RETRO-WAVE",
+			subscription: nav.SubscribeAny,
+		}),
+
+		Entry(nil, &poolingTE{
+			message:      "folders",
+			relative:     "",
+			subscription: nav.SubscribeFolders,
+		}),
+
+		Entry(nil, &poolingTE{
+			message:      "folders with files",
+			relative:     "",
+			subscription: nav.SubscribeFoldersWithFiles,
+		}),
+
+		Entry(nil, &poolingTE{
+			message:      "files",
+			relative:     "",
+			subscription: nav.SubscribeFiles,
+		}),
+
+		Entry(nil, &poolingTE{
+			message:      "universal: streaming",
+			relative:     "RETRO-WAVE",
+			subscription: nav.SubscribeAny,
+			streaming:    true,
+		}),
+	)
+})
@@ -0,0 +1,141 @@
+package nav_test
+
+import (
+	"fmt"
+	"io/fs"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/xfs/nav"
+)
+
+type readAheadTE struct {
+	message      string
+	relative     string
+	subscription nav.TraverseSubscription
+	depth        uint
+	unordered    bool
+}
+
+var _ = Describe("Traverse With Read Ahead", Ordered, func() {
+	var root string
+
+	BeforeAll(func() {
+		root = musico()
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	// run traverses the path and returns the paths of the items visited, in
+	// the order they were visited, along with the maximum number of
+	// directories that were being read at the same time.
+	run := func(entry *readAheadTE, readers uint) (visited []string, concurrency int32) {
+		var (
+			reading, peak atomic.Int32
+		)
+
+		visited = []string{}
+
+		_, err := nav.New().Primary(&nav.Prime{
+			Path: helpers.Path(root, entry.relative),
+			OptionsFn: func(o *nav.TraverseOptions) {
+				o.Store.Subscription = entry.subscription
+				o.Store.Behaviours.Cascade.Depth = entry.depth
+				o.Store.ReadAhead = nav.ReadAheadOptions{
+					NoReaders: readers,
+					Unordered: entry.unordered,
+				}
+				o.Hooks.ReadDirectory = func(dirname string) ([]fs.DirEntry, error) {
+					now := reading.Add(1)
+					defer reading.Add(-1)
+
+					for previous := peak.Load(); now > previous; previous = peak.Load() {
+						if peak.CompareAndSwap(previous, now) {
+							break
+						}
+					}
+
+					// simulate a slow file system
+					//
+					time.Sleep(time.Millisecond)
+
+					return nav.ReadEntriesHookFn(dirname)
+				}
+				o.Callback = &nav.LabelledTraverseCallback{
+					Label: "read ahead callback",
+					Fn: func(item *nav.TraverseItem) error {
+						visited = append(visited, item.Path)
+
+						return nil
+					},
+				}
+			},
+		}).Run()
+		Expect(err).To(Succeed())
+
+		return visited, peak.Load()
+	}
+
+	DescribeTable("read ahead",
+		func(entry *readAheadTE) {
+			expected, _ := run(entry, 0)
+			visited, concurrency := run(entry, 4)
+
+			if entry.unordered {
+				Expect(visited).To(ConsistOf(expected))
+			} else {
+				Expect(visited).To(Equal(expected))
+			}
+
+			Expect(concurrency).To(BeNumerically(">", 1))
+			Expect(concurrency).To(BeNumerically("<=", 4))
+		},
+		func(entry *readAheadTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.message, "visit same items")
+		},
+
+		Entry(nil, &readAheadTE{
+			message:      "universal: ordered",
+			relative:     "RETRO-WAVE",
+			subscription: nav.SubscribeAny,
+		}),
+
+		Entry(nil, &readAheadTE{
+			message:      "universal: unordered",
+			relative:     "RETRO-WAVE",
+			subscription: nav.SubscribeAny,
+			unordered:    true,
+		}),
+
+		Entry(nil, &readAheadTE{
+			message:      "folders: ordered",
+			relative:     "",
+			subscription: nav.SubscribeFolders,
+		}),
+
+		Entry(nil, &readAheadTE{
+			message:      "files: unordered",
+			relative:     "",
+			subscription: nav.SubscribeFiles,
+			unordered:    true,
+		}),
+
+		Entry(nil, &readAheadTE{
+			message:      "universal: ordered, limited depth",
+			relative:     "",
+			subscription: nav.SubscribeAny,
+			depth:        2,
+		}),
+	)
+})
@@ -0,0 +1,236 @@
+package nav_test
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/internal/helpers"
+	"github.com/snivilised/extendio/xfs/nav"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+type streamingTE struct {
+	message      string
+	relative     string
+	subscription nav.TraverseSubscription
+	depth        uint
+	batchSize    uint
+	ordered      bool
+}
+
+// meteredStream records the largest batch read from a directory
+type meteredStream struct {
+	nav.DirectoryStream
+	largest *int
+}
+
+func (s *meteredStream) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries, err := s.DirectoryStream.ReadDir(n)
+	*s.largest = max(*s.largest, len(entries))
+
+	return entries, err
+}
+
+var _ = Describe("Traverse With Streaming", Ordered, func() {
+	var root string
+
+	BeforeAll(func() {
+		root = musico()
+	})
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+	})
+
+	// run traverses the path and returns the items visited, in the order
+	// they were visited, along with the largest batch read and the number
+	// of directories read up front.
+	run := func(entry *streamingTE, batchSize uint,
+		fn ...nav.TraverseOptionFn,
+	) (visited []*nav.TraverseItem, largest, reads int) {
+		visited = []*nav.TraverseItem{}
+
+		_, err := nav.New().Primary(&nav.Prime{
+			Path: helpers.Path(root, entry.relative),
+			OptionsFn: func(o *nav.TraverseOptions) {
+				o.Store.Subscription = entry.subscription
+				o.Store.Behaviours.Cascade.Depth = entry.depth
+				o.Store.Streaming.BatchSize = batchSize
+				o.Hooks.ReadDirectory = func(dirname string) ([]fs.DirEntry, error) {
+					reads++
+
+					return nav.ReadEntriesHookFn(dirname)
+				}
+				o.Hooks.OpenDirectory = func(dirname string) (nav.DirectoryStream, error) {
+					stream, err := nav.OpenEntriesHookFn(dirname)
+					if err != nil {
+						return nil, err
+					}
+
+					return &meteredStream{DirectoryStream: stream, largest: &largest}, nil
+				}
+				o.Callback = &nav.LabelledTraverseCallback{
+					Label: "streaming callback",
+					Fn: func(item *nav.TraverseItem) error {
+						visited = append(visited, item)
+
+						return nil
+					},
+				}
+
+				for _, f := range fn {
+					f(o)
+				}
+			},
+		}).Run()
+		Expect(err).To(Succeed())
+
+		return visited, largest, reads
+	}
+
+	paths := func(items []*nav.TraverseItem) []string {
+		result := make([]string, 0, len(items))
+
+		for _, item := range items {
+			result = append(result, item.Path)
+		}
+
+		return result
+	}
+
+	DescribeTable("streaming",
+		func(entry *streamingTE) {
+			expected, _, _ := run(entry, 0)
+			visited, largest, reads := run(entry, entry.batchSize)
+
+			if entry.ordered {
+				Expect(paths(visited)).To(Equal(paths(expected)))
+			} else {
+				Expect(paths(visited)).To(ConsistOf(paths(expected)))
+			}
+
+			Expect(largest).To(BeNumerically(">", 0))
+			Expect(largest).To(BeNumerically("<=", entry.batchSize))
+			Expect(reads).To(Equal(0), "directories should not be read up front")
+		},
+		func(entry *streamingTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.message, "visit same items")
+		},
+
+		Entry(nil, &streamingTE{
+			message:      "universal: single batch",
+			relative:     "RETRO-WAVE",
+			subscription: nav.SubscribeAny,
+			batchSize:    1000,
+			ordered:      true,
+		}),
+
+		Entry(nil, &streamingTE{
+			message:      "universal: small batches",
+			relative:     "RETRO-WAVE",
+			subscription: nav.SubscribeAny,
+			batchSize:    2,
+		}),
+
+		Entry(nil, &streamingTE{
+			message:      "folders: small batches",
+			relative:     "",
+			subscription: nav.SubscribeFolders,
+			batchSize:    2,
+		}),
+
+		Entry(nil, &streamingTE{
+			message:      "files: small batches",
+			relative:     "",
+			subscription: nav.SubscribeFiles,
+			batchSize:    1,
+		}),
+
+		Entry(nil, &streamingTE{
+			message:      "universal: small batches, limited depth",
+			relative:     "",
+			subscription: nav.SubscribeAny,
+			depth:        2,
+			batchSize:    3,
+		}),
+	)
+
+	When("streaming", func() {
+		It("🧪 should: not consider any directory to be a leaf", func() {
+			visited, _, _ := run(&streamingTE{
+				relative:     "RETRO-WAVE",
+				subscription: nav.SubscribeFolders,
+			}, 2)
+
+			Expect(visited).NotTo(BeEmpty())
+
+			for _, item := range visited {
+				Expect(item.Extension.IsLeaf).To(BeFalse(), item.Path)
+			}
+		})
+	})
+
+	When("sampling", func() {
+		It("🧪 should: read directories up front", func() {
+			visited, largest, reads := run(&streamingTE{
+				relative:     "RETRO-WAVE",
+				subscription: nav.SubscribeAny,
+			}, 2, func(o *nav.TraverseOptions) {
+				o.Store.Sampling.SampleType = nav.SampleTypeSliceEn
+				o.Store.Sampling.NoOf.Files = 1
+				o.Store.Sampling.NoOf.Folders = 1
+			})
+
+			Expect(visited).NotTo(BeEmpty())
+			Expect(largest).To(Equal(0))
+			Expect(reads).To(BeNumerically(">", 0))
+		})
+	})
+
+	When("reading from virtual file system without open directory hook", func() {
+		It("🧪 should: read directories up front", func() {
+			vfs := storage.UseMemFS()
+			virtual := filepath.Join(string(filepath.Separator), "virtual")
+
+			for _, path := range []string{
+				filepath.Join(virtual, "a", "x.txt"),
+				filepath.Join(virtual, "a", "y.txt"),
+				filepath.Join(virtual, "b", "z.txt"),
+			} {
+				Expect(vfs.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+				Expect(vfs.WriteFile(path, []byte(path), 0o644)).To(Succeed())
+			}
+
+			visited := []string{}
+			_, err := nav.New().Primary(&nav.Prime{
+				Path: virtual,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.Subscription = nav.SubscribeAny
+					o.Store.Streaming.BatchSize = 2
+					o.Hooks.QueryStatus = vfs.Lstat
+					o.Hooks.ReadDirectory = nav.VirtualReadEntriesHookFn(vfs)
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "streaming callback",
+						Fn: func(item *nav.TraverseItem) error {
+							visited = append(visited, item.Path)
+
+							return item.Error
+						},
+					}
+				},
+			}).Run()
+
+			Expect(err).To(Succeed())
+			Expect(visited).To(HaveLen(6))
+		})
+	})
+})
@@ -0,0 +1,323 @@
+package nav_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/nav"
+)
+
+type watchTE struct {
+	message      string
+	poll         bool
+	subscription nav.TraverseSubscription
+	change       func(root string)
+	expected     []string
+	excluded     []string
+}
+
+var _ = Describe("Traverse Watch", func() {
+	var (
+		root string
+	)
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+
+		root = GinkgoT().TempDir()
+
+		for _, path := range []string{
+			filepath.Join("rock", "a.flac"),
+			filepath.Join("rock", "b.flac"),
+			filepath.Join("jazz", "c.flac"),
+		} {
+			Expect(os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(root, path), []byte(path), 0o644)).To(Succeed())
+		}
+	})
+
+	// watch starts a watch session in the background and returns a function
+	// that reports the events received so far (as "event:relative-path", with
+	// " <= from" appended for a rename), once the initial traversal, which
+	// invokes the callback for the given number of items, is complete.
+	watch := func(ctx context.Context, entry *watchTE, initial int,
+		fn func(item *nav.TraverseItem) error,
+	) (events func() []string, done chan error) {
+		var (
+			mutex    sync.Mutex
+			received []string
+			visited  int
+		)
+
+		started := make(chan struct{})
+		done = make(chan error, 1)
+
+		go func() {
+			defer GinkgoRecover()
+
+			_, err := nav.New().Primary(&nav.Prime{
+				Path: root,
+				OptionsFn: func(o *nav.TraverseOptions) {
+					o.Store.Subscription = entry.subscription
+					o.Watch = nav.WatchOptions{
+						Context:  ctx,
+						Poll:     entry.poll,
+						Interval: time.Millisecond * 20,
+					}
+					o.Callback = &nav.LabelledTraverseCallback{
+						Label: "watch callback",
+						Fn: func(item *nav.TraverseItem) error {
+							if item.Extension.Event == nav.WatchEventNoneEn {
+								if visited++; visited == initial {
+									close(started)
+								}
+
+								return nil
+							}
+
+							relative, _ := filepath.Rel(root, item.Path)
+							event := fmt.Sprintf("%v:%v", item.Extension.Event, relative)
+							if item.Extension.RenamedFrom != "" {
+								from, _ := filepath.Rel(root, item.Extension.RenamedFrom)
+								event += " <= " + from
+							}
+
+							mutex.Lock()
+							received = append(received, event)
+							mutex.Unlock()
+
+							if fn != nil {
+								return fn(item)
+							}
+
+							return nil
+						},
+					}
+				},
+			}).Run()
+			done <- err
+		}()
+
+		Eventually(started).WithTimeout(time.Second * 5).Should(BeClosed())
+
+		return func() []string {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			return append([]string{}, received...)
+		}, done
+	}
+
+	DescribeTable("changes",
+		func(entry *watchTE) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			initial := 6
+			if entry.subscription == nav.SubscribeFiles {
+				initial = 3
+			}
+
+			events, done := watch(ctx, entry, initial, nil)
+			entry.change(root)
+
+			Eventually(events).WithTimeout(time.Second * 5).Should(ContainElements(entry.expected))
+
+			for _, excluded := range entry.excluded {
+				Consistently(events).WithTimeout(time.Millisecond * 200).ShouldNot(ContainElement(excluded))
+			}
+
+			cancel()
+			Eventually(done).WithTimeout(time.Second * 5).Should(Receive(Succeed()))
+		},
+		func(entry *watchTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.message, entry.expected)
+		},
+
+		Entry(nil, &watchTE{
+			message:      "polling: file created, modified and deleted",
+			poll:         true,
+			subscription: nav.SubscribeAny,
+			change: func(root string) {
+				Expect(os.WriteFile(filepath.Join(root, "rock", "d.flac"), []byte("new"), 0o644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(root, "rock", "a.flac"), []byte("modified"), 0o644)).To(Succeed())
+				Expect(os.Remove(filepath.Join(root, "jazz", "c.flac"))).To(Succeed())
+			},
+			expected: []string{
+				"create:" + filepath.Join("rock", "d.flac"),
+				"modify:" + filepath.Join("rock", "a.flac"),
+				"delete:" + filepath.Join("jazz", "c.flac"),
+			},
+		}),
+
+		Entry(nil, &watchTE{
+			message:      "polling: folder renamed",
+			poll:         true,
+			subscription: nav.SubscribeAny,
+			change: func(root string) {
+				Expect(os.Rename(filepath.Join(root, "jazz"), filepath.Join(root, "blues"))).To(Succeed())
+			},
+			expected: []string{
+				"rename:blues <= jazz",
+			},
+			excluded: []string{
+				"rename:" + filepath.Join("blues", "c.flac") + " <= " + filepath.Join("jazz", "c.flac"),
+			},
+		}),
+
+		Entry(nil, &watchTE{
+			message:      "polling: files subscription",
+			poll:         true,
+			subscription: nav.SubscribeFiles,
+			change: func(root string) {
+				Expect(os.MkdirAll(filepath.Join(root, "pop"), 0o755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(root, "pop", "e.flac"), []byte("new"), 0o644)).To(Succeed())
+			},
+			expected: []string{
+				"create:" + filepath.Join("pop", "e.flac"),
+			},
+			excluded: []string{
+				"create:pop",
+			},
+		}),
+
+		Entry(nil, &watchTE{
+			message:      "polling: file whose name starts with dots created",
+			poll:         true,
+			subscription: nav.SubscribeAny,
+			change: func(root string) {
+				Expect(os.WriteFile(filepath.Join(root, "..d.flac"), []byte("new"), 0o644)).To(Succeed())
+			},
+			expected: []string{
+				"create:..d.flac",
+			},
+		}),
+
+		Entry(nil, &watchTE{
+			message:      "native: file created, modified and deleted",
+			subscription: nav.SubscribeAny,
+			change: func(root string) {
+				Expect(os.WriteFile(filepath.Join(root, "rock", "d.flac"), []byte("new"), 0o644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(root, "rock", "a.flac"), []byte("modified"), 0o644)).To(Succeed())
+				Expect(os.Remove(filepath.Join(root, "jazz", "c.flac"))).To(Succeed())
+			},
+			expected: []string{
+				"create:" + filepath.Join("rock", "d.flac"),
+				"modify:" + filepath.Join("rock", "a.flac"),
+				"delete:" + filepath.Join("jazz", "c.flac"),
+			},
+		}),
+
+		Entry(nil, &watchTE{
+			message:      "native: file renamed",
+			subscription: nav.SubscribeAny,
+			change: func(root string) {
+				Expect(os.Rename(
+					filepath.Join(root, "rock", "b.flac"), filepath.Join(root, "jazz", "b.flac"),
+				)).To(Succeed())
+			},
+			expected: []string{
+				"rename:" + filepath.Join("jazz", "b.flac") + " <= " + filepath.Join("rock", "b.flac"),
+			},
+		}),
+
+		Entry(nil, &watchTE{
+			message:      "native: file created in new folder",
+			subscription: nav.SubscribeAny,
+			change: func(root string) {
+				Expect(os.MkdirAll(filepath.Join(root, "pop"), 0o755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(root, "pop", "e.flac"), []byte("new"), 0o644)).To(Succeed())
+
+				// give the watcher time to adopt the new folder
+				//
+				time.Sleep(time.Millisecond * 200)
+				Expect(os.WriteFile(filepath.Join(root, "pop", "f.flac"), []byte("new"), 0o644)).To(Succeed())
+			},
+			expected: []string{
+				"create:pop",
+				"create:" + filepath.Join("pop", "e.flac"),
+				"create:" + filepath.Join("pop", "f.flac"),
+			},
+		}),
+	)
+
+	When("callback returns SkipAll", func() {
+		It("🧪 should: end the session", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			entry := &watchTE{
+				poll:         true,
+				subscription: nav.SubscribeAny,
+			}
+			events, done := watch(ctx, entry, 6, func(_ *nav.TraverseItem) error {
+				return fs.SkipAll
+			})
+			Expect(os.WriteFile(filepath.Join(root, "rock", "d.flac"), []byte("new"), 0o644)).To(Succeed())
+
+			Eventually(done).WithTimeout(time.Second * 5).Should(Receive(Succeed()))
+			Expect(events()).To(HaveLen(1))
+		})
+	})
+
+	When("native event queue overflows", func() {
+		It("🧪 should: report overflow", func() {
+			if runtime.GOOS != "linux" {
+				Skip("inotify is only available on linux")
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			blocked := make(chan struct{})
+			flooded := make(chan struct{})
+			once := sync.Once{}
+
+			entry := &watchTE{
+				subscription: nav.SubscribeAny,
+			}
+			events, done := watch(ctx, entry, 6, func(_ *nav.TraverseItem) error {
+				// hold up the delivery of events, so that the queue overflows
+				//
+				once.Do(func() {
+					close(blocked)
+					<-flooded
+				})
+
+				return nil
+			})
+			Expect(os.WriteFile(filepath.Join(root, "rock", "d.flac"), []byte("new"), 0o644)).To(Succeed())
+			Eventually(blocked).WithTimeout(time.Second * 5).Should(BeClosed())
+
+			// more than the default limit (fs.inotify.max_queued_events) of
+			// 16384 events
+			//
+			for i := range 17000 {
+				f, err := os.Create(filepath.Join(root, "rock", fmt.Sprintf("flood-%05d.flac", i)))
+				Expect(err).To(Succeed())
+				Expect(f.Close()).To(Succeed())
+			}
+
+			close(flooded)
+
+			Eventually(events).WithTimeout(time.Second * 5).Should(ContainElement("overflow:."))
+
+			cancel()
+			Eventually(done).WithTimeout(time.Second * 5).Should(Receive(Succeed()))
+		})
+	})
+})
@@ -0,0 +1,121 @@
+package nav
+
+import (
+	"context"
+	"time"
+
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+const (
+	// DefaultWatchPollInterval is the interval at which the polling watcher
+	// re-scans the tree, when no interval is specified.
+	DefaultWatchPollInterval = time.Second
+)
+
+// WatchEventEnum denotes the kind of change that caused an item to be
+// delivered in watch mode.
+type WatchEventEnum uint
+
+const (
+	// WatchEventNoneEn denotes an item delivered by the traversal, rather
+	// than by a watch event
+	//
+	WatchEventNoneEn WatchEventEnum = iota
+
+	// WatchEventCreateEn the item was created, or moved into the tree
+	//
+	WatchEventCreateEn
+
+	// WatchEventModifyEn the content or attributes of the item changed
+	//
+	WatchEventModifyEn
+
+	// WatchEventDeleteEn the item was deleted, or moved out of the tree. As
+	// the item no longer exists, it has neither an Entry nor an Info.
+	//
+	WatchEventDeleteEn
+
+	// WatchEventRenameEn the item was renamed within the tree; the item
+	// represents the new path and ExtendedItem.RenamedFrom the old one.
+	//
+	WatchEventRenameEn
+
+	// WatchEventOverflowEn changes were lost, because they occurred faster
+	// than they could be delivered (eg the inotify queue overflowed). The
+	// item represents the root and is delivered regardless of the
+	// subscription and filters; the client should re-scan the tree (eg by
+	// running the traversal again, incrementally).
+	//
+	WatchEventOverflowEn
+)
+
+func (e WatchEventEnum) String() string {
+	switch e {
+	case WatchEventCreateEn:
+		return "create"
+	case WatchEventModifyEn:
+		return "modify"
+	case WatchEventDeleteEn:
+		return "delete"
+	case WatchEventRenameEn:
+		return "rename"
+	case WatchEventOverflowEn:
+		return "overflow"
+	case WatchEventNoneEn:
+	}
+
+	return "none"
+}
+
+// WatchOptions enables watch mode, where the session keeps running after the
+// initial traversal, delivering the changes made to the tree under the root
+// as TraverseItems. The items are subject to the same subscription, filters
+// and depth limit as the traversal, and are passed to the same callback. On
+// Linux, changes are detected with inotify; elsewhere, or when requested,
+// the tree is polled.
+type WatchOptions struct {
+	// Context enables watch mode. The session continues to deliver events
+	// until the context is cancelled, or the callback returns fs.SkipAll
+	// (or any other error, which is returned from the session).
+	//
+	Context context.Context
+
+	// Poll forces the use of the polling watcher, even when a native watcher
+	// is available on the platform.
+	//
+	Poll bool
+
+	// FS is the file system the polling watcher scans. Setting FS implies
+	// Poll. When not set, the native file system is scanned. The QueryStatus
+	// and ReadDirectory hooks should be set to match (see
+	// VirtualQueryStatusHookFn and VirtualReadEntriesHookFn).
+	//
+	FS storage.ReadOnlyVirtualFS
+
+	// Interval is the interval at which the polling watcher scans the tree
+	// (defaults to DefaultWatchPollInterval).
+	//
+	Interval time.Duration
+}
+
+func (o *WatchOptions) enabled() bool {
+	return o.Context != nil
+}
+
+// watchEvent is a change detected by a watcher
+type watchEvent struct {
+	kind  WatchEventEnum
+	path  string
+	from  string // the previous path, for a rename
+	isDir bool
+}
+
+// watcher detects changes to the tree under a root
+type watcher interface {
+	// next blocks until changes are detected, returning them in the order in
+	// which they should be delivered. Once the context is done, next returns
+	// without events.
+	next(ctx context.Context) ([]watchEvent, error)
+	close() error
+}
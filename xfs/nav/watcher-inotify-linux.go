@@ -0,0 +1,319 @@
+//go:build linux
+
+package nav
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/snivilised/extendio/internal/lo"
+)
+
+const (
+	inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_ATTRIB |
+		syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+		syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+	// inotifyWakeInterval is the interval at which a blocked read is
+	// interrupted, to check whether the context is done.
+	inotifyWakeInterval = time.Millisecond * 100
+
+	inotifyBufferSize = 64 * (syscall.SizeofInotifyEvent + syscall.NAME_MAX + 1)
+)
+
+// inotifyWatcher detects changes using Linux's inotify. A watch is added for
+// every folder in the tree, including those created after the watcher
+// started.
+type inotifyWatcher struct {
+	fd      int
+	file    *os.File
+	root    string
+	paths   map[int32]string // watch descriptor => folder
+	buffer  []byte
+	stopped bool
+}
+
+func newNativeWatcher(root string) (watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	w := &inotifyWatcher{
+		// a non blocking descriptor is integrated with the runtime poller,
+		// which allows read deadlines to be set. The descriptor is retained,
+		// because File.Fd would put the file back into blocking mode.
+		//
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		root:   root,
+		paths:  make(map[int32]string),
+		buffer: make([]byte, inotifyBufferSize),
+	}
+
+	if _, err := w.watchTree(root); err != nil {
+		_ = w.close()
+
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// watchTree adds a watch for the folder and all the folders beneath it,
+// returning the paths of the items found beneath it.
+func (w *inotifyWatcher) watchTree(root string) ([]string, error) {
+	found := []string{}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// the item may have been removed in the meantime
+			//
+			return lo.Ternary(path == root, err, nil)
+		}
+
+		if path != root {
+			found = append(found, path)
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		wd, err := syscall.InotifyAddWatch(w.fd, path, inotifyMask)
+		if err != nil {
+			return lo.Ternary(path == root, os.NewSyscallError("inotify_add_watch", err), nil)
+		}
+
+		w.paths[int32(wd)] = path //nolint:gosec // watch descriptors are small
+
+		return nil
+	})
+
+	return found, err
+}
+
+func (w *inotifyWatcher) forget(folder string) {
+	for wd, path := range w.paths {
+		if path == folder || strings.HasPrefix(path, folder+string(filepath.Separator)) {
+			delete(w.paths, wd)
+		}
+	}
+}
+
+func (w *inotifyWatcher) relocate(from, to string) {
+	separator := string(filepath.Separator)
+
+	for wd, path := range w.paths {
+		if path == from {
+			w.paths[wd] = to
+		} else if strings.HasPrefix(path, from+separator) {
+			w.paths[wd] = to + path[len(from):]
+		}
+	}
+}
+
+func (w *inotifyWatcher) next(ctx context.Context) ([]watchEvent, error) {
+	for !w.stopped {
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+
+		_ = w.file.SetReadDeadline(time.Now().Add(inotifyWakeInterval))
+		n, err := w.file.Read(w.buffer)
+
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		if events := w.decode(w.buffer[:n]); len(events) > 0 {
+			return events, nil
+		}
+	}
+
+	// the root has gone, so there is nothing left to watch; wait for the
+	// client to end the session.
+	//
+	<-ctx.Done()
+
+	return nil, nil
+}
+
+type inotifyRaw struct {
+	mask   uint32
+	cookie uint32
+	path   string
+}
+
+func (w *inotifyWatcher) decode(buffer []byte) []watchEvent {
+	raws := []inotifyRaw{}
+	overflowed := false
+
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buffer); {
+		raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buffer[offset])) //nolint:gosec // kernel defined layout
+		start := offset + syscall.SizeofInotifyEvent
+		offset = start + int(raw.Len)
+
+		if raw.Mask&syscall.IN_Q_OVERFLOW != 0 {
+			// not associated with a watch (its descriptor is -1)
+			//
+			overflowed = true
+
+			continue
+		}
+
+		folder, found := w.paths[raw.Wd]
+		if !found {
+			continue
+		}
+
+		name := strings.TrimRight(string(buffer[start:offset]), "\x00")
+		path := lo.Ternary(name == "", folder, filepath.Join(folder, name))
+
+		if raw.Mask&syscall.IN_IGNORED != 0 {
+			delete(w.paths, raw.Wd)
+
+			continue
+		}
+
+		raws = append(raws, inotifyRaw{
+			mask:   raw.Mask,
+			cookie: raw.Cookie,
+			path:   path,
+		})
+	}
+
+	events := w.translate(raws)
+
+	if overflowed {
+		events = append(events, w.overflow()...)
+	}
+
+	return events
+}
+
+// overflow re-walks the tree after the event queue overflowed, so that the
+// folders created in the meantime are watched, then reports that events
+// were lost, since the changes themselves can't be recovered.
+func (w *inotifyWatcher) overflow() []watchEvent {
+	if w.stopped {
+		return nil
+	}
+
+	if _, err := w.watchTree(w.root); err != nil {
+		w.stopped = true
+
+		return nil
+	}
+
+	return []watchEvent{{kind: WatchEventOverflowEn, path: w.root, isDir: true}}
+}
+
+// translate converts the raw events into watch events. A move within the
+// tree is reported by inotify as a pair of events sharing a cookie, which
+// are combined into a rename; an unpaired move is either a delete (moved
+// out) or a create (moved in).
+func (w *inotifyWatcher) translate(raws []inotifyRaw) []watchEvent {
+	events := []watchEvent{}
+	paired := map[int]bool{}
+
+	for i, raw := range raws {
+		isDir := raw.mask&syscall.IN_ISDIR != 0
+
+		switch {
+		case raw.mask&(syscall.IN_DELETE_SELF|syscall.IN_MOVE_SELF) != 0:
+			if raw.path == w.root {
+				w.stopped = true
+			}
+
+		case raw.mask&syscall.IN_CREATE != 0:
+			events = append(events, watchEvent{kind: WatchEventCreateEn, path: raw.path, isDir: isDir})
+			events = append(events, w.adopt(raw.path, isDir)...)
+
+		case raw.mask&(syscall.IN_MODIFY|syscall.IN_ATTRIB) != 0:
+			if !isDir {
+				events = append(events, watchEvent{kind: WatchEventModifyEn, path: raw.path})
+			}
+
+		case raw.mask&syscall.IN_DELETE != 0:
+			events = append(events, watchEvent{kind: WatchEventDeleteEn, path: raw.path, isDir: isDir})
+
+		case raw.mask&syscall.IN_MOVED_FROM != 0:
+			to := -1
+
+			for j := i + 1; j < len(raws); j++ {
+				if raws[j].cookie == raw.cookie && raws[j].mask&syscall.IN_MOVED_TO != 0 {
+					to = j
+
+					break
+				}
+			}
+
+			if to < 0 {
+				if isDir {
+					w.forget(raw.path)
+				}
+
+				events = append(events, watchEvent{kind: WatchEventDeleteEn, path: raw.path, isDir: isDir})
+
+				continue
+			}
+
+			paired[to] = true
+
+			if isDir {
+				w.relocate(raw.path, raws[to].path)
+			}
+
+			events = append(events, watchEvent{
+				kind: WatchEventRenameEn, path: raws[to].path, from: raw.path, isDir: isDir,
+			})
+
+		case raw.mask&syscall.IN_MOVED_TO != 0:
+			if !paired[i] {
+				events = append(events, watchEvent{kind: WatchEventCreateEn, path: raw.path, isDir: isDir})
+				events = append(events, w.adopt(raw.path, isDir)...)
+			}
+		}
+	}
+
+	return dedupeWatchEvents(events)
+}
+
+// adopt watches a folder that has appeared in the tree. The items already
+// inside it are reported as created, since they may have been added before
+// the watch was in place.
+func (w *inotifyWatcher) adopt(path string, isDir bool) []watchEvent {
+	if !isDir {
+		return nil
+	}
+
+	found, _ := w.watchTree(path)
+	events := make([]watchEvent, 0, len(found))
+
+	for _, child := range found {
+		info, err := os.Lstat(child)
+		if err != nil {
+			continue
+		}
+
+		events = append(events, watchEvent{kind: WatchEventCreateEn, path: child, isDir: info.IsDir()})
+	}
+
+	return events
+}
+
+func (w *inotifyWatcher) close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package nav
+
+import (
+	"errors"
+)
+
+// newNativeWatcher is not available on this platform, so the polling
+// watcher is used instead.
+func newNativeWatcher(_ string) (watcher, error) {
+	return nil, errors.ErrUnsupported
+}
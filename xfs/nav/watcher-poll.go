@@ -0,0 +1,210 @@
+package nav
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/snivilised/extendio/xfs/storage"
+	"github.com/snivilised/extendio/xfs/utils"
+)
+
+type pollState struct {
+	size    int64
+	modTime time.Time
+	inode   uint64
+	isDir   bool
+}
+
+type pollSnapshot map[string]pollState
+
+// pollingWatcher detects changes by periodically scanning the tree and
+// comparing it against the previous scan. A rename is recognised when an
+// item disappears and another with the same inode appears; this requires
+// file info derived from a native file system, otherwise a rename is
+// reported as a delete followed by a create.
+type pollingWatcher struct {
+	vfs      storage.ReadOnlyVirtualFS
+	root     string
+	interval time.Duration
+	previous pollSnapshot
+}
+
+func newPollingWatcher(vfs storage.ReadOnlyVirtualFS, root string, interval time.Duration) *pollingWatcher {
+	w := &pollingWatcher{
+		vfs:      vfs,
+		root:     root,
+		interval: interval,
+	}
+	w.previous = w.scan()
+
+	return w
+}
+
+func (w *pollingWatcher) scan() pollSnapshot {
+	snapshot := make(pollSnapshot)
+
+	_ = w.vfs.WalkDir(w.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == w.root {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		snapshot[path] = pollState{
+			size:    info.Size(),
+			modTime: info.ModTime(),
+			inode:   utils.Inode(info),
+			isDir:   info.IsDir(),
+		}
+
+		return nil
+	})
+
+	return snapshot
+}
+
+func (w *pollingWatcher) next(ctx context.Context) ([]watchEvent, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(w.interval):
+		}
+
+		current := w.scan()
+		events := w.diff(current)
+		w.previous = current
+
+		if len(events) > 0 {
+			return events, nil
+		}
+	}
+}
+
+// diff returns the deletions (deepest first), then the renames, creations
+// and modifications (shallowest first). The descendants of a renamed
+// folder are not reported, since they have only moved along with it.
+func (w *pollingWatcher) diff(current pollSnapshot) []watchEvent {
+	var (
+		deleted, created, modified []string
+		renamed                    = map[string]string{} // new path => old path
+		separator                  = string(filepath.Separator)
+	)
+
+	for path, state := range current {
+		if before, found := w.previous[path]; !found {
+			created = append(created, path)
+		} else if !state.isDir && (state.size != before.size || !state.modTime.Equal(before.modTime)) {
+			modified = append(modified, path)
+		}
+	}
+
+	for path := range w.previous {
+		if _, found := current[path]; !found {
+			deleted = append(deleted, path)
+		}
+	}
+
+	sort.Strings(created)
+	sort.Strings(deleted)
+	sort.Strings(modified)
+
+	for _, from := range deleted {
+		before := w.previous[from]
+
+		if before.inode == 0 {
+			continue
+		}
+
+		for _, to := range created {
+			if _, taken := renamed[to]; taken {
+				continue
+			}
+
+			if state := current[to]; state.inode == before.inode && state.isDir == before.isDir {
+				renamed[to] = from
+
+				break
+			}
+		}
+	}
+
+	// the descendants of a renamed folder are also matched as renames, but
+	// only the folder itself should be reported
+	//
+	origins, folders := map[string]bool{}, map[string]bool{}
+
+	for to, from := range renamed {
+		origins[from] = true
+
+		if current[to].isDir {
+			folders[from] = true
+		}
+	}
+
+	moved := func(from string) bool {
+		for folder := range folders {
+			if strings.HasPrefix(from, folder+separator) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	events := []watchEvent{}
+
+	for i := len(deleted) - 1; i >= 0; i-- {
+		if path := deleted[i]; !origins[path] {
+			events = append(events, watchEvent{
+				kind:  WatchEventDeleteEn,
+				path:  path,
+				isDir: w.previous[path].isDir,
+			})
+		}
+	}
+
+	for _, path := range created {
+		from, found := renamed[path]
+
+		switch {
+		case found && moved(from):
+			continue
+
+		case found:
+			events = append(events, watchEvent{
+				kind:  WatchEventRenameEn,
+				path:  path,
+				from:  from,
+				isDir: current[path].isDir,
+			})
+
+		default:
+			events = append(events, watchEvent{
+				kind:  WatchEventCreateEn,
+				path:  path,
+				isDir: current[path].isDir,
+			})
+		}
+	}
+
+	for _, path := range modified {
+		events = append(events, watchEvent{
+			kind: WatchEventModifyEn,
+			path: path,
+		})
+	}
+
+	return events
+}
+
+func (w *pollingWatcher) close() error {
+	return nil
+}
@@ -0,0 +1,530 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault is a rule that declares a fault to be injected into calls made on a
+// FaultyVirtualFS. A call is subject to the fault when both the operation and
+// one of its paths match.
+type Fault struct {
+	// Op is a glob pattern matched against the name of the VirtualFS method
+	// being invoked, eg "ReadDir", or "Read*" for all the read methods. When
+	// empty, all operations match.
+	//
+	Op string
+
+	// Glob is a glob pattern, as defined by filepath.Match, that is matched
+	// against the full path and, failing that, against the base name, so
+	// that "*.flac" matches at any depth. When empty, all paths match.
+	//
+	Glob string
+
+	// Err is the error returned from the call, wrapped in an fs.PathError;
+	// eg fs.ErrNotExist or syscall.EACCES. When nil, the call is performed.
+	//
+	Err error
+
+	// Latency is the delay added to the call before it is performed
+	//
+	Latency time.Duration
+
+	// Limit, when positive, truncates the result of ReadFile to that number
+	// of bytes and the result of ReadDir to that number of entries. The same
+	// applies to the File returned by Open or OpenFile, whose Read calls
+	// reach the end of file after that number of bytes and whose ReadDir
+	// calls return no more than that number of entries in total.
+	//
+	Limit int
+
+	// Every, when greater than 1, restricts the fault to every Nth matching
+	// call, eg 3 causes the 3rd, 6th, 9th ... matching calls to fail.
+	//
+	Every int
+
+	// Times, when positive, is the maximum number of times the fault is
+	// injected, after which it is spent.
+	//
+	Times int
+}
+
+// validate checks that the patterns of the fault are well formed, so that
+// a malformed pattern is reported rather than never matching.
+func (f *Fault) validate() error {
+	for _, pattern := range []string{f.Op, f.Glob} {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid fault pattern '%v': %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (f *Fault) matches(op string, paths []string) bool {
+	if f.Op != "" {
+		if matched, _ := filepath.Match(f.Op, op); !matched {
+			return false
+		}
+	}
+
+	if f.Glob == "" {
+		return true
+	}
+
+	for _, path := range paths {
+		if matched, _ := filepath.Match(f.Glob, path); matched {
+			return true
+		}
+
+		if matched, _ := filepath.Match(f.Glob, filepath.Base(path)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Injection records a fault that was injected.
+type Injection struct {
+	// Op is the name of the VirtualFS method
+	//
+	Op string
+
+	// Path is the (first) path passed to the method
+	//
+	Path string
+
+	// Fault is the index of the fault that was injected
+	//
+	Fault int
+}
+
+func (i Injection) String() string {
+	return fmt.Sprintf("fault %v: %v '%v'", i.Fault, i.Op, i.Path)
+}
+
+// FaultyVirtualFS is a VirtualFS that injects faults into the calls it
+// forwards to the target, for resilience testing.
+type FaultyVirtualFS interface {
+	VirtualFS
+
+	// Inject adds the faults to the rules already in force. None of the
+	// faults are added if any of their patterns are malformed.
+	Inject(faults ...Fault) error
+
+	// Clear removes all faults, so that subsequent calls are forwarded
+	// unimpeded, eg to test resuming after a failed run.
+	Clear()
+
+	// Injections returns the faults injected so far, in order
+	Injections() []Injection
+}
+
+type faultRule struct {
+	Fault
+	calls int
+	fired int
+}
+
+type faultyFS struct {
+	backend VirtualBackend
+	target  VirtualFS
+
+	mutex      sync.Mutex
+	rules      []*faultRule
+	injections []Injection
+}
+
+// UseFaultyFS creates a VirtualFS that forwards all calls to the target,
+// injecting the faults declared. The faults are evaluated in order; the
+// latencies of all the faults that fire are accumulated, but the first
+// fault with an error ends the evaluation. Every and Times are tracked per
+// fault, counting only the calls that match. ExistsInFS methods report
+// false when an error is injected. An error is returned if the patterns of
+// any of the faults are malformed.
+func UseFaultyFS(target VirtualFS, faults ...Fault) (FaultyVirtualFS, error) {
+	ffs := &faultyFS{
+		backend: VirtualBackend(fmt.Sprintf("faulty(%v)", target.Backend())),
+		target:  target,
+	}
+
+	if err := ffs.Inject(faults...); err != nil {
+		return nil, err
+	}
+
+	return ffs, nil
+}
+
+func (ffs *faultyFS) Backend() VirtualBackend {
+	return ffs.backend
+}
+
+func (ffs *faultyFS) Inject(faults ...Fault) error {
+	for i := range faults {
+		if err := faults[i].validate(); err != nil {
+			return err
+		}
+	}
+
+	ffs.mutex.Lock()
+	defer ffs.mutex.Unlock()
+
+	for _, fault := range faults {
+		ffs.rules = append(ffs.rules, &faultRule{Fault: fault})
+	}
+
+	return nil
+}
+
+func (ffs *faultyFS) Clear() {
+	ffs.mutex.Lock()
+	defer ffs.mutex.Unlock()
+
+	ffs.rules = nil
+}
+
+func (ffs *faultyFS) Injections() []Injection {
+	ffs.mutex.Lock()
+	defer ffs.mutex.Unlock()
+
+	result := make([]Injection, len(ffs.injections))
+	copy(result, ffs.injections)
+
+	return result
+}
+
+// inject evaluates the faults for the call, returning the limit to apply to
+// the result and the error to return instead of performing the call.
+func (ffs *faultyFS) inject(op string, paths ...string) (limit int, err error) {
+	var latency time.Duration
+
+	ffs.mutex.Lock()
+
+	for i, rule := range ffs.rules {
+		if !rule.matches(op, paths) {
+			continue
+		}
+
+		rule.calls++
+
+		if rule.Every > 1 && rule.calls%rule.Every != 0 {
+			continue
+		}
+
+		if rule.Times > 0 && rule.fired >= rule.Times {
+			continue
+		}
+
+		rule.fired++
+		ffs.injections = append(ffs.injections, Injection{Op: op, Path: paths[0], Fault: i})
+		latency += rule.Latency
+
+		if limit == 0 && rule.Limit > 0 {
+			limit = rule.Limit
+		}
+
+		if rule.Err != nil {
+			err = &fs.PathError{Op: strings.ToLower(op), Path: paths[0], Err: rule.Err}
+
+			break
+		}
+	}
+
+	ffs.mutex.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	return limit, err
+}
+
+// interface ExistsInFS
+
+func (ffs *faultyFS) FileExists(path string) bool {
+	if _, err := ffs.inject("FileExists", path); err != nil {
+		return false
+	}
+
+	return ffs.target.FileExists(path)
+}
+
+func (ffs *faultyFS) DirectoryExists(path string) bool {
+	if _, err := ffs.inject("DirectoryExists", path); err != nil {
+		return false
+	}
+
+	return ffs.target.DirectoryExists(path)
+}
+
+// end: interface ExistsInFS
+
+// interface ReadOnlyVirtualFS
+
+func (ffs *faultyFS) Lstat(path string) (fs.FileInfo, error) {
+	if _, err := ffs.inject("Lstat", path); err != nil {
+		return nil, err
+	}
+
+	return ffs.target.Lstat(path)
+}
+
+func (ffs *faultyFS) Stat(path string) (fs.FileInfo, error) {
+	if _, err := ffs.inject("Stat", path); err != nil {
+		return nil, err
+	}
+
+	return ffs.target.Stat(path)
+}
+
+func (ffs *faultyFS) Glob(pattern string) ([]string, error) {
+	if _, err := ffs.inject("Glob", pattern); err != nil {
+		return nil, err
+	}
+
+	return ffs.target.Glob(pattern)
+}
+
+func (ffs *faultyFS) Open(name string) (File, error) {
+	limit, err := ffs.inject("Open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := ffs.target.Open(name)
+	if err != nil || limit == 0 {
+		return file, err
+	}
+
+	return &faultyFile{File: file, remaining: limit}, nil
+}
+
+func (ffs *faultyFS) ReadFile(name string) ([]byte, error) {
+	limit, err := ffs.inject("ReadFile", name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ffs.target.ReadFile(name)
+	if err == nil && limit > 0 && len(data) > limit {
+		data = data[:limit]
+	}
+
+	return data, err
+}
+
+func (ffs *faultyFS) ReadDir(name string) ([]os.DirEntry, error) {
+	limit, err := ffs.inject("ReadDir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ffs.target.ReadDir(name)
+	if err == nil && limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, err
+}
+
+func (ffs *faultyFS) ReadLink(name string) (string, error) {
+	if _, err := ffs.inject("ReadLink", name); err != nil {
+		return "", err
+	}
+
+	return ffs.target.ReadLink(name)
+}
+
+// WalkDir is implemented in terms of Lstat and ReadDir, so that the faults
+// declared for those operations are also injected during the walk.
+func (ffs *faultyFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	if _, err := ffs.inject("WalkDir", root); err != nil {
+		return err
+	}
+
+	return walkDirIn(ffs, root, fn)
+}
+
+// end: interface ReadOnlyVirtualFS
+
+// interface WriteToFS
+
+func (ffs *faultyFS) Chmod(name string, mode os.FileMode) error {
+	if _, err := ffs.inject("Chmod", name); err != nil {
+		return err
+	}
+
+	return ffs.target.Chmod(name, mode)
+}
+
+func (ffs *faultyFS) Chtimes(name string, atime, mtime time.Time) error {
+	if _, err := ffs.inject("Chtimes", name); err != nil {
+		return err
+	}
+
+	return ffs.target.Chtimes(name, atime, mtime)
+}
+
+func (ffs *faultyFS) Chown(name string, uid, gid int) error {
+	if _, err := ffs.inject("Chown", name); err != nil {
+		return err
+	}
+
+	return ffs.target.Chown(name, uid, gid)
+}
+
+func (ffs *faultyFS) Create(name string) (File, error) {
+	if _, err := ffs.inject("Create", name); err != nil {
+		return nil, err
+	}
+
+	return ffs.target.Create(name)
+}
+
+func (ffs *faultyFS) CreateTemp(dir, pattern string) (File, error) {
+	if _, err := ffs.inject("CreateTemp", dir); err != nil {
+		return nil, err
+	}
+
+	return ffs.target.CreateTemp(dir, pattern)
+}
+
+func (ffs *faultyFS) Link(oldname, newname string) error {
+	if _, err := ffs.inject("Link", oldname, newname); err != nil {
+		return err
+	}
+
+	return ffs.target.Link(oldname, newname)
+}
+
+func (ffs *faultyFS) Mkdir(name string, perm fs.FileMode) error {
+	if _, err := ffs.inject("Mkdir", name); err != nil {
+		return err
+	}
+
+	return ffs.target.Mkdir(name, perm)
+}
+
+func (ffs *faultyFS) MkdirAll(path string, perm os.FileMode) error {
+	if _, err := ffs.inject("MkdirAll", path); err != nil {
+		return err
+	}
+
+	return ffs.target.MkdirAll(path, perm)
+}
+
+func (ffs *faultyFS) MkdirTemp(dir, pattern string) (string, error) {
+	if _, err := ffs.inject("MkdirTemp", dir); err != nil {
+		return "", err
+	}
+
+	return ffs.target.MkdirTemp(dir, pattern)
+}
+
+func (ffs *faultyFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	limit, err := ffs.inject("OpenFile", name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := ffs.target.OpenFile(name, flag, perm)
+	if err != nil || limit == 0 {
+		return file, err
+	}
+
+	return &faultyFile{File: file, remaining: limit}, nil
+}
+
+func (ffs *faultyFS) Remove(name string) error {
+	if _, err := ffs.inject("Remove", name); err != nil {
+		return err
+	}
+
+	return ffs.target.Remove(name)
+}
+
+func (ffs *faultyFS) RemoveAll(path string) error {
+	if _, err := ffs.inject("RemoveAll", path); err != nil {
+		return err
+	}
+
+	return ffs.target.RemoveAll(path)
+}
+
+func (ffs *faultyFS) Rename(oldpath, newpath string) error {
+	if _, err := ffs.inject("Rename", oldpath, newpath); err != nil {
+		return err
+	}
+
+	return ffs.target.Rename(oldpath, newpath)
+}
+
+func (ffs *faultyFS) Symlink(oldname, newname string) error {
+	if _, err := ffs.inject("Symlink", newname, oldname); err != nil {
+		return err
+	}
+
+	return ffs.target.Symlink(oldname, newname)
+}
+
+func (ffs *faultyFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if _, err := ffs.inject("WriteFile", name); err != nil {
+		return err
+	}
+
+	return ffs.target.WriteFile(name, data, perm)
+}
+
+// end: interface WriteToFS
+
+// faultyFile applies the Limit of the fault injected when the file was
+// opened, to the reads subsequently made on it.
+type faultyFile struct {
+	File
+	remaining int
+}
+
+func (f *faultyFile) Read(p []byte) (int, error) {
+	if f.remaining <= 0 {
+		return 0, io.EOF
+	}
+
+	if len(p) > f.remaining {
+		p = p[:f.remaining]
+	}
+
+	n, err := f.File.Read(p)
+	f.remaining -= n
+
+	return n, err
+}
+
+func (f *faultyFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if f.remaining <= 0 {
+		if n > 0 {
+			return nil, io.EOF
+		}
+
+		return []fs.DirEntry{}, nil
+	}
+
+	if n > f.remaining {
+		n = f.remaining
+	}
+
+	entries, err := f.File.ReadDir(n)
+	if len(entries) > f.remaining {
+		entries = entries[:f.remaining]
+	}
+
+	f.remaining -= len(entries)
+
+	return entries, err
+}
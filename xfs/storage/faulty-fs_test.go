@@ -0,0 +1,207 @@
+package storage_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+var _ = Describe("faulty-fs", func() {
+	var (
+		root string
+		vfs  storage.FaultyVirtualFS
+	)
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(root, "music", "rock"), faydeaudeau)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "music", "a.flac"), []byte("alpha"), beezledub)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "music", "b.txt"), []byte("bravo"), beezledub)).To(Succeed())
+
+		var err error
+
+		vfs, err = storage.UseFaultyFS(storage.UseNativeFS())
+		Expect(err).To(Succeed())
+	})
+
+	It("🧪 should: report layering", func() {
+		Expect(vfs.Backend()).To(Equal(storage.VirtualBackend("faulty(native)")))
+	})
+
+	It("🧪 should: forward calls without faults", func() {
+		entries, err := vfs.ReadDir(filepath.Join(root, "music"))
+		Expect(err).To(Succeed())
+		Expect(entries).To(HaveLen(3))
+		Expect(vfs.Injections()).To(BeEmpty())
+	})
+
+	When("error injected on matching path", func() {
+		It("🧪 should: fail only matching calls", func() {
+			Expect(vfs.Inject(storage.Fault{Op: "ReadDir", Glob: "rock", Err: syscall.EACCES})).To(Succeed())
+
+			_, err := vfs.ReadDir(filepath.Join(root, "music", "rock"))
+			Expect(errors.Is(err, fs.ErrPermission)).To(BeTrue())
+			Expect(os.IsPermission(err)).To(BeTrue())
+
+			_, err = vfs.ReadDir(filepath.Join(root, "music"))
+			Expect(err).To(Succeed())
+
+			_, err = vfs.Lstat(filepath.Join(root, "music", "rock"))
+			Expect(err).To(Succeed())
+
+			Expect(vfs.Injections()).To(HaveLen(1))
+			Expect(vfs.Injections()[0].Op).To(Equal("ReadDir"))
+		})
+	})
+
+	When("operation pattern declared", func() {
+		It("🧪 should: fail all matching operations", func() {
+			Expect(vfs.Inject(storage.Fault{Op: "Read*", Glob: "*.flac", Err: fs.ErrNotExist})).To(Succeed())
+
+			_, err := vfs.ReadFile(filepath.Join(root, "music", "a.flac"))
+			Expect(err).To(MatchError(fs.ErrNotExist))
+
+			_, err = vfs.ReadLink(filepath.Join(root, "music", "a.flac"))
+			Expect(err).To(MatchError(fs.ErrNotExist))
+
+			Expect(vfs.FileExists(filepath.Join(root, "music", "a.flac"))).To(BeTrue())
+		})
+	})
+
+	When("every Nth call declared", func() {
+		It("🧪 should: fail every Nth matching call", func() {
+			Expect(vfs.Inject(storage.Fault{Op: "Lstat", Err: syscall.EIO, Every: 3})).To(Succeed())
+
+			failures := 0
+
+			for i := 0; i < 9; i++ {
+				if _, err := vfs.Lstat(root); err != nil {
+					Expect(errors.Is(err, syscall.EIO)).To(BeTrue())
+					failures++
+				}
+			}
+
+			Expect(failures).To(Equal(3))
+		})
+	})
+
+	When("times declared", func() {
+		It("🧪 should: stop once spent", func() {
+			Expect(vfs.Inject(storage.Fault{Op: "Stat", Err: syscall.EIO, Times: 2})).To(Succeed())
+
+			_, err := vfs.Stat(root)
+			Expect(err).To(HaveOccurred())
+			_, err = vfs.Stat(root)
+			Expect(err).To(HaveOccurred())
+			_, err = vfs.Stat(root)
+			Expect(err).To(Succeed())
+		})
+	})
+
+	When("limit declared", func() {
+		It("🧪 should: truncate reads", func() {
+			Expect(vfs.Inject(storage.Fault{Op: "Read*", Limit: 2})).To(Succeed())
+
+			data, err := vfs.ReadFile(filepath.Join(root, "music", "b.txt"))
+			Expect(err).To(Succeed())
+			Expect(string(data)).To(Equal("br"))
+
+			entries, err := vfs.ReadDir(filepath.Join(root, "music"))
+			Expect(err).To(Succeed())
+			Expect(entries).To(HaveLen(2))
+		})
+
+		It("🧪 should: truncate reads of opened file", func() {
+			Expect(vfs.Inject(storage.Fault{Op: "Open*", Limit: 2})).To(Succeed())
+
+			file, err := vfs.Open(filepath.Join(root, "music", "b.txt"))
+			Expect(err).To(Succeed())
+			defer file.Close()
+
+			data, err := io.ReadAll(file)
+			Expect(err).To(Succeed())
+			Expect(string(data)).To(Equal("br"))
+
+			directory, err := vfs.OpenFile(filepath.Join(root, "music"), os.O_RDONLY, 0)
+			Expect(err).To(Succeed())
+			defer directory.Close()
+
+			entries, err := directory.ReadDir(1)
+			Expect(err).To(Succeed())
+			Expect(entries).To(HaveLen(1))
+
+			entries, err = directory.ReadDir(-1)
+			Expect(err).To(Succeed())
+			Expect(entries).To(HaveLen(1))
+
+			_, err = directory.ReadDir(1)
+			Expect(err).To(MatchError(io.EOF))
+		})
+	})
+
+	When("malformed pattern declared", func() {
+		It("🧪 should: return error and not inject any of the faults", func() {
+			Expect(vfs.Inject(
+				storage.Fault{Op: "Lstat", Err: syscall.EIO},
+				storage.Fault{Op: "Read[", Err: syscall.EIO},
+			)).To(MatchError(filepath.ErrBadPattern))
+
+			_, err := vfs.Lstat(root)
+			Expect(err).To(Succeed())
+
+			_, err = storage.UseFaultyFS(storage.UseNativeFS(), storage.Fault{Glob: "[*.flac"})
+			Expect(err).To(MatchError(filepath.ErrBadPattern))
+		})
+	})
+
+	When("latency declared", func() {
+		It("🧪 should: delay call", func() {
+			latency := time.Millisecond * 20
+			Expect(vfs.Inject(storage.Fault{Op: "Lstat", Latency: latency})).To(Succeed())
+
+			start := time.Now()
+			_, err := vfs.Lstat(root)
+			Expect(err).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically(">=", latency))
+		})
+	})
+
+	When("cleared", func() {
+		It("🧪 should: forward subsequent calls", func() {
+			Expect(vfs.Inject(storage.Fault{Err: syscall.EIO})).To(Succeed())
+			Expect(vfs.DirectoryExists(root)).To(BeFalse())
+
+			vfs.Clear()
+			Expect(vfs.DirectoryExists(root)).To(BeTrue())
+		})
+	})
+
+	When("walking", func() {
+		It("🧪 should: report injected read dir fault to walk function", func() {
+			Expect(vfs.Inject(storage.Fault{Op: "ReadDir", Glob: "rock", Err: syscall.EACCES})).To(Succeed())
+
+			var reported error
+
+			err := vfs.WalkDir(root, func(_ string, _ fs.DirEntry, err error) error {
+				if err != nil {
+					reported = err
+
+					return filepath.SkipDir
+				}
+
+				return nil
+			})
+			Expect(err).To(Succeed())
+			Expect(errors.Is(reported, fs.ErrPermission)).To(BeTrue())
+		})
+	})
+})
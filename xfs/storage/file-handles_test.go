@@ -0,0 +1,151 @@
+package storage_test
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+type handlesTE struct {
+	message string
+	use     func() storage.VirtualFS
+}
+
+var _ = Describe("file handles", func() {
+	var root string
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+	})
+
+	// populate creates the tree natively, so that it is visible to the lower
+	// layer of the overlay; the mem backend is populated through the vfs.
+	populate := func(vfs storage.VirtualFS) {
+		target := vfs
+		if vfs.Backend() != "mem" {
+			target = storage.UseNativeFS()
+		}
+
+		setupFiles(target, filepath.Join(root, "sub"),
+			&setupFile{filePath: filepath.Join(root, "a.txt"), data: []byte("alpha")},
+			&setupFile{filePath: filepath.Join(root, "b.flac"), data: []byte("bravo")},
+			&setupFile{filePath: filepath.Join(root, "sub", "c.txt"), data: []byte("charlie")},
+		)
+	}
+
+	DescribeTable("widened interface",
+		func(entry *handlesTE) {
+			vfs := entry.use()
+			populate(vfs)
+
+			By("open: streaming read of file")
+			file, err := vfs.Open(filepath.Join(root, "a.txt"))
+			Expect(err).To(BeNil())
+			data, err := io.ReadAll(file)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("alpha"))
+			Expect(file.Close()).To(Succeed())
+
+			By("open: missing file")
+			_, err = vfs.Open(filepath.Join(root, "missing.txt"))
+			Expect(err).To(MatchError(fs.ErrNotExist))
+
+			By("open file: append to existing file")
+			file, err = vfs.OpenFile(filepath.Join(root, "a.txt"), os.O_WRONLY|os.O_APPEND, beezledub)
+			Expect(err).To(BeNil())
+			_, err = file.WriteString("-appended")
+			Expect(err).To(BeNil())
+			Expect(file.Close()).To(Succeed())
+			data, err = vfs.ReadFile(filepath.Join(root, "a.txt"))
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("alpha-appended"))
+
+			By("open file: exclusive create of existing file")
+			_, err = vfs.OpenFile(filepath.Join(root, "a.txt"), os.O_RDWR|os.O_CREATE|os.O_EXCL, beezledub)
+			Expect(err).To(MatchError(fs.ErrExist))
+
+			By("create: write through handle")
+			file, err = vfs.Create(filepath.Join(root, "sub", "created.txt"))
+			Expect(err).To(BeNil())
+			_, err = file.Write([]byte("created"))
+			Expect(err).To(BeNil())
+			Expect(file.Close()).To(Succeed())
+			data, err = vfs.ReadFile(filepath.Join(root, "sub", "created.txt"))
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("created"))
+
+			By("glob: match files")
+			matches, err := vfs.Glob(filepath.Join(root, "*.txt"))
+			Expect(err).To(BeNil())
+			Expect(matches).To(Equal([]string{filepath.Join(root, "a.txt")}))
+
+			By("glob: bad pattern")
+			_, err = vfs.Glob(filepath.Join(root, "["))
+			Expect(err).To(MatchError(filepath.ErrBadPattern))
+
+			By("walk dir: visit all in lexical order")
+			visited := []string{}
+			Expect(vfs.WalkDir(root, func(path string, _ fs.DirEntry, err error) error {
+				visited = append(visited, strings.TrimPrefix(path, root))
+
+				return err
+			})).To(Succeed())
+			Expect(visited).To(Equal([]string{
+				"",
+				string(filepath.Separator) + "a.txt",
+				string(filepath.Separator) + "b.flac",
+				string(filepath.Separator) + "sub",
+				filepath.Join(string(filepath.Separator)+"sub", "c.txt"),
+				filepath.Join(string(filepath.Separator)+"sub", "created.txt"),
+			}))
+
+			By("read link: resolve symlink")
+			Expect(vfs.Symlink(filepath.Join(root, "a.txt"), filepath.Join(root, "link"))).To(Succeed())
+			target, err := vfs.ReadLink(filepath.Join(root, "link"))
+			Expect(err).To(BeNil())
+			Expect(target).To(Equal(filepath.Join(root, "a.txt")))
+
+			By("mkdir temp: create unique directory")
+			dir, err := vfs.MkdirTemp(root, "temp-*-dir")
+			Expect(err).To(BeNil())
+			Expect(filepath.Base(dir)).To(HavePrefix("temp-"))
+			Expect(filepath.Base(dir)).To(HaveSuffix("-dir"))
+			Expect(vfs.DirectoryExists(dir)).To(BeTrue())
+
+			By("create temp: create unique file")
+			file, err = vfs.CreateTemp(dir, "temp")
+			Expect(err).To(BeNil())
+			Expect(filepath.Dir(file.Name())).To(Equal(dir))
+			Expect(file.Close()).To(Succeed())
+			Expect(vfs.FileExists(file.Name())).To(BeTrue())
+		},
+		func(entry *handlesTE) string {
+			return fmt.Sprintf("🧪 ===> backend: '%v'", entry.message)
+		},
+
+		Entry(nil, &handlesTE{
+			message: "native",
+			use:     storage.UseNativeFS,
+		}),
+
+		Entry(nil, &handlesTE{
+			message: "mem",
+			use:     storage.UseMemFS,
+		}),
+
+		Entry(nil, &handlesTE{
+			message: "overlay",
+			use: func() storage.VirtualFS {
+				return storage.UseOverlayFS(storage.UseNativeFS())
+			},
+		}),
+	)
+})
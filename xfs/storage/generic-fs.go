@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The functions here implement the higher level operations purely in terms
+// of the primitive ones, for the benefit of backends that layer on top of
+// other backends, which therefore can't delegate these operations.
+
+const (
+	maxTempAttempts = 10000
+)
+
+// globIn is the equivalent of filepath.Glob, on the file system provided.
+func globIn(vfs ReadOnlyVirtualFS, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	if !hasMeta(pattern) {
+		if _, err := vfs.Lstat(pattern); err != nil {
+			return nil, nil //nolint:nilerr // no match is not an error
+		}
+
+		return []string{pattern}, nil
+	}
+
+	dir, file := filepath.Split(pattern)
+	dir = cleanGlobPath(dir)
+
+	if !hasMeta(dir) {
+		return globDir(vfs, dir, file, nil), nil
+	}
+
+	if dir == pattern {
+		return nil, filepath.ErrBadPattern
+	}
+
+	dirs, err := globIn(vfs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+
+	for _, d := range dirs {
+		matches = globDir(vfs, d, file, matches)
+	}
+
+	return matches, nil
+}
+
+func globDir(vfs ReadOnlyVirtualFS, dir, pattern string, matches []string) []string {
+	if info, err := vfs.Stat(dir); err != nil || !info.IsDir() {
+		return matches
+	}
+
+	entries, err := vfs.ReadDir(dir)
+	if err != nil {
+		return matches
+	}
+
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			matches = append(matches, filepath.Join(dir, name))
+		}
+	}
+
+	return matches
+}
+
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[\`)
+}
+
+func cleanGlobPath(path string) string {
+	switch path {
+	case "":
+		return "."
+	case string(filepath.Separator):
+		return path
+	}
+
+	return path[0 : len(path)-1]
+}
+
+// walkDirIn is the equivalent of filepath.WalkDir, on the file system
+// provided.
+func walkDirIn(vfs ReadOnlyVirtualFS, root string, fn fs.WalkDirFunc) error {
+	info, err := vfs.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(vfs, root, fs.FileInfoToDirEntry(info), fn)
+	}
+
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+func walkDir(vfs ReadOnlyVirtualFS, path string, entry fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, entry, nil); err != nil || !entry.IsDir() {
+		if errors.Is(err, filepath.SkipDir) && entry.IsDir() {
+			err = nil
+		}
+
+		return err
+	}
+
+	entries, err := vfs.ReadDir(path)
+	if err != nil {
+		if err = fn(path, entry, err); err != nil {
+			if errors.Is(err, filepath.SkipDir) && entry.IsDir() {
+				err = nil
+			}
+
+			return err
+		}
+	}
+
+	for _, child := range entries {
+		if err := walkDir(vfs, filepath.Join(path, child.Name()), child, fn); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				break
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tempName generates a candidate name from the pattern, replacing the last
+// "*" with a random string, or appending it if there is no "*".
+func tempName(dir, pattern string) string {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	random := strconv.FormatUint(uint64(rand.Uint32()), 10) //nolint:gosec // not security sensitive
+
+	if i := strings.LastIndex(pattern, "*"); i >= 0 {
+		return filepath.Join(dir, pattern[:i]+random+pattern[i+1:])
+	}
+
+	return filepath.Join(dir, pattern+random)
+}
+
+// mkdirTempIn is the equivalent of os.MkdirTemp, on the file system provided.
+func mkdirTempIn(vfs VirtualFS, dir, pattern string) (string, error) {
+	for attempt := 0; attempt < maxTempAttempts; attempt++ {
+		name := tempName(dir, pattern)
+
+		err := vfs.Mkdir(name, 0o700)
+		if err == nil {
+			return name, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+	}
+
+	return "", &fs.PathError{Op: "mkdirtemp", Path: filepath.Join(dir, pattern), Err: fs.ErrExist}
+}
+
+// createTempIn is the equivalent of os.CreateTemp, on the file system
+// provided.
+func createTempIn(vfs VirtualFS, dir, pattern string) (File, error) {
+	for attempt := 0; attempt < maxTempAttempts; attempt++ {
+		name := tempName(dir, pattern)
+
+		file, err := vfs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			return file, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+	}
+
+	return nil, &fs.PathError{Op: "createtemp", Path: filepath.Join(dir, pattern), Err: fs.ErrExist}
+}
+
+// isWrite determines whether the open flag permits mutation
+func isWrite(flag int) bool {
+	return flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0
+}
@@ -1,12 +1,12 @@
 package storage
 
 import (
-	"fmt"
 	"io/fs"
 	"os"
+	"time"
 
+	"github.com/avfs/avfs"
 	"github.com/avfs/avfs/vfs/memfs"
-	"github.com/pkg/errors"
 )
 
 type memFS struct {
@@ -49,14 +49,32 @@ func (ms *memFS) DirectoryExists(path string) bool {
 
 // interface ReadOnlyVirtualFS
 
+// Lstat rejects the empty path, which memfs would otherwise resolve to the
+// current directory, unlike the native file system.
 func (ms *memFS) Lstat(path string) (fs.FileInfo, error) {
+	if path == "" {
+		return nil, notExist("lstat", path)
+	}
+
 	return ms.mfs.Lstat(path)
 }
 
 func (ms *memFS) Stat(path string) (fs.FileInfo, error) {
+	if path == "" {
+		return nil, notExist("stat", path)
+	}
+
 	return ms.mfs.Stat(path)
 }
 
+func (ms *memFS) Glob(pattern string) ([]string, error) {
+	return ms.mfs.Glob(pattern)
+}
+
+func (ms *memFS) Open(name string) (File, error) {
+	return asMemFile(ms.mfs.Open(name))
+}
+
 func (ms *memFS) ReadFile(name string) ([]byte, error) {
 	return ms.mfs.ReadFile(name)
 }
@@ -65,6 +83,14 @@ func (ms *memFS) ReadDir(name string) ([]os.DirEntry, error) {
 	return ms.mfs.ReadDir(name)
 }
 
+func (ms *memFS) ReadLink(name string) (string, error) {
+	return ms.mfs.Readlink(name)
+}
+
+func (ms *memFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return ms.mfs.WalkDir(root, fn)
+}
+
 // end: interface ReadOnlyVirtualFS
 
 // interface WriteToFS
@@ -73,20 +99,20 @@ func (ms *memFS) Chmod(name string, mode os.FileMode) error {
 	return ms.mfs.Chmod(name, mode)
 }
 
+func (ms *memFS) Chtimes(name string, atime, mtime time.Time) error {
+	return ms.mfs.Chtimes(name, atime, mtime)
+}
+
 func (ms *memFS) Chown(name string, uid, gid int) error {
 	return ms.mfs.Chown(name, uid, gid)
 }
 
-func (ms *memFS) Create(name string) (*os.File, error) {
-	f, err := ms.mfs.Create(name)
-
-	if file, ok := f.(*os.File); ok {
-		return file, err
-	}
+func (ms *memFS) Create(name string) (File, error) {
+	return asMemFile(ms.mfs.Create(name))
+}
 
-	return nil, errors.Wrap(err,
-		fmt.Sprintf("file '%v' creation in '%v' failed", name, ms.backend),
-	)
+func (ms *memFS) CreateTemp(dir, pattern string) (File, error) {
+	return asMemFile(ms.mfs.CreateTemp(dir, pattern))
 }
 
 func (ms *memFS) Link(oldname, newname string) error {
@@ -101,6 +127,14 @@ func (ms *memFS) MkdirAll(path string, perm os.FileMode) error {
 	return ms.mfs.MkdirAll(path, perm)
 }
 
+func (ms *memFS) MkdirTemp(dir, pattern string) (string, error) {
+	return ms.mfs.MkdirTemp(dir, pattern)
+}
+
+func (ms *memFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	return asMemFile(ms.mfs.OpenFile(name, flag, perm))
+}
+
 func (ms *memFS) Remove(name string) error {
 	return ms.mfs.Remove(name)
 }
@@ -113,8 +147,22 @@ func (ms *memFS) Rename(oldpath, newpath string) error {
 	return ms.mfs.Rename(oldpath, newpath)
 }
 
+func (ms *memFS) Symlink(oldname, newname string) error {
+	return ms.mfs.Symlink(oldname, newname)
+}
+
 func (ms *memFS) WriteFile(name string, data []byte, perm os.FileMode) error {
 	return ms.mfs.WriteFile(name, data, perm)
 }
 
 // end: interface WriteToFS
+
+// asMemFile converts the result of opening a mem file, so that a failed
+// open results in a nil File interface.
+func asMemFile(file avfs.File, err error) (File, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -3,6 +3,8 @@ package storage
 import (
 	"io/fs"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 type nativeFS struct {
@@ -51,6 +53,14 @@ func (ns *nativeFS) Stat(path string) (fs.FileInfo, error) {
 	return os.Stat(path)
 }
 
+func (ns *nativeFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (ns *nativeFS) Open(name string) (File, error) {
+	return asFile(os.Open(name))
+}
+
 func (ns *nativeFS) ReadFile(name string) ([]byte, error) {
 	return os.ReadFile(name)
 }
@@ -59,18 +69,34 @@ func (ns *nativeFS) ReadDir(name string) ([]os.DirEntry, error) {
 	return os.ReadDir(name)
 }
 
+func (ns *nativeFS) ReadLink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+func (ns *nativeFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(root, fn)
+}
+
 // end: interface ReadOnlyVirtualFS
 
 func (ns *nativeFS) Chmod(name string, mode os.FileMode) error {
 	return os.Chmod(name, mode)
 }
 
+func (ns *nativeFS) Chtimes(name string, atime, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
 func (ns *nativeFS) Chown(name string, uid, gid int) error {
 	return os.Chown(name, uid, gid)
 }
 
-func (ns *nativeFS) Create(name string) (*os.File, error) {
-	return os.Create(name)
+func (ns *nativeFS) Create(name string) (File, error) {
+	return asFile(os.Create(name))
+}
+
+func (ns *nativeFS) CreateTemp(dir, pattern string) (File, error) {
+	return asFile(os.CreateTemp(dir, pattern))
 }
 
 // interface WriteToFS
@@ -87,6 +113,14 @@ func (ns *nativeFS) MkdirAll(path string, perm os.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
 
+func (ns *nativeFS) MkdirTemp(dir, pattern string) (string, error) {
+	return os.MkdirTemp(dir, pattern)
+}
+
+func (ns *nativeFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	return asFile(os.OpenFile(name, flag, perm))
+}
+
 func (ns *nativeFS) Remove(name string) error {
 	return os.Remove(name)
 }
@@ -99,8 +133,22 @@ func (ns *nativeFS) Rename(oldpath, newpath string) error {
 	return os.Rename(oldpath, newpath)
 }
 
+func (ns *nativeFS) Symlink(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
 func (ns *nativeFS) WriteFile(name string, data []byte, perm os.FileMode) error {
 	return os.WriteFile(name, data, perm)
 }
 
 // end: interface WriteToFS
+
+// asFile converts the result of opening a native file, so that a failed
+// open results in a nil File interface, rather than a nil *os.File.
+func asFile(file *os.File, err error) (File, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -0,0 +1,720 @@
+package storage
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/snivilised/extendio/internal/lo"
+)
+
+// OverlayVirtualFS is a copy-on-write VirtualFS. Reads are served from the
+// upper layer if the path exists there, otherwise from the lower layer. All
+// mutations are applied to the upper layer, so the lower layer is never
+// modified. Deletions of items in the lower layer are recorded as whiteouts,
+// which hide them from subsequent reads.
+type OverlayVirtualFS interface {
+	VirtualFS
+
+	// Upper returns the in-memory layer that contains all the mutations
+	Upper() VirtualFS
+
+	// Whiteouts returns the sorted paths of the items in the lower layer that
+	// have been deleted
+	Whiteouts() []string
+}
+
+type overlayFS struct {
+	backend VirtualBackend
+	lower   ReadOnlyVirtualFS
+	upper   VirtualFS
+
+	mutex sync.RWMutex
+	// whiteouts contains the paths deleted from the lower layer; the whiteout
+	// also hides all the descendants of the path.
+	whiteouts map[string]bool
+	// opaque contains the paths of the directories that have been re-created
+	// over a whiteout; the lower layer content of an opaque directory is
+	// hidden.
+	opaque map[string]bool
+}
+
+// UseOverlayFS creates a copy-on-write VirtualFS which overlays an in-memory
+// upper layer on top of the read-only lower layer provided (eg the native
+// file system). The Backend reports both layers, eg "overlay(mem/native)".
+func UseOverlayFS(lower ReadOnlyVirtualFS) OverlayVirtualFS {
+	upper := UseMemFS()
+	name := VirtualBackend("read-only")
+
+	if backend, ok := lower.(interface{ Backend() VirtualBackend }); ok {
+		name = backend.Backend()
+	}
+
+	return &overlayFS{
+		backend:   VirtualBackend(fmt.Sprintf("overlay(%v/%v)", upper.Backend(), name)),
+		lower:     lower,
+		upper:     upper,
+		whiteouts: make(map[string]bool),
+		opaque:    make(map[string]bool),
+	}
+}
+
+func (ofs *overlayFS) Backend() VirtualBackend {
+	return ofs.backend
+}
+
+func (ofs *overlayFS) Upper() VirtualFS {
+	return ofs.upper
+}
+
+func (ofs *overlayFS) Whiteouts() []string {
+	ofs.mutex.RLock()
+	defer ofs.mutex.RUnlock()
+
+	result := lo.Keys(ofs.whiteouts)
+	slices.Sort(result)
+
+	return result
+}
+
+// lowerVisible determines whether the path in the lower layer has not been
+// hidden by a whiteout or an opaque directory.
+func (ofs *overlayFS) lowerVisible(path string) bool {
+	ofs.mutex.RLock()
+	defer ofs.mutex.RUnlock()
+
+	for current := path; ; {
+		if ofs.whiteouts[current] {
+			return false
+		}
+
+		if current != path && ofs.opaque[current] {
+			return false
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return true
+		}
+
+		current = parent
+	}
+}
+
+func (ofs *overlayFS) inUpper(path string) bool {
+	_, err := ofs.upper.Lstat(path)
+
+	return err == nil
+}
+
+func (ofs *overlayFS) inLower(path string) bool {
+	if !ofs.lowerVisible(path) {
+		return false
+	}
+
+	_, err := ofs.lower.Lstat(path)
+
+	return err == nil
+}
+
+// whiteout hides the path in the lower layer, if present there.
+func (ofs *overlayFS) whiteout(path string) {
+	exists := ofs.inLower(path)
+
+	ofs.mutex.Lock()
+	defer ofs.mutex.Unlock()
+
+	for key := range ofs.whiteouts {
+		if strings.HasPrefix(key, path+string(filepath.Separator)) {
+			delete(ofs.whiteouts, key)
+		}
+	}
+
+	for key := range ofs.opaque {
+		if key == path || strings.HasPrefix(key, path+string(filepath.Separator)) {
+			delete(ofs.opaque, key)
+		}
+	}
+
+	if exists {
+		ofs.whiteouts[path] = true
+	}
+}
+
+// revive clears the whiteout of a path that is being re-created. A directory
+// re-created over a whiteout becomes opaque, so that the previous content in
+// the lower layer remains hidden.
+func (ofs *overlayFS) revive(path string, isDir bool) {
+	ofs.mutex.Lock()
+	defer ofs.mutex.Unlock()
+
+	if ofs.whiteouts[path] {
+		delete(ofs.whiteouts, path)
+
+		if isDir {
+			ofs.opaque[path] = true
+		}
+	}
+}
+
+func notExist(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+}
+
+// ensureDir makes sure that the directory at the path, which must exist in
+// the overlay, also exists in the upper layer.
+func (ofs *overlayFS) ensureDir(op, path string) error {
+	if ofs.inUpper(path) {
+		return nil
+	}
+
+	info, err := ofs.Lstat(path)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+
+	if !info.IsDir() {
+		return &fs.PathError{Op: op, Path: path, Err: syscall.ENOTDIR}
+	}
+
+	if parent := filepath.Dir(path); parent != path {
+		if err := ofs.ensureDir(op, parent); err != nil {
+			return err
+		}
+	}
+
+	if err := ofs.upper.Mkdir(path, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	return ofs.upper.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// copyUp makes sure that the item at the path, which must exist in the
+// overlay, also exists in the upper layer. The content of a directory is
+// not copied and a symlink is copied as a link, rather than followed.
+func (ofs *overlayFS) copyUp(op, path string) error {
+	if ofs.inUpper(path) {
+		return nil
+	}
+
+	info, err := ofs.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return ofs.ensureDir(op, path)
+	}
+
+	if err := ofs.ensureDir(op, filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return ofs.copyLink(path, path)
+	}
+
+	data, err := ofs.lower.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := ofs.upper.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	return ofs.upper.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// copyTree copies the item at the source path in the overlay, along with
+// all of its descendants, to the destination in the upper layer. Symlinks
+// are copied as links, rather than followed.
+func (ofs *overlayFS) copyTree(source, destination string) error {
+	info, err := ofs.Lstat(source)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return ofs.copyLink(source, destination)
+	}
+
+	if !info.IsDir() {
+		data, err := ofs.ReadFile(source)
+		if err != nil {
+			return err
+		}
+
+		if err := ofs.upper.WriteFile(destination, data, info.Mode().Perm()); err != nil {
+			return err
+		}
+
+		return ofs.upper.Chtimes(destination, info.ModTime(), info.ModTime())
+	}
+
+	if err := ofs.upper.Mkdir(destination, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	entries, err := ofs.ReadDir(source)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := ofs.copyTree(
+			filepath.Join(source, entry.Name()), filepath.Join(destination, entry.Name()),
+		); err != nil {
+			return err
+		}
+	}
+
+	return ofs.upper.Chtimes(destination, info.ModTime(), info.ModTime())
+}
+
+// copyLink re-creates the symlink at the source path in the overlay at the
+// destination in the upper layer, with the same target; so that a dangling
+// link, or a link to a directory, is preserved as is. The modification time
+// is not applied, since Chtimes would follow the link.
+func (ofs *overlayFS) copyLink(source, destination string) error {
+	target, err := ofs.ReadLink(source)
+	if err != nil {
+		return err
+	}
+
+	return ofs.upper.Symlink(target, destination)
+}
+
+// interface ExistsInFS
+
+func (ofs *overlayFS) FileExists(path string) bool {
+	result := false
+	if info, err := ofs.Lstat(path); err == nil {
+		result = !info.IsDir()
+	}
+
+	return result
+}
+
+func (ofs *overlayFS) DirectoryExists(path string) bool {
+	result := false
+	if info, err := ofs.Lstat(path); err == nil {
+		result = info.IsDir()
+	}
+
+	return result
+}
+
+// end: interface ExistsInFS
+
+// interface ReadOnlyVirtualFS
+
+func (ofs *overlayFS) Lstat(path string) (fs.FileInfo, error) {
+	if path == "" {
+		return nil, notExist("lstat", path)
+	}
+
+	path = filepath.Clean(path)
+
+	if info, err := ofs.upper.Lstat(path); err == nil {
+		return info, nil
+	}
+
+	if !ofs.lowerVisible(path) {
+		return nil, notExist("lstat", path)
+	}
+
+	return ofs.lower.Lstat(path)
+}
+
+func (ofs *overlayFS) Stat(path string) (fs.FileInfo, error) {
+	if path == "" {
+		return nil, notExist("stat", path)
+	}
+
+	path = filepath.Clean(path)
+
+	if info, err := ofs.upper.Stat(path); err == nil {
+		return info, nil
+	}
+
+	if !ofs.lowerVisible(path) {
+		return nil, notExist("stat", path)
+	}
+
+	return ofs.lower.Stat(path)
+}
+
+func (ofs *overlayFS) ReadFile(name string) ([]byte, error) {
+	name = filepath.Clean(name)
+
+	if ofs.inUpper(name) {
+		return ofs.upper.ReadFile(name)
+	}
+
+	if !ofs.lowerVisible(name) {
+		return nil, notExist("open", name)
+	}
+
+	return ofs.lower.ReadFile(name)
+}
+
+// ReadDir returns the merged contents of the directory in both layers, sorted
+// by name. Entries in the upper layer take precedence.
+func (ofs *overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	name = filepath.Clean(name)
+
+	upper, upperErr := ofs.upper.ReadDir(name)
+	lower, lowerErr := []fs.DirEntry{}, error(nil)
+
+	if ofs.lowerVisible(name) && !ofs.isOpaque(name) {
+		lower, lowerErr = ofs.lower.ReadDir(name)
+	} else {
+		lowerErr = notExist("open", name)
+	}
+
+	if upperErr != nil && lowerErr != nil {
+		return nil, lo.Ternary(ofs.inUpper(name), upperErr, lowerErr)
+	}
+
+	merged := make(map[string]fs.DirEntry, len(upper)+len(lower))
+
+	for _, entry := range lower {
+		if ofs.lowerVisible(filepath.Join(name, entry.Name())) {
+			merged[entry.Name()] = entry
+		}
+	}
+
+	for _, entry := range upper {
+		merged[entry.Name()] = entry
+	}
+
+	result := lo.Values(merged)
+	slices.SortFunc(result, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return result, nil
+}
+
+func (ofs *overlayFS) Glob(pattern string) ([]string, error) {
+	return globIn(ofs, pattern)
+}
+
+// Open opens the file from the layer in which it is visible. Note that
+// reading the entries of a directory through the resulting handle only
+// reflects that layer; use ReadDir for the merged contents.
+func (ofs *overlayFS) Open(name string) (File, error) {
+	name = filepath.Clean(name)
+
+	if ofs.inUpper(name) {
+		return ofs.upper.Open(name)
+	}
+
+	if !ofs.lowerVisible(name) {
+		return nil, notExist("open", name)
+	}
+
+	return ofs.lower.Open(name)
+}
+
+func (ofs *overlayFS) ReadLink(name string) (string, error) {
+	name = filepath.Clean(name)
+
+	if ofs.inUpper(name) {
+		return ofs.upper.ReadLink(name)
+	}
+
+	if !ofs.lowerVisible(name) {
+		return "", notExist("readlink", name)
+	}
+
+	return ofs.lower.ReadLink(name)
+}
+
+func (ofs *overlayFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return walkDirIn(ofs, root, fn)
+}
+
+func (ofs *overlayFS) isOpaque(path string) bool {
+	ofs.mutex.RLock()
+	defer ofs.mutex.RUnlock()
+
+	return ofs.opaque[path]
+}
+
+// end: interface ReadOnlyVirtualFS
+
+// interface WriteToFS
+
+func (ofs *overlayFS) Chmod(name string, mode fs.FileMode) error {
+	name = filepath.Clean(name)
+
+	if err := ofs.copyUp("chmod", name); err != nil {
+		return err
+	}
+
+	return ofs.upper.Chmod(name, mode)
+}
+
+func (ofs *overlayFS) Chtimes(name string, atime, mtime time.Time) error {
+	name = filepath.Clean(name)
+
+	if err := ofs.copyUp("chtimes", name); err != nil {
+		return err
+	}
+
+	return ofs.upper.Chtimes(name, atime, mtime)
+}
+
+func (ofs *overlayFS) Chown(name string, uid, gid int) error {
+	name = filepath.Clean(name)
+
+	if err := ofs.copyUp("chown", name); err != nil {
+		return err
+	}
+
+	return ofs.upper.Chown(name, uid, gid)
+}
+
+func (ofs *overlayFS) Create(name string) (File, error) {
+	return ofs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666) //nolint:mnd // as os.Create
+}
+
+func (ofs *overlayFS) CreateTemp(dir, pattern string) (File, error) {
+	return createTempIn(ofs, dir, pattern)
+}
+
+func (ofs *overlayFS) Link(oldname, newname string) error {
+	oldname, newname = filepath.Clean(oldname), filepath.Clean(newname)
+
+	if err := ofs.copyUp("link", oldname); err != nil {
+		return err
+	}
+
+	if err := ofs.ensureDir("link", filepath.Dir(newname)); err != nil {
+		return err
+	}
+
+	if _, err := ofs.Lstat(newname); err == nil {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+
+	ofs.revive(newname, false)
+
+	return ofs.upper.Link(oldname, newname)
+}
+
+func (ofs *overlayFS) Mkdir(name string, perm fs.FileMode) error {
+	name = filepath.Clean(name)
+
+	if _, err := ofs.Lstat(name); err == nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+
+	if err := ofs.ensureDir("mkdir", filepath.Dir(name)); err != nil {
+		return err
+	}
+
+	if err := ofs.upper.Mkdir(name, perm); err != nil {
+		return err
+	}
+
+	ofs.revive(name, true)
+
+	return nil
+}
+
+func (ofs *overlayFS) MkdirAll(path string, perm fs.FileMode) error {
+	path = filepath.Clean(path)
+
+	if info, err := ofs.Lstat(path); err == nil {
+		if info.IsDir() {
+			return nil
+		}
+
+		return &fs.PathError{Op: "mkdir", Path: path, Err: syscall.ENOTDIR}
+	}
+
+	if parent := filepath.Dir(path); parent != path {
+		if err := ofs.MkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+
+	return ofs.Mkdir(path, perm)
+}
+
+func (ofs *overlayFS) MkdirTemp(dir, pattern string) (string, error) {
+	return mkdirTempIn(ofs, dir, pattern)
+}
+
+// OpenFile opens the file for read as Open does, unless the flag permits
+// mutation, in which case the file is first copied up to the upper layer.
+func (ofs *overlayFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	name = filepath.Clean(name)
+
+	if !isWrite(flag) {
+		return ofs.Open(name)
+	}
+
+	if info, err := ofs.Lstat(name); err == nil {
+		if flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+		}
+
+		if info.IsDir() {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+		}
+
+		if err := ofs.copyUp("open", name); err != nil {
+			return nil, err
+		}
+	} else {
+		if flag&os.O_CREATE == 0 {
+			return nil, notExist("open", name)
+		}
+
+		if err := ofs.ensureDir("open", filepath.Dir(name)); err != nil {
+			return nil, err
+		}
+
+		ofs.revive(name, false)
+	}
+
+	return ofs.upper.OpenFile(name, flag, perm)
+}
+
+func (ofs *overlayFS) Remove(name string) error {
+	name = filepath.Clean(name)
+
+	info, err := ofs.Lstat(name)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if info.IsDir() {
+		entries, err := ofs.ReadDir(name)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) > 0 {
+			return &fs.PathError{Op: "remove", Path: name, Err: syscall.ENOTEMPTY}
+		}
+	}
+
+	if ofs.inUpper(name) {
+		if err := ofs.upper.RemoveAll(name); err != nil {
+			return err
+		}
+	}
+
+	ofs.whiteout(name)
+
+	return nil
+}
+
+func (ofs *overlayFS) RemoveAll(path string) error {
+	path = filepath.Clean(path)
+
+	if _, err := ofs.Lstat(path); err != nil {
+		return nil
+	}
+
+	if ofs.inUpper(path) {
+		if err := ofs.upper.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
+	ofs.whiteout(path)
+
+	return nil
+}
+
+func (ofs *overlayFS) Rename(oldpath, newpath string) error {
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+
+	info, err := ofs.Lstat(oldpath)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+
+	if strings.HasPrefix(newpath, oldpath+string(filepath.Separator)) {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EINVAL}
+	}
+
+	if target, err := ofs.Lstat(newpath); err == nil && target.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrExist}
+	}
+
+	if err := ofs.ensureDir("rename", filepath.Dir(newpath)); err != nil {
+		return err
+	}
+
+	if ofs.inUpper(newpath) {
+		if err := ofs.upper.Remove(newpath); err != nil {
+			return err
+		}
+	}
+
+	ofs.revive(newpath, info.IsDir())
+
+	if info.IsDir() || !ofs.inUpper(oldpath) {
+		if err := ofs.copyTree(oldpath, newpath); err != nil {
+			return err
+		}
+
+		return ofs.RemoveAll(oldpath)
+	}
+
+	if err := ofs.upper.Rename(oldpath, newpath); err != nil {
+		return err
+	}
+
+	ofs.whiteout(oldpath)
+
+	return nil
+}
+
+func (ofs *overlayFS) Symlink(oldname, newname string) error {
+	newname = filepath.Clean(newname)
+
+	if _, err := ofs.Lstat(newname); err == nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+
+	if err := ofs.ensureDir("symlink", filepath.Dir(newname)); err != nil {
+		return err
+	}
+
+	ofs.revive(newname, false)
+
+	return ofs.upper.Symlink(oldname, newname)
+}
+
+func (ofs *overlayFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = filepath.Clean(name)
+
+	if err := ofs.ensureDir("open", filepath.Dir(name)); err != nil {
+		return err
+	}
+
+	if info, err := ofs.Lstat(name); err == nil && info.IsDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+	}
+
+	ofs.revive(name, false)
+
+	return ofs.upper.WriteFile(name, data, perm)
+}
+
+// end: interface WriteToFS
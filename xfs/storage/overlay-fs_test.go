@@ -0,0 +1,217 @@
+package storage_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/internal/lo"
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+func names(entries []fs.DirEntry) []string {
+	return lo.Map(entries, func(entry fs.DirEntry, _ int) string {
+		return entry.Name()
+	})
+}
+
+var _ = Describe("overlay-fs", func() {
+	var (
+		root    string
+		overlay storage.OverlayVirtualFS
+	)
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+
+		for name, content := range map[string]string{
+			"top.txt":       "top",
+			"dir/a.txt":     "alpha",
+			"dir/sub/b.txt": "bravo",
+		} {
+			path := filepath.Join(root, filepath.FromSlash(name))
+			Expect(os.MkdirAll(filepath.Dir(path), faydeaudeau)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(content), beezledub)).To(Succeed())
+		}
+
+		overlay = storage.UseOverlayFS(storage.UseNativeFS())
+	})
+
+	expectOnDisk := func(name, content string) {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(content))
+	}
+
+	It("🧪 should: report layering", func() {
+		Expect(overlay.Backend()).To(Equal(storage.VirtualBackend("overlay(mem/native)")))
+	})
+
+	It("🧪 should: read from lower layer", func() {
+		data, err := overlay.ReadFile(filepath.Join(root, "dir", "a.txt"))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("alpha"))
+		Expect(overlay.DirectoryExists(filepath.Join(root, "dir", "sub"))).To(BeTrue())
+
+		entries, err := overlay.ReadDir(root)
+		Expect(err).To(BeNil())
+		Expect(names(entries)).To(Equal([]string{"dir", "top.txt"}))
+	})
+
+	When("file is written", func() {
+		It("🧪 should: only write to upper layer", func() {
+			created := filepath.Join(root, "dir", "new.txt")
+			Expect(overlay.WriteFile(created, []byte("new"), beezledub)).To(Succeed())
+			Expect(overlay.WriteFile(filepath.Join(root, "top.txt"), []byte("changed"), beezledub)).To(Succeed())
+
+			data, err := overlay.ReadFile(filepath.Join(root, "top.txt"))
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("changed"))
+			expectOnDisk("top.txt", "top")
+
+			Expect(overlay.FileExists(created)).To(BeTrue())
+			Expect(overlay.Upper().FileExists(created)).To(BeTrue())
+			Expect(created).NotTo(BeAnExistingFile())
+
+			entries, err := overlay.ReadDir(filepath.Join(root, "dir"))
+			Expect(err).To(BeNil())
+			Expect(names(entries)).To(Equal([]string{"a.txt", "new.txt", "sub"}))
+		})
+	})
+
+	When("file is removed", func() {
+		It("🧪 should: hide file with whiteout", func() {
+			removed := filepath.Join(root, "dir", "a.txt")
+			Expect(overlay.Remove(removed)).To(Succeed())
+
+			Expect(overlay.FileExists(removed)).To(BeFalse())
+			_, err := overlay.ReadFile(removed)
+			Expect(err).To(MatchError(fs.ErrNotExist))
+			Expect(overlay.Whiteouts()).To(Equal([]string{removed}))
+			expectOnDisk("dir/a.txt", "alpha")
+
+			entries, err := overlay.ReadDir(filepath.Join(root, "dir"))
+			Expect(err).To(BeNil())
+			Expect(names(entries)).To(Equal([]string{"sub"}))
+		})
+	})
+
+	When("non empty directory is removed", func() {
+		It("🧪 should: return error", func() {
+			Expect(overlay.Remove(filepath.Join(root, "dir"))).NotTo(Succeed())
+		})
+	})
+
+	When("directory is re-created after being removed", func() {
+		It("🧪 should: not reveal previous contents", func() {
+			dir := filepath.Join(root, "dir")
+			Expect(overlay.RemoveAll(dir)).To(Succeed())
+			Expect(overlay.DirectoryExists(dir)).To(BeFalse())
+
+			Expect(overlay.MkdirAll(filepath.Join(dir, "sub"), faydeaudeau)).To(Succeed())
+			Expect(overlay.Whiteouts()).To(BeEmpty())
+
+			entries, err := overlay.ReadDir(dir)
+			Expect(err).To(BeNil())
+			Expect(names(entries)).To(Equal([]string{"sub"}))
+
+			entries, err = overlay.ReadDir(filepath.Join(dir, "sub"))
+			Expect(err).To(BeNil())
+			Expect(entries).To(BeEmpty())
+			expectOnDisk("dir/sub/b.txt", "bravo")
+		})
+	})
+
+	When("file is renamed", func() {
+		It("🧪 should: report file at new path only", func() {
+			from := filepath.Join(root, "top.txt")
+			to := filepath.Join(root, "dir", "moved.txt")
+			Expect(overlay.Rename(from, to)).To(Succeed())
+
+			Expect(overlay.FileExists(from)).To(BeFalse())
+			data, err := overlay.ReadFile(to)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("top"))
+			expectOnDisk("top.txt", "top")
+		})
+	})
+
+	When("directory is renamed", func() {
+		It("🧪 should: move whole sub tree", func() {
+			from := filepath.Join(root, "dir")
+			to := filepath.Join(root, "renamed")
+			Expect(overlay.Rename(from, to)).To(Succeed())
+
+			Expect(overlay.DirectoryExists(from)).To(BeFalse())
+			data, err := overlay.ReadFile(filepath.Join(to, "sub", "b.txt"))
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("bravo"))
+			Expect(overlay.Whiteouts()).To(Equal([]string{from}))
+			Expect(from).To(BeADirectory())
+		})
+	})
+
+	When("renamed directory contains links", func() {
+		It("🧪 should: move links rather than their targets", func() {
+			Expect(os.Symlink("a.txt", filepath.Join(root, "dir", "link"))).To(Succeed())
+			Expect(os.Symlink("missing.txt", filepath.Join(root, "dir", "dangling"))).To(Succeed())
+			Expect(os.Symlink("sub", filepath.Join(root, "dir", "folder"))).To(Succeed())
+
+			from := filepath.Join(root, "dir")
+			to := filepath.Join(root, "renamed")
+			Expect(overlay.Rename(from, to)).To(Succeed())
+
+			for name, expected := range map[string]string{
+				"link":     "a.txt",
+				"dangling": "missing.txt",
+				"folder":   "sub",
+			} {
+				info, err := overlay.Lstat(filepath.Join(to, name))
+				Expect(err).To(BeNil(), name)
+				Expect(info.Mode()&fs.ModeSymlink).NotTo(BeZero(), name)
+
+				target, err := overlay.ReadLink(filepath.Join(to, name))
+				Expect(err).To(BeNil(), name)
+				Expect(target).To(Equal(expected), name)
+			}
+		})
+	})
+
+	When("link is renamed", func() {
+		It("🧪 should: move link rather than its target", func() {
+			from := filepath.Join(root, "dangling")
+			to := filepath.Join(root, "dir", "moved")
+			Expect(os.Symlink("missing.txt", from)).To(Succeed())
+			Expect(overlay.Rename(from, to)).To(Succeed())
+
+			target, err := overlay.ReadLink(to)
+			Expect(err).To(BeNil())
+			Expect(target).To(Equal("missing.txt"))
+
+			_, err = overlay.Lstat(from)
+			Expect(err).To(MatchError(fs.ErrNotExist))
+		})
+	})
+
+	When("mode is changed", func() {
+		It("🧪 should: copy up file", func() {
+			path := filepath.Join(root, "dir", "sub", "b.txt")
+			Expect(overlay.Chmod(path, 0o600)).To(Succeed())
+
+			info, err := overlay.Lstat(path)
+			Expect(err).To(BeNil())
+			Expect(info.Mode().Perm()).To(Equal(fs.FileMode(0o600)))
+
+			data, err := overlay.ReadFile(path)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("bravo"))
+
+			native, err := os.Lstat(path)
+			Expect(err).To(BeNil())
+			Expect(native.Mode().Perm()).NotTo(Equal(fs.FileMode(0o600)))
+		})
+	})
+})
@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OperationEnum identifies a mutative file system operation.
+type OperationEnum string
+
+const (
+	OperationChmodEn     OperationEnum = "chmod"
+	OperationChtimesEn   OperationEnum = "chtimes"
+	OperationChownEn     OperationEnum = "chown"
+	OperationLinkEn      OperationEnum = "link"
+	OperationMkdirEn     OperationEnum = "mkdir"
+	OperationMkdirAllEn  OperationEnum = "mkdir-all"
+	OperationRemoveEn    OperationEnum = "remove"
+	OperationRemoveAllEn OperationEnum = "remove-all"
+	OperationRenameEn    OperationEnum = "rename"
+	OperationSymlinkEn   OperationEnum = "symlink"
+	OperationWriteFileEn OperationEnum = "write-file"
+)
+
+// Operation is a recorded call to one of the WriteToFS methods. Only the
+// fields relevant to the operation are populated.
+type Operation struct {
+	Op OperationEnum `json:"op"`
+
+	// Path is the path operated on; for rename, link and symlink, it is the
+	// new path.
+	//
+	Path string `json:"path"`
+
+	// Source is the old path of a rename, link or symlink
+	//
+	Source string `json:"source,omitempty"`
+
+	Mode  fs.FileMode `json:"mode,omitempty"`
+	UID   int         `json:"uid,omitempty"`
+	GID   int         `json:"gid,omitempty"`
+	Data  []byte      `json:"data,omitempty"`
+	Atime time.Time   `json:"atime"`
+	Mtime time.Time   `json:"mtime"`
+}
+
+func (o *Operation) String() string {
+	switch o.Op {
+	case OperationRenameEn, OperationLinkEn, OperationSymlinkEn:
+		return fmt.Sprintf("%v '%v' -> '%v'", o.Op, o.Source, o.Path)
+
+	case OperationChmodEn, OperationMkdirEn, OperationMkdirAllEn:
+		return fmt.Sprintf("%v '%v' (%v)", o.Op, o.Path, o.Mode)
+
+	case OperationChownEn:
+		return fmt.Sprintf("%v '%v' (%v:%v)", o.Op, o.Path, o.UID, o.GID)
+
+	case OperationChtimesEn:
+		return fmt.Sprintf("%v '%v' (%v)", o.Op, o.Path, o.Mtime.Format(time.RFC3339))
+
+	case OperationWriteFileEn:
+		return fmt.Sprintf("%v '%v' (%v bytes, %v)", o.Op, o.Path, len(o.Data), o.Mode)
+
+	case OperationRemoveEn, OperationRemoveAllEn:
+	}
+
+	return fmt.Sprintf("%v '%v'", o.Op, o.Path)
+}
+
+// Apply performs the operation on the virtual file system provided.
+func (o *Operation) Apply(vfs VirtualFS) error {
+	switch o.Op {
+	case OperationChmodEn:
+		return vfs.Chmod(o.Path, o.Mode)
+	case OperationChtimesEn:
+		return vfs.Chtimes(o.Path, o.Atime, o.Mtime)
+	case OperationChownEn:
+		return vfs.Chown(o.Path, o.UID, o.GID)
+	case OperationLinkEn:
+		return vfs.Link(o.Source, o.Path)
+	case OperationMkdirEn:
+		return vfs.Mkdir(o.Path, o.Mode)
+	case OperationMkdirAllEn:
+		return vfs.MkdirAll(o.Path, o.Mode)
+	case OperationRemoveEn:
+		return vfs.Remove(o.Path)
+	case OperationRemoveAllEn:
+		return vfs.RemoveAll(o.Path)
+	case OperationRenameEn:
+		return vfs.Rename(o.Source, o.Path)
+	case OperationSymlinkEn:
+		return vfs.Symlink(o.Source, o.Path)
+	case OperationWriteFileEn:
+		return vfs.WriteFile(o.Path, o.Data, o.Mode)
+	}
+
+	return fmt.Errorf("unknown operation '%v' for path '%v'", o.Op, o.Path)
+}
+
+// Plan is a sequence of recorded operations.
+type Plan []Operation
+
+func (p Plan) String() string {
+	var builder strings.Builder
+
+	for i := range p {
+		builder.WriteString(p[i].String())
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// Write serialises the plan as JSON to the writer.
+func (p Plan) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(p)
+}
+
+// Replay performs each of the operations in the plan, in order, on the
+// virtual file system provided, stopping at the first that fails.
+func (p Plan) Replay(vfs VirtualFS) error {
+	for i := range p {
+		if err := p[i].Apply(vfs); err != nil {
+			return fmt.Errorf("replay of operation %v (%v) failed: %w", i, p[i].String(), err)
+		}
+	}
+
+	return nil
+}
+
+// ReadPlan de-serialises a plan previously written with Plan.Write.
+func ReadPlan(r io.Reader) (Plan, error) {
+	var plan Plan
+
+	if err := json.NewDecoder(r).Decode(&plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// RecordingVirtualFS is a VirtualFS that records mutative operations rather
+// than performing them.
+type RecordingVirtualFS interface {
+	VirtualFS
+
+	// Plan returns a copy of the operations recorded so far
+	Plan() Plan
+}
+
+type recordingFS struct {
+	backend VirtualBackend
+	overlay OverlayVirtualFS
+
+	mutex sync.Mutex
+	plan  Plan
+}
+
+// UseRecordingFS creates a VirtualFS for dry runs. Reads pass through to the
+// target, but calls to the WriteToFS methods are recorded and not performed
+// on the target. Instead, their effects are simulated on a copy-on-write
+// overlay, so that subsequent reads reflect them; eg a renamed file is
+// reported at its new path. Only operations that succeed in the simulation
+// are recorded, so the resulting plan is the one that would have been
+// performed. Opening a file for writing (Create, CreateTemp and OpenFile
+// with a mutative flag) is not supported, since writes through the
+// resulting File could not be recorded.
+func UseRecordingFS(target ReadOnlyVirtualFS) RecordingVirtualFS {
+	overlay := UseOverlayFS(target)
+
+	return &recordingFS{
+		backend: VirtualBackend(fmt.Sprintf("recording(%v)", overlay.Backend())),
+		overlay: overlay,
+		plan:    Plan{},
+	}
+}
+
+func (rs *recordingFS) Backend() VirtualBackend {
+	return rs.backend
+}
+
+func (rs *recordingFS) Plan() Plan {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	result := make(Plan, len(rs.plan))
+	copy(result, rs.plan)
+
+	return result
+}
+
+// record simulates the operation and records it if successful; the lock is
+// held throughout, so that the plan is in the same order as the simulation.
+func (rs *recordingFS) record(operation *Operation) error {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if err := operation.Apply(rs.overlay); err != nil {
+		return err
+	}
+
+	rs.plan = append(rs.plan, *operation)
+
+	return nil
+}
+
+// interface ExistsInFS
+
+func (rs *recordingFS) FileExists(path string) bool {
+	return rs.overlay.FileExists(path)
+}
+
+func (rs *recordingFS) DirectoryExists(path string) bool {
+	return rs.overlay.DirectoryExists(path)
+}
+
+// end: interface ExistsInFS
+
+// interface ReadOnlyVirtualFS
+
+func (rs *recordingFS) Lstat(path string) (fs.FileInfo, error) {
+	return rs.overlay.Lstat(path)
+}
+
+func (rs *recordingFS) Stat(path string) (fs.FileInfo, error) {
+	return rs.overlay.Stat(path)
+}
+
+func (rs *recordingFS) ReadFile(name string) ([]byte, error) {
+	return rs.overlay.ReadFile(name)
+}
+
+func (rs *recordingFS) ReadDir(name string) ([]os.DirEntry, error) {
+	return rs.overlay.ReadDir(name)
+}
+
+func (rs *recordingFS) Glob(pattern string) ([]string, error) {
+	return rs.overlay.Glob(pattern)
+}
+
+func (rs *recordingFS) Open(name string) (File, error) {
+	return rs.overlay.Open(name)
+}
+
+func (rs *recordingFS) ReadLink(name string) (string, error) {
+	return rs.overlay.ReadLink(name)
+}
+
+func (rs *recordingFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return rs.overlay.WalkDir(root, fn)
+}
+
+// end: interface ReadOnlyVirtualFS
+
+// interface WriteToFS
+
+func (rs *recordingFS) Chmod(name string, mode os.FileMode) error {
+	return rs.record(&Operation{Op: OperationChmodEn, Path: name, Mode: mode})
+}
+
+func (rs *recordingFS) Chtimes(name string, atime, mtime time.Time) error {
+	return rs.record(&Operation{Op: OperationChtimesEn, Path: name, Atime: atime, Mtime: mtime})
+}
+
+func (rs *recordingFS) Chown(name string, uid, gid int) error {
+	return rs.record(&Operation{Op: OperationChownEn, Path: name, UID: uid, GID: gid})
+}
+
+func (rs *recordingFS) Create(name string) (File, error) {
+	return nil, &fs.PathError{Op: "create", Path: name, Err: errors.ErrUnsupported}
+}
+
+func (rs *recordingFS) CreateTemp(dir, pattern string) (File, error) {
+	return nil, &fs.PathError{Op: "createtemp", Path: dir, Err: errors.ErrUnsupported}
+}
+
+func (rs *recordingFS) Link(oldname, newname string) error {
+	return rs.record(&Operation{Op: OperationLinkEn, Path: newname, Source: oldname})
+}
+
+func (rs *recordingFS) Mkdir(name string, perm fs.FileMode) error {
+	return rs.record(&Operation{Op: OperationMkdirEn, Path: name, Mode: perm})
+}
+
+func (rs *recordingFS) MkdirAll(path string, perm os.FileMode) error {
+	return rs.record(&Operation{Op: OperationMkdirAllEn, Path: path, Mode: perm})
+}
+
+// MkdirTemp records the creation of the temporary directory as a mkdir, so
+// that replaying the plan re-creates it with the same name.
+func (rs *recordingFS) MkdirTemp(dir, pattern string) (string, error) {
+	return mkdirTempIn(rs, dir, pattern)
+}
+
+// OpenFile only supports opening for read.
+func (rs *recordingFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	if isWrite(flag) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.ErrUnsupported}
+	}
+
+	return rs.overlay.OpenFile(name, flag, perm)
+}
+
+func (rs *recordingFS) Remove(name string) error {
+	return rs.record(&Operation{Op: OperationRemoveEn, Path: name})
+}
+
+func (rs *recordingFS) RemoveAll(path string) error {
+	return rs.record(&Operation{Op: OperationRemoveAllEn, Path: path})
+}
+
+func (rs *recordingFS) Rename(oldpath, newpath string) error {
+	return rs.record(&Operation{Op: OperationRenameEn, Path: newpath, Source: oldpath})
+}
+
+func (rs *recordingFS) Symlink(oldname, newname string) error {
+	return rs.record(&Operation{Op: OperationSymlinkEn, Path: newname, Source: oldname})
+}
+
+func (rs *recordingFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return rs.record(&Operation{
+		Op:   OperationWriteFileEn,
+		Path: name,
+		Data: append([]byte(nil), data...),
+		Mode: perm,
+	})
+}
+
+// end: interface WriteToFS
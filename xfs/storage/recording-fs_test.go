@@ -0,0 +1,131 @@
+package storage_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+var _ = Describe("recording-fs", func() {
+	var (
+		root     string
+		original string
+		renamed  string
+		recorder storage.RecordingVirtualFS
+	)
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+		original = filepath.Join(root, "original.txt")
+		renamed = filepath.Join(root, "sub", "renamed.txt")
+		Expect(os.WriteFile(original, []byte("content"), beezledub)).To(Succeed())
+
+		recorder = storage.UseRecordingFS(storage.UseNativeFS())
+
+		Expect(recorder.MkdirAll(filepath.Join(root, "sub"), faydeaudeau)).To(Succeed())
+		Expect(recorder.Rename(original, renamed)).To(Succeed())
+		Expect(recorder.WriteFile(original, []byte("replaced"), beezledub)).To(Succeed())
+	})
+
+	It("🧪 should: report layering", func() {
+		Expect(recorder.Backend()).To(Equal(storage.VirtualBackend("recording(overlay(mem/native))")))
+	})
+
+	It("🧪 should: not perform operations", func() {
+		Expect(filepath.Join(root, "sub")).NotTo(BeADirectory())
+
+		data, err := os.ReadFile(original)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("content"))
+	})
+
+	It("🧪 should: simulate effects of operations", func() {
+		data, err := recorder.ReadFile(renamed)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("content"))
+
+		data, err = recorder.ReadFile(original)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("replaced"))
+	})
+
+	It("🧪 should: record operations in order", func() {
+		plan := recorder.Plan()
+
+		Expect(plan).To(HaveLen(3))
+		Expect(plan[0].Op).To(Equal(storage.OperationMkdirAllEn))
+		Expect(plan[1].Op).To(Equal(storage.OperationRenameEn))
+		Expect(plan[1].Source).To(Equal(original))
+		Expect(plan[1].Path).To(Equal(renamed))
+		Expect(plan[2].Op).To(Equal(storage.OperationWriteFileEn))
+		Expect(plan.String()).To(ContainSubstring("rename '" + original + "' -> '" + renamed + "'"))
+	})
+
+	It("🧪 should: not record failed operations", func() {
+		err := recorder.Remove(filepath.Join(root, "missing.txt"))
+
+		Expect(err).To(MatchError(os.ErrNotExist))
+		Expect(recorder.Plan()).To(HaveLen(3))
+	})
+
+	It("🧪 should: not support create", func() {
+		_, err := recorder.Create(filepath.Join(root, "created.txt"))
+
+		Expect(errors.Is(err, errors.ErrUnsupported)).To(BeTrue())
+	})
+
+	When("renamed directory contains links", func() {
+		It("🧪 should: record rename and replay it", func() {
+			from := filepath.Join(root, "links")
+			to := filepath.Join(root, "moved")
+			Expect(os.Mkdir(from, faydeaudeau)).To(Succeed())
+			Expect(os.Symlink("../original.txt", filepath.Join(from, "link"))).To(Succeed())
+			Expect(os.Symlink("missing.txt", filepath.Join(from, "dangling"))).To(Succeed())
+
+			Expect(recorder.Rename(from, to)).To(Succeed())
+
+			plan := recorder.Plan()
+			Expect(plan).To(HaveLen(4))
+			Expect(plan[3].Op).To(Equal(storage.OperationRenameEn))
+			Expect(from).To(BeADirectory())
+
+			target, err := recorder.ReadLink(filepath.Join(to, "dangling"))
+			Expect(err).To(BeNil())
+			Expect(target).To(Equal("missing.txt"))
+
+			Expect(plan.Replay(storage.UseNativeFS())).To(Succeed())
+
+			for name, expected := range map[string]string{
+				"link":     "../original.txt",
+				"dangling": "missing.txt",
+			} {
+				target, err := os.Readlink(filepath.Join(to, name))
+				Expect(err).To(BeNil(), name)
+				Expect(target).To(Equal(expected), name)
+			}
+		})
+	})
+
+	It("🧪 should: replay serialised plan", func() {
+		var buffer bytes.Buffer
+		Expect(recorder.Plan().Write(&buffer)).To(Succeed())
+
+		plan, err := storage.ReadPlan(&buffer)
+		Expect(err).To(BeNil())
+		Expect(plan.Replay(storage.UseNativeFS())).To(Succeed())
+
+		data, err := os.ReadFile(renamed)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("content"))
+
+		data, err = os.ReadFile(original)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("replaced"))
+	})
+})
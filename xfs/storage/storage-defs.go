@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"io"
 	"io/fs"
 	"os"
+	"time"
 )
 
 type filepathAPI interface {
@@ -16,6 +18,25 @@ type filepathAPI interface {
 	// will serve as a reminder in the intended use of this interface.
 }
 
+// File is a backend neutral handle to an open file, satisfied by *os.File.
+type File interface {
+	fs.ReadDirFile
+	io.ReaderAt
+	io.Seeker
+	io.StringWriter
+	io.Writer
+	io.WriterAt
+
+	// Name returns the name of the file as presented to Open
+	Name() string
+
+	// Sync commits the current contents of the file to stable storage
+	Sync() error
+
+	// Truncate changes the size of the file
+	Truncate(size int64) error
+}
+
 // ExistsInFS contains methods that check the existence of file system items.
 type ExistsInFS interface {
 	// FileExists does file exist at the path specified
@@ -32,11 +53,23 @@ type ReadFromFS interface {
 	// Lstat, see https://pkg.go.dev/os#Stat
 	Stat(path string) (fs.FileInfo, error)
 
+	// Glob, see https://pkg.go.dev/path/filepath#Glob
+	Glob(pattern string) ([]string, error)
+
+	// Open, see https://pkg.go.dev/os#Open
+	Open(name string) (File, error)
+
 	// ReadFile, see https://pkg.go.dev/os#ReadFile
 	ReadFile(name string) ([]byte, error)
 
 	// ReadDir, see https://pkg.go.dev/os#ReadDir
 	ReadDir(name string) ([]os.DirEntry, error)
+
+	// ReadLink, see https://pkg.go.dev/os#Readlink
+	ReadLink(name string) (string, error)
+
+	// WalkDir, see https://pkg.go.dev/path/filepath#WalkDir
+	WalkDir(root string, fn fs.WalkDirFunc) error
 }
 
 // WriteToFS contains methods that perform mutative operations on the file system.
@@ -45,11 +78,17 @@ type WriteToFS interface {
 	// Chmod, see https://pkg.go.dev/os#Chmod
 	Chmod(name string, mode os.FileMode) error
 
+	// Chtimes, see https://pkg.go.dev/os#Chtimes
+	Chtimes(name string, atime, mtime time.Time) error
+
 	// Chown, https://pkg.go.dev/os#Chown
 	Chown(name string, uid, gid int) error
 
 	// Create, see https://pkg.go.dev/os#Create
-	Create(name string) (*os.File, error)
+	Create(name string) (File, error)
+
+	// CreateTemp, see https://pkg.go.dev/os#CreateTemp
+	CreateTemp(dir, pattern string) (File, error)
 
 	// Link, see https://pkg.go.dev/os#Link
 	Link(oldname, newname string) error
@@ -60,6 +99,12 @@ type WriteToFS interface {
 	// MkdirAll, see https://pkg.go.dev/os#MkdirAll
 	MkdirAll(path string, perm os.FileMode) error
 
+	// MkdirTemp, see https://pkg.go.dev/os#MkdirTemp
+	MkdirTemp(dir, pattern string) (string, error)
+
+	// OpenFile, see https://pkg.go.dev/os#OpenFile
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+
 	// Remove, see https://pkg.go.dev/os#Remove
 	Remove(name string) error
 
@@ -69,6 +114,9 @@ type WriteToFS interface {
 	// Rename, see https://pkg.go.dev/os#Rename
 	Rename(oldpath, newpath string) error
 
+	// Symlink, see https://pkg.go.dev/os#Symlink
+	Symlink(oldname, newname string) error
+
 	// WriteFile, see https://pkg.go.dev/os#WriteFile
 	WriteFile(name string, data []byte, perm os.FileMode) error
 }
@@ -0,0 +1,593 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrTransactionFinished is returned when an operation is attempted on a
+// transaction that has already been committed or rolled back.
+var ErrTransactionFinished = errors.New("transaction already finished")
+
+// TransactionOptions defines where a transaction keeps its state.
+type TransactionOptions struct {
+	// Journal is the path of the file, to which the journal is written before
+	// each mutation is performed. It must not be inside the tree being
+	// modified.
+	//
+	Journal string
+
+	// Trash is the directory that removed and overwritten items are moved
+	// to, so that they can be restored on rollback. It must be on the same
+	// volume as the items being modified. Defaults to the Journal path with a
+	// ".trash" suffix.
+	//
+	Trash string
+}
+
+// journalEntry records a mutation along with the information required to
+// undo it.
+type journalEntry struct {
+	Op     OperationEnum `json:"op"`
+	Path   string        `json:"path"`
+	Source string        `json:"source,omitempty"`
+
+	// Trashed is the location in the trash of the item that was removed or
+	// replaced by the operation
+	//
+	Trashed string `json:"trashed,omitempty"`
+
+	// Applied denotes that the item has been moved to the trash, after which
+	// the mutation may have been performed. An entry with a Trashed item,
+	// that has not been applied, can only have been interrupted before the
+	// mutation, so undoing it must not touch the item at Path.
+	//
+	Applied bool `json:"applied,omitempty"`
+
+	// Created are the directories created by the operation
+	//
+	Created []string `json:"created,omitempty"`
+
+	// Mode and ModTime are the attributes of the item prior to the operation
+	//
+	Mode    fs.FileMode `json:"mode,omitempty"`
+	ModTime time.Time   `json:"mtime"`
+}
+
+type journal struct {
+	Trash   string          `json:"trash"`
+	Entries []*journalEntry `json:"entries"`
+}
+
+// Transaction is a VirtualFS that journals each mutation, so that a batch
+// of them can either be committed or rolled back. Reads pass through to the
+// underlying file system. The journal is written ahead of each mutation, so
+// that if the process crashes, the mutations can be rolled back by Recover.
+// Chown is not supported, because the previous owner can't be determined
+// portably.
+type Transaction struct {
+	vfs     VirtualFS
+	options TransactionOptions
+
+	mutex    sync.Mutex
+	journal  journal
+	finished bool
+}
+
+// Begin starts a transaction on the virtual file system provided. An error
+// is returned if a journal already exists, since it indicates that a
+// previous transaction did not finish; it should be handled with Recover.
+func Begin(vfs VirtualFS, options *TransactionOptions) (*Transaction, error) {
+	if options.Journal == "" {
+		return nil, errors.New("transaction journal path not specified")
+	}
+
+	if vfs.FileExists(options.Journal) {
+		return nil, &fs.PathError{Op: "begin", Path: options.Journal, Err: fs.ErrExist}
+	}
+
+	resolved := *options
+	if resolved.Trash == "" {
+		resolved.Trash = options.Journal + ".trash"
+	}
+
+	t := &Transaction{
+		vfs:     vfs,
+		options: resolved,
+		journal: journal{
+			Trash:   resolved.Trash,
+			Entries: []*journalEntry{},
+		},
+	}
+
+	if err := vfs.MkdirAll(resolved.Trash, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return t, t.persist()
+}
+
+// Recover rolls back the transaction described by the journal at the path
+// specified, if it exists. This should be invoked on start up, to clean up
+// after a process that crashed during a transaction.
+func Recover(vfs VirtualFS, journalPath string) error {
+	data, err := vfs.ReadFile(journalPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	t := &Transaction{
+		vfs: vfs,
+		options: TransactionOptions{
+			Journal: journalPath,
+		},
+	}
+
+	if err := json.Unmarshal(data, &t.journal); err != nil {
+		return fmt.Errorf("invalid transaction journal '%v': %w", journalPath, err)
+	}
+
+	t.options.Trash = t.journal.Trash
+
+	return t.Rollback()
+}
+
+// Commit makes the mutations permanent, by discarding the trash and the
+// journal.
+func (t *Transaction) Commit() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.finished {
+		return ErrTransactionFinished
+	}
+
+	t.finished = true
+
+	return t.discard()
+}
+
+// Rollback undoes the mutations in reverse order, restoring removed and
+// overwritten items from the trash. Rollback continues past failures, so
+// that as much as possible is restored; the errors encountered are joined.
+// Undo steps that find the item they would undo does not exist are ignored,
+// because the mutation may not have been performed before a crash.
+func (t *Transaction) Rollback() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.finished {
+		return ErrTransactionFinished
+	}
+
+	t.finished = true
+
+	var errs []error
+
+	for i := len(t.journal.Entries) - 1; i >= 0; i-- {
+		if err := t.undo(t.journal.Entries[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		// the journal is retained, so that the rollback can be re-attempted
+		//
+		return errors.Join(errs...)
+	}
+
+	return t.discard()
+}
+
+func (t *Transaction) discard() error {
+	if err := t.vfs.RemoveAll(t.options.Trash); err != nil {
+		return err
+	}
+
+	return t.vfs.Remove(t.options.Journal)
+}
+
+func (t *Transaction) persist() error {
+	data, err := json.MarshalIndent(&t.journal, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp := t.options.Journal + ".tmp"
+
+	if err := t.vfs.WriteFile(temp, data, 0o600); err != nil {
+		return err
+	}
+
+	return t.vfs.Rename(temp, t.options.Journal)
+}
+
+// perform journals the entry and then invokes the mutation. If the mutation
+// fails, the entry is removed from the journal.
+func (t *Transaction) perform(entry *journalEntry, mutation func() error) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.finished {
+		return ErrTransactionFinished
+	}
+
+	if err := t.prepare(entry); err != nil {
+		return err
+	}
+
+	t.journal.Entries = append(t.journal.Entries, entry)
+
+	if err := t.persist(); err != nil {
+		t.journal.Entries = t.journal.Entries[:len(t.journal.Entries)-1]
+
+		return err
+	}
+
+	if err := t.apply(entry, mutation); err != nil {
+		if entry.Trashed != "" {
+			_ = t.vfs.Rename(entry.Trashed, entry.Path)
+		}
+
+		t.journal.Entries = t.journal.Entries[:len(t.journal.Entries)-1]
+
+		return errors.Join(err, t.persist())
+	}
+
+	return nil
+}
+
+func (t *Transaction) exists(path string) bool {
+	_, err := t.vfs.Lstat(path)
+
+	return err == nil
+}
+
+// prepare captures the state required to undo the entry.
+func (t *Transaction) prepare(entry *journalEntry) error {
+	switch entry.Op {
+	case OperationChmodEn, OperationChtimesEn:
+		info, err := t.vfs.Lstat(entry.Path)
+		if err != nil {
+			return err
+		}
+
+		entry.Mode = info.Mode().Perm()
+		entry.ModTime = info.ModTime()
+
+	case OperationMkdirAllEn:
+		for current := entry.Path; !t.exists(current); {
+			entry.Created = append(entry.Created, current)
+
+			parent := filepath.Dir(current)
+			if parent == current {
+				break
+			}
+
+			current = parent
+		}
+
+	case OperationRemoveEn, OperationRemoveAllEn, OperationRenameEn, OperationWriteFileEn:
+		info, err := t.vfs.Lstat(entry.Path)
+		if err != nil {
+			break
+		}
+
+		if entry.Op == OperationWriteFileEn && info.IsDir() {
+			return &fs.PathError{Op: "open", Path: entry.Path, Err: syscall.EISDIR}
+		}
+
+		entry.Trashed = filepath.Join(t.options.Trash,
+			fmt.Sprintf("%v-%v", len(t.journal.Entries), filepath.Base(entry.Path)),
+		)
+
+	case OperationLinkEn, OperationMkdirEn, OperationSymlinkEn:
+		// these fail if the path already exists, in which case, undoing them
+		// would remove the pre-existing item.
+		//
+		if _, err := t.vfs.Lstat(entry.Path); err == nil {
+			return &fs.PathError{Op: string(entry.Op), Path: entry.Path, Err: fs.ErrExist}
+		}
+
+	case OperationChownEn:
+	}
+
+	return nil
+}
+
+// apply moves any existing item to the trash, before invoking the mutation.
+// The move is journaled before the mutation is invoked, so that a crash in
+// between them can be recovered from.
+func (t *Transaction) apply(entry *journalEntry, mutation func() error) error {
+	if entry.Trashed != "" {
+		if err := t.vfs.Rename(entry.Path, entry.Trashed); err != nil {
+			return err
+		}
+
+		entry.Applied = true
+
+		if err := t.persist(); err != nil {
+			return err
+		}
+	}
+
+	if mutation == nil {
+		return nil
+	}
+
+	return mutation()
+}
+
+func (t *Transaction) undo(entry *journalEntry) error {
+	if entry.Trashed != "" && !entry.Applied {
+		// the mutation was not performed, but the item may have been moved to
+		// the trash before the move was journaled
+		//
+		if !t.exists(entry.Trashed) {
+			return nil
+		}
+
+		return t.vfs.Rename(entry.Trashed, entry.Path)
+	}
+
+	var err error
+
+	switch entry.Op {
+	case OperationChmodEn:
+		err = t.vfs.Chmod(entry.Path, entry.Mode)
+
+	case OperationChtimesEn:
+		err = t.vfs.Chtimes(entry.Path, entry.ModTime, entry.ModTime)
+
+	case OperationLinkEn, OperationMkdirEn, OperationSymlinkEn:
+		err = t.vfs.Remove(entry.Path)
+
+	case OperationMkdirAllEn:
+		for _, created := range entry.Created {
+			if e := t.vfs.Remove(created); e != nil && !errors.Is(e, fs.ErrNotExist) {
+				err = e
+
+				break
+			}
+		}
+
+	case OperationRenameEn:
+		err = t.vfs.Rename(entry.Path, entry.Source)
+
+	case OperationWriteFileEn:
+		err = t.vfs.Remove(entry.Path)
+
+	case OperationRemoveEn, OperationRemoveAllEn, OperationChownEn:
+	}
+
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if entry.Trashed != "" {
+		if err := t.vfs.Rename(entry.Trashed, entry.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Transaction) Backend() VirtualBackend {
+	return VirtualBackend(fmt.Sprintf("transaction(%v)", t.vfs.Backend()))
+}
+
+// interface ExistsInFS
+
+func (t *Transaction) FileExists(path string) bool {
+	return t.vfs.FileExists(path)
+}
+
+func (t *Transaction) DirectoryExists(path string) bool {
+	return t.vfs.DirectoryExists(path)
+}
+
+// end: interface ExistsInFS
+
+// interface ReadOnlyVirtualFS
+
+func (t *Transaction) Lstat(path string) (fs.FileInfo, error) {
+	return t.vfs.Lstat(path)
+}
+
+func (t *Transaction) Stat(path string) (fs.FileInfo, error) {
+	return t.vfs.Stat(path)
+}
+
+func (t *Transaction) ReadFile(name string) ([]byte, error) {
+	return t.vfs.ReadFile(name)
+}
+
+func (t *Transaction) ReadDir(name string) ([]os.DirEntry, error) {
+	return t.vfs.ReadDir(name)
+}
+
+func (t *Transaction) Glob(pattern string) ([]string, error) {
+	return t.vfs.Glob(pattern)
+}
+
+func (t *Transaction) Open(name string) (File, error) {
+	return t.vfs.Open(name)
+}
+
+func (t *Transaction) ReadLink(name string) (string, error) {
+	return t.vfs.ReadLink(name)
+}
+
+func (t *Transaction) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return t.vfs.WalkDir(root, fn)
+}
+
+// end: interface ReadOnlyVirtualFS
+
+// interface WriteToFS
+
+func (t *Transaction) Chmod(name string, mode os.FileMode) error {
+	return t.perform(&journalEntry{Op: OperationChmodEn, Path: name}, func() error {
+		return t.vfs.Chmod(name, mode)
+	})
+}
+
+func (t *Transaction) Chtimes(name string, atime, mtime time.Time) error {
+	return t.perform(&journalEntry{Op: OperationChtimesEn, Path: name}, func() error {
+		return t.vfs.Chtimes(name, atime, mtime)
+	})
+}
+
+func (t *Transaction) Chown(name string, _, _ int) error {
+	return &fs.PathError{Op: "chown", Path: name, Err: errors.ErrUnsupported}
+}
+
+func (t *Transaction) Create(name string) (File, error) {
+	return t.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666) //nolint:mnd // as os.Create
+}
+
+// CreateTemp creates the temporary file through OpenFile, so that it is
+// removed on rollback.
+func (t *Transaction) CreateTemp(dir, pattern string) (File, error) {
+	return createTempIn(t, dir, pattern)
+}
+
+func (t *Transaction) Link(oldname, newname string) error {
+	return t.perform(&journalEntry{Op: OperationLinkEn, Path: newname, Source: oldname}, func() error {
+		return t.vfs.Link(oldname, newname)
+	})
+}
+
+func (t *Transaction) Mkdir(name string, perm fs.FileMode) error {
+	return t.perform(&journalEntry{Op: OperationMkdirEn, Path: name}, func() error {
+		return t.vfs.Mkdir(name, perm)
+	})
+}
+
+func (t *Transaction) MkdirAll(path string, perm os.FileMode) error {
+	return t.perform(&journalEntry{Op: OperationMkdirAllEn, Path: path}, func() error {
+		return t.vfs.MkdirAll(path, perm)
+	})
+}
+
+// MkdirTemp creates the temporary directory through Mkdir, so that it is
+// removed on rollback.
+func (t *Transaction) MkdirTemp(dir, pattern string) (string, error) {
+	return mkdirTempIn(t, dir, pattern)
+}
+
+// OpenFile opening for write is journaled in the same way as WriteFile; any
+// existing file is moved to the trash, and unless truncated, its content is
+// copied back before the file is opened; a truncated file is re-created with
+// its original permissions.
+func (t *Transaction) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	if !isWrite(flag) {
+		return t.vfs.OpenFile(name, flag, perm)
+	}
+
+	info, err := t.vfs.Lstat(name)
+
+	switch {
+	case err == nil && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+
+	case err != nil && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	var file File
+
+	entry := &journalEntry{Op: OperationWriteFileEn, Path: name}
+	err = t.perform(entry, func() error {
+		if entry.Trashed != "" {
+			if flag&os.O_TRUNC != 0 {
+				// the file no longer exists, so must be re-created
+				//
+				flag |= os.O_CREATE
+				perm = info.Mode().Perm()
+			} else {
+				data, err := t.vfs.ReadFile(entry.Trashed)
+				if err != nil {
+					return err
+				}
+
+				if err := t.vfs.WriteFile(name, data, info.Mode().Perm()); err != nil {
+					return err
+				}
+			}
+		}
+
+		var err error
+		file, err = t.vfs.OpenFile(name, flag, perm)
+
+		return err
+	})
+
+	return file, err
+}
+
+// Remove moves the item to the trash, so that it can be restored on rollback.
+func (t *Transaction) Remove(name string) error {
+	info, err := t.vfs.Lstat(name)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if info.IsDir() {
+		if entries, err := t.vfs.ReadDir(name); err != nil || len(entries) > 0 {
+			return &fs.PathError{Op: "remove", Path: name, Err: syscall.ENOTEMPTY}
+		}
+	}
+
+	return t.perform(&journalEntry{Op: OperationRemoveEn, Path: name}, nil)
+}
+
+// RemoveAll moves the item to the trash, so that it can be restored on
+// rollback.
+func (t *Transaction) RemoveAll(path string) error {
+	if _, err := t.vfs.Lstat(path); err != nil {
+		return nil
+	}
+
+	return t.perform(&journalEntry{Op: OperationRemoveAllEn, Path: path}, nil)
+}
+
+// Rename renames the item; any item it replaces is moved to the trash, so
+// that it can be restored on rollback.
+func (t *Transaction) Rename(oldpath, newpath string) error {
+	if _, err := t.vfs.Lstat(oldpath); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+
+	return t.perform(&journalEntry{Op: OperationRenameEn, Path: newpath, Source: oldpath}, func() error {
+		return t.vfs.Rename(oldpath, newpath)
+	})
+}
+
+func (t *Transaction) Symlink(oldname, newname string) error {
+	return t.perform(&journalEntry{Op: OperationSymlinkEn, Path: newname, Source: oldname}, func() error {
+		return t.vfs.Symlink(oldname, newname)
+	})
+}
+
+// WriteFile writes the file; any file it replaces is moved to the trash, so
+// that it can be restored on rollback.
+func (t *Transaction) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return t.perform(&journalEntry{Op: OperationWriteFileEn, Path: name}, func() error {
+		return t.vfs.WriteFile(name, data, perm)
+	})
+}
+
+// end: interface WriteToFS
@@ -0,0 +1,278 @@
+package storage_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	"github.com/snivilised/extendio/xfs/storage"
+)
+
+// errCrash is raised by crashingFS to simulate the process crashing
+var errCrash = errors.New("crash")
+
+// crashingFS panics when the crash function reports a call is fatal,
+// simulating the process crashing at that point.
+type crashingFS struct {
+	storage.VirtualFS
+	crash func(op string, paths ...string) bool
+}
+
+func (c *crashingFS) Rename(oldpath, newpath string) error {
+	if c.crash("Rename", oldpath, newpath) {
+		panic(errCrash)
+	}
+
+	return c.VirtualFS.Rename(oldpath, newpath)
+}
+
+func (c *crashingFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if c.crash("WriteFile", name) {
+		panic(errCrash)
+	}
+
+	return c.VirtualFS.WriteFile(name, data, perm)
+}
+
+type crashTE struct {
+	given  string
+	should string
+	crash  func(trash string) func(op string, paths ...string) bool
+}
+
+var _ = Describe("Transaction", func() {
+	var (
+		root, tree, journal string
+		vfs                 storage.VirtualFS
+	)
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(tree, name))
+		Expect(err).To(BeNil())
+
+		return string(data)
+	}
+
+	// mutate performs a batch of mutations, the last of which fails
+	mutate := func(tx storage.VirtualFS) {
+		Expect(tx.Rename(filepath.Join(tree, "a.txt"), filepath.Join(tree, "renamed.txt"))).To(Succeed())
+		Expect(tx.Remove(filepath.Join(tree, "b.txt"))).To(Succeed())
+		Expect(tx.RemoveAll(filepath.Join(tree, "dir"))).To(Succeed())
+		Expect(tx.WriteFile(filepath.Join(tree, "c.txt"), []byte("overwritten"), beezledub)).To(Succeed())
+		Expect(tx.MkdirAll(filepath.Join(tree, "new", "sub"), faydeaudeau)).To(Succeed())
+		Expect(tx.Chmod(filepath.Join(tree, "c.txt"), 0o600)).To(Succeed())
+		Expect(tx.Rename(filepath.Join(tree, "missing.txt"), filepath.Join(tree, "x.txt"))).NotTo(Succeed())
+	}
+
+	expectOriginal := func() {
+		Expect(read("a.txt")).To(Equal("alpha"))
+		Expect(read("b.txt")).To(Equal("bravo"))
+		Expect(read("c.txt")).To(Equal("charlie"))
+		Expect(read("dir/d.txt")).To(Equal("delta"))
+		Expect(filepath.Join(tree, "renamed.txt")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tree, "new")).NotTo(BeAnExistingFile())
+
+		info, err := os.Lstat(filepath.Join(tree, "c.txt"))
+		Expect(err).To(BeNil())
+		Expect(info.Mode().Perm()).NotTo(Equal(fs.FileMode(0o600)))
+
+		Expect(journal).NotTo(BeAnExistingFile())
+		Expect(journal + ".trash").NotTo(BeAnExistingFile())
+	}
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+		tree = filepath.Join(root, "tree")
+		journal = filepath.Join(root, "journal.json")
+		vfs = storage.UseNativeFS()
+
+		for name, content := range map[string]string{
+			"a.txt":     "alpha",
+			"b.txt":     "bravo",
+			"c.txt":     "charlie",
+			"dir/d.txt": "delta",
+		} {
+			path := filepath.Join(tree, filepath.FromSlash(name))
+			Expect(os.MkdirAll(filepath.Dir(path), faydeaudeau)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(content), beezledub)).To(Succeed())
+		}
+	})
+
+	When("committed", func() {
+		It("🧪 should: keep changes and discard journal", func() {
+			tx, err := storage.Begin(vfs, &storage.TransactionOptions{Journal: journal})
+			Expect(err).To(BeNil())
+			Expect(journal).To(BeAnExistingFile())
+
+			mutate(tx)
+			Expect(tx.Commit()).To(Succeed())
+
+			Expect(read("renamed.txt")).To(Equal("alpha"))
+			Expect(read("c.txt")).To(Equal("overwritten"))
+			Expect(filepath.Join(tree, "b.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tree, "dir")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tree, "new", "sub")).To(BeADirectory())
+			Expect(journal).NotTo(BeAnExistingFile())
+			Expect(journal + ".trash").NotTo(BeAnExistingFile())
+
+			Expect(tx.WriteFile(filepath.Join(tree, "late.txt"), nil, beezledub)).To(
+				MatchError(storage.ErrTransactionFinished),
+			)
+		})
+	})
+
+	When("rolled back", func() {
+		It("🧪 should: restore original tree", func() {
+			tx, err := storage.Begin(vfs, &storage.TransactionOptions{Journal: journal})
+			Expect(err).To(BeNil())
+
+			mutate(tx)
+			Expect(tx.Rollback()).To(Succeed())
+			expectOriginal()
+		})
+	})
+
+	When("process crashed during transaction", func() {
+		It("🧪 should: recover by rolling back", func() {
+			tx, err := storage.Begin(vfs, &storage.TransactionOptions{Journal: journal})
+			Expect(err).To(BeNil())
+
+			mutate(tx)
+			// abandon the transaction as if the process had crashed
+
+			_, err = storage.Begin(vfs, &storage.TransactionOptions{Journal: journal})
+			Expect(errors.Is(err, fs.ErrExist)).To(BeTrue())
+
+			Expect(storage.Recover(vfs, journal)).To(Succeed())
+			expectOriginal()
+		})
+	})
+
+	When("no journal exists", func() {
+		It("🧪 should: recover without error", func() {
+			Expect(storage.Recover(vfs, journal)).To(Succeed())
+		})
+	})
+
+	When("file opened for append is rolled back", func() {
+		It("🧪 should: restore original content", func() {
+			tx, err := storage.Begin(vfs, &storage.TransactionOptions{Journal: journal})
+			Expect(err).To(BeNil())
+
+			file, err := tx.OpenFile(filepath.Join(tree, "a.txt"), os.O_WRONLY|os.O_APPEND, beezledub)
+			Expect(err).To(BeNil())
+			_, err = file.WriteString("-appended")
+			Expect(err).To(BeNil())
+			Expect(file.Close()).To(Succeed())
+			Expect(read("a.txt")).To(Equal("alpha-appended"))
+
+			Expect(tx.Rollback()).To(Succeed())
+			Expect(read("a.txt")).To(Equal("alpha"))
+		})
+	})
+
+	When("file opened for truncate is rolled back", func() {
+		It("🧪 should: re-create file with original permissions, then restore it", func() {
+			path := filepath.Join(tree, "a.txt")
+			Expect(os.Chmod(path, 0o640)).To(Succeed())
+
+			tx, err := storage.Begin(vfs, &storage.TransactionOptions{Journal: journal})
+			Expect(err).To(BeNil())
+
+			file, err := tx.OpenFile(path, os.O_WRONLY|os.O_TRUNC, beezledub)
+			Expect(err).To(BeNil())
+			_, err = file.WriteString("truncated")
+			Expect(err).To(BeNil())
+			Expect(file.Close()).To(Succeed())
+			Expect(read("a.txt")).To(Equal("truncated"))
+
+			info, err := os.Lstat(path)
+			Expect(err).To(BeNil())
+			Expect(info.Mode().Perm()).To(Equal(fs.FileMode(0o640)))
+
+			Expect(tx.Rollback()).To(Succeed())
+			Expect(read("a.txt")).To(Equal("alpha"))
+		})
+	})
+
+	When("directory already exists", func() {
+		It("🧪 should: fail mkdir without removing it on rollback", func() {
+			tx, err := storage.Begin(vfs, &storage.TransactionOptions{Journal: journal})
+			Expect(err).To(BeNil())
+
+			Expect(tx.Mkdir(filepath.Join(tree, "dir"), faydeaudeau)).To(MatchError(fs.ErrExist))
+			Expect(tx.Rollback()).To(Succeed())
+			Expect(read("dir/d.txt")).To(Equal("delta"))
+		})
+	})
+
+	DescribeTable("process crashed while replacing an item",
+		func(entry *crashTE) {
+			trash := journal + ".trash"
+			tx, err := storage.Begin(&crashingFS{
+				VirtualFS: vfs,
+				crash:     entry.crash(trash),
+			}, &storage.TransactionOptions{Journal: journal})
+			Expect(err).To(BeNil())
+
+			Expect(func() {
+				_ = tx.WriteFile(filepath.Join(tree, "c.txt"), []byte("overwritten"), beezledub)
+			}).To(PanicWith(errCrash))
+
+			Expect(storage.Recover(vfs, journal)).To(Succeed())
+			Expect(read("c.txt")).To(Equal("charlie"))
+			Expect(journal).NotTo(BeAnExistingFile())
+			Expect(trash).NotTo(BeAnExistingFile())
+		},
+		func(entry *crashTE) string {
+			return fmt.Sprintf("🧪 ===> given: '%v', should: '%v'", entry.given, entry.should)
+		},
+
+		Entry(nil, &crashTE{
+			given:  "crash after journaling, before moving original to trash",
+			should: "leave original in place",
+			crash: func(trash string) func(string, ...string) bool {
+				return func(op string, paths ...string) bool {
+					return op == "Rename" && filepath.Dir(paths[1]) == trash
+				}
+			},
+		}),
+
+		Entry(nil, &crashTE{
+			given:  "crash after moving original to trash, before journaling the move",
+			should: "restore original from trash",
+			crash: func(trash string) func(string, ...string) bool {
+				trashed := false
+
+				return func(op string, paths ...string) bool {
+					if op == "Rename" && filepath.Dir(paths[1]) == trash {
+						trashed = true
+					}
+
+					return op == "WriteFile" && trashed
+				}
+			},
+		}),
+
+		Entry(nil, &crashTE{
+			given:  "crash after journaling the move, before writing",
+			should: "restore original from trash",
+			crash: func(trash string) func(string, ...string) bool {
+				trashed := false
+
+				return func(op string, paths ...string) bool {
+					if op == "Rename" && filepath.Dir(paths[1]) == trash {
+						trashed = true
+					}
+
+					return op == "WriteFile" && trashed && filepath.Dir(paths[0]) != filepath.Dir(trash)
+				}
+			},
+		}),
+	)
+})
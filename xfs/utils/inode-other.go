@@ -0,0 +1,13 @@
+//go:build !unix
+
+package utils
+
+import (
+	"io/fs"
+)
+
+// Inode is not available on this platform, so files are identified by
+// their path, size and modification time only.
+func Inode(_ fs.FileInfo) uint64 {
+	return 0
+}
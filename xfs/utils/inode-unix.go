@@ -0,0 +1,18 @@
+//go:build unix
+
+package utils
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// Inode returns the inode number of the file, or 0 if the file info
+// was not derived from a native file system.
+func Inode(info fs.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino) //nolint:unconvert // Ino is not uint64 on all platforms
+	}
+
+	return 0
+}
@@ -0,0 +1,71 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:revive // ginkgo ok
+	. "github.com/onsi/gomega"    //nolint:revive // gomega ok
+
+	. "github.com/snivilised/extendio/i18n" //nolint:revive // i18n ok
+	"github.com/snivilised/extendio/xfs/jail"
+	"github.com/snivilised/extendio/xfs/matchers"
+	"github.com/snivilised/extendio/xfs/storage"
+	"github.com/snivilised/extendio/xfs/utils"
+)
+
+var _ = Describe("ResolvePathIn", func() {
+	var (
+		root string
+		vfs  jail.VirtualFS
+	)
+
+	BeforeEach(func() {
+		if err := Use(func(o *UseOptions) {
+			o.Tag = DefaultLanguage.Get()
+		}); err != nil {
+			Fail(err.Error())
+		}
+
+		root = filepath.Join(GinkgoT().TempDir(), "jail")
+		Expect(os.MkdirAll(root, perm)).To(Succeed())
+
+		var err error
+		vfs, err = jail.New(storage.UseNativeFS(), root, nil)
+		Expect(err).To(Succeed())
+	})
+
+	It("🧪 should: resolve home to root of jail", func() {
+		actual, err := utils.ResolvePathIn("~/logs", vfs)
+		Expect(err).To(Succeed())
+		Expect(actual).To(Equal(filepath.Join(root, "logs")))
+	})
+
+	It("🧪 should: resolve relative path against root of jail", func() {
+		actual, err := utils.ResolvePathIn(filepath.Join(".", "logs", "..", "data"), vfs)
+		Expect(err).To(Succeed())
+		Expect(actual).To(Equal(filepath.Join(root, "data")))
+	})
+
+	It("🧪 should: reject path that escapes jail", func() {
+		_, err := utils.ResolvePathIn("~/../escaped", vfs)
+		Expect(QueryPathEscapesJailError(err)).To(BeTrue())
+	})
+
+	When("ensuring path in jail", func() {
+		It("🧪 should: create parent directory inside jail", func() {
+			actual, err := utils.EnsurePathAt(filepath.Join(root, "logs", "test.log"), "default.log", perm, vfs)
+			Expect(err).To(Succeed())
+			Expect(actual).To(Equal(filepath.Join(root, "logs", "test.log")))
+			Expect(matchers.AsDirectory(filepath.Join(root, "logs"))).To(matchers.ExistInFS(vfs))
+		})
+
+		It("🧪 should: reject path that escapes jail", func() {
+			_, err := utils.EnsurePathAt(filepath.Join(root, "..", "logs")+string(filepath.Separator),
+				"default.log", perm, vfs,
+			)
+			Expect(QueryPathEscapesJailError(err)).To(BeTrue())
+			Expect(filepath.Join(root, "..", "logs")).NotTo(BeADirectory())
+		})
+	})
+})
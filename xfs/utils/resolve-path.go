@@ -81,3 +81,34 @@ func ResolvePath(path string, mocks ...ResolveMocks) string {
 
 	return result
 }
+
+// Jail represents a file system that confines paths to a root directory (see
+// the jail package).
+type Jail interface {
+	// Root returns the directory to which paths are confined
+	Root() string
+
+	// Abs returns the absolute representation of the path, interpreting a
+	// relative path relative to the root, or an error if the path escapes
+	// the jail.
+	Abs(path string) (string, error)
+}
+
+// ResolvePathIn performs the same resolution as ResolvePath, except inside
+// the jail provided; ie the ~ character refers to the root of the jail and
+// ./ or ../ relative paths are relative to the root. An error is returned if
+// the resolved path escapes the jail.
+func ResolvePathIn(path string, jail Jail) (string, error) {
+	if path == "" {
+		return jail.Abs(path)
+	}
+
+	resolved := ResolvePath(path, ResolveMocks{
+		HomeFunc: func() (string, error) {
+			return jail.Root(), nil
+		},
+		AbsFunc: jail.Abs,
+	})
+
+	return jail.Abs(resolved)
+}